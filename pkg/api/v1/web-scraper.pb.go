@@ -25,6 +25,37 @@ var _ = math.Inf
 // proto package needs to be updated.
 const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
 
+type Job_Status int32
+
+const (
+	Job_PENDING  Job_Status = 0
+	Job_RUNNING  Job_Status = 1
+	Job_DONE     Job_Status = 2
+	Job_CANCELED Job_Status = 3
+)
+
+var Job_Status_name = map[int32]string{
+	0: "PENDING",
+	1: "RUNNING",
+	2: "DONE",
+	3: "CANCELED",
+}
+
+var Job_Status_value = map[string]int32{
+	"PENDING":  0,
+	"RUNNING":  1,
+	"DONE":     2,
+	"CANCELED": 3,
+}
+
+func (x Job_Status) String() string {
+	return proto.EnumName(Job_Status_name, int32(x))
+}
+
+func (Job_Status) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_c181f2f37fbaedca, []int{28, 0}
+}
+
 //Project Object
 type Product struct {
 	Asin                 string               `protobuf:"bytes,1,opt,name=asin,proto3" json:"asin,omitempty"`
@@ -33,6 +64,11 @@ type Product struct {
 	Ranks                []*ProductRank       `protobuf:"bytes,4,rep,name=ranks,proto3" json:"ranks,omitempty"`
 	Dimensions           []string             `protobuf:"bytes,5,rep,name=dimensions,proto3" json:"dimensions,omitempty"`
 	CreatedAt            *timestamp.Timestamp `protobuf:"bytes,6,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	Marketplace          string               `protobuf:"bytes,7,opt,name=marketplace,proto3" json:"marketplace,omitempty"`
+	Price                string               `protobuf:"bytes,8,opt,name=price,proto3" json:"price,omitempty"`
+	Availability         string               `protobuf:"bytes,9,opt,name=availability,proto3" json:"availability,omitempty"`
+	Brand                string               `protobuf:"bytes,10,opt,name=brand,proto3" json:"brand,omitempty"`
+	Features             []string             `protobuf:"bytes,11,rep,name=features,proto3" json:"features,omitempty"`
 	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
 	XXX_unrecognized     []byte               `json:"-"`
 	XXX_sizecache        int32                `json:"-"`
@@ -105,6 +141,41 @@ func (m *Product) GetCreatedAt() *timestamp.Timestamp {
 	return nil
 }
 
+func (m *Product) GetMarketplace() string {
+	if m != nil {
+		return m.Marketplace
+	}
+	return ""
+}
+
+func (m *Product) GetPrice() string {
+	if m != nil {
+		return m.Price
+	}
+	return ""
+}
+
+func (m *Product) GetAvailability() string {
+	if m != nil {
+		return m.Availability
+	}
+	return ""
+}
+
+func (m *Product) GetBrand() string {
+	if m != nil {
+		return m.Brand
+	}
+	return ""
+}
+
+func (m *Product) GetFeatures() []string {
+	if m != nil {
+		return m.Features
+	}
+	return nil
+}
+
 //ProjectCategoryObject
 type ProductCategory struct {
 	Name                 string   `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
@@ -203,7 +274,13 @@ func (m *ProductRank) GetLevel() int64 {
 
 //Expected Request For GetProduct
 type GetProductRequest struct {
-	Asin                 string   `protobuf:"bytes,1,opt,name=asin,proto3" json:"asin,omitempty"`
+	Asin string `protobuf:"bytes,1,opt,name=asin,proto3" json:"asin,omitempty"`
+	//
+	// Cache control, the REST gateway also maps the Cache-Control request header:
+	// max-age=N to max_age, no-cache to force_refresh and only-if-cached to cache_only
+	MaxAge               int64    `protobuf:"varint,2,opt,name=max_age,json=maxAge,proto3" json:"max_age,omitempty"`
+	ForceRefresh         bool     `protobuf:"varint,3,opt,name=force_refresh,json=forceRefresh,proto3" json:"force_refresh,omitempty"`
+	CacheOnly            bool     `protobuf:"varint,4,opt,name=cache_only,json=cacheOnly,proto3" json:"cache_only,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -241,9 +318,31 @@ func (m *GetProductRequest) GetAsin() string {
 	return ""
 }
 
+func (m *GetProductRequest) GetMaxAge() int64 {
+	if m != nil {
+		return m.MaxAge
+	}
+	return 0
+}
+
+func (m *GetProductRequest) GetForceRefresh() bool {
+	if m != nil {
+		return m.ForceRefresh
+	}
+	return false
+}
+
+func (m *GetProductRequest) GetCacheOnly() bool {
+	if m != nil {
+		return m.CacheOnly
+	}
+	return false
+}
+
 //Expected Response From GetProduct
 type GetProductResponse struct {
 	Product              *Product `protobuf:"bytes,1,opt,name=product,proto3" json:"product,omitempty"`
+	Stale                bool     `protobuf:"varint,2,opt,name=stale,proto3" json:"stale,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -281,123 +380,2810 @@ func (m *GetProductResponse) GetProduct() *Product {
 	return nil
 }
 
-func init() {
-	proto.RegisterType((*Product)(nil), "v1.Product")
-	proto.RegisterType((*ProductCategory)(nil), "v1.ProductCategory")
-	proto.RegisterType((*ProductRank)(nil), "v1.ProductRank")
-	proto.RegisterType((*GetProductRequest)(nil), "v1.GetProductRequest")
-	proto.RegisterType((*GetProductResponse)(nil), "v1.GetProductResponse")
+func (m *GetProductResponse) GetStale() bool {
+	if m != nil {
+		return m.Stale
+	}
+	return false
 }
 
-func init() { proto.RegisterFile("web-scraper.proto", fileDescriptor_c181f2f37fbaedca) }
+//Expected Request For GetProductByCode
+type GetProductByCodeRequest struct {
+	Code                 string   `protobuf:"bytes,1,opt,name=code,proto3" json:"code,omitempty"`
+	CodeType             string   `protobuf:"bytes,2,opt,name=code_type,json=codeType,proto3" json:"code_type,omitempty"`
+	Marketplace          string   `protobuf:"bytes,3,opt,name=marketplace,proto3" json:"marketplace,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
 
-var fileDescriptor_c181f2f37fbaedca = []byte{
-	// 555 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x74, 0x93, 0xcd, 0x6e, 0xd3, 0x40,
-	0x10, 0xc7, 0x71, 0xd2, 0xa6, 0x64, 0x22, 0x51, 0xba, 0x7c, 0x28, 0x0a, 0xa8, 0xac, 0x22, 0x95,
-	0x46, 0x15, 0xf1, 0x36, 0x6e, 0x2f, 0xb4, 0x97, 0x16, 0x0e, 0x88, 0x0b, 0x42, 0x06, 0x54, 0x89,
-	0x4b, 0xb5, 0xb6, 0xa7, 0xce, 0xd2, 0x78, 0xd7, 0xdd, 0x5d, 0x3b, 0x7c, 0x88, 0x0b, 0x8f, 0x00,
-	0x37, 0xde, 0x85, 0x03, 0xcf, 0xc0, 0x2b, 0x70, 0xe0, 0x31, 0x90, 0x3f, 0x02, 0x56, 0x03, 0x17,
-	0x7b, 0xfd, 0x9f, 0xdf, 0xce, 0xfc, 0x67, 0x34, 0x86, 0x8d, 0x39, 0x06, 0x63, 0x13, 0x6a, 0x9e,
-	0xa2, 0x76, 0x53, 0xad, 0xac, 0x22, 0xad, 0x7c, 0x32, 0xb8, 0x17, 0x2b, 0x15, 0xcf, 0x90, 0x95,
-	0x4a, 0x90, 0x9d, 0x31, 0x2b, 0x12, 0x34, 0x96, 0x27, 0x69, 0x05, 0x0d, 0xee, 0xd6, 0x00, 0x4f,
-	0x05, 0xe3, 0x52, 0x2a, 0xcb, 0xad, 0x50, 0xd2, 0xd4, 0xd1, 0x07, 0xe5, 0x2b, 0x1c, 0xc7, 0x28,
-	0xc7, 0x66, 0xce, 0xe3, 0x18, 0x35, 0x53, 0x69, 0x49, 0x2c, 0xd3, 0xc3, 0x5f, 0x0e, 0xac, 0x3d,
-	0xd7, 0x2a, 0xca, 0x42, 0x4b, 0x08, 0xac, 0x70, 0x23, 0x64, 0xdf, 0xa1, 0xce, 0xa8, 0xeb, 0x97,
-	0xe7, 0x42, 0x93, 0x3c, 0xc1, 0x7e, 0xab, 0xd2, 0x8a, 0x33, 0xd9, 0x03, 0x08, 0xb9, 0xc5, 0x58,
-	0x69, 0x81, 0xa6, 0xdf, 0xa6, 0xed, 0x51, 0xcf, 0xbb, 0xe1, 0xe6, 0x13, 0xb7, 0x4e, 0xf4, 0xb8,
-	0x0a, 0xbe, 0xf3, 0x1b, 0x18, 0xd9, 0x82, 0x55, 0xcd, 0xe5, 0xb9, 0xe9, 0xaf, 0x94, 0xfc, 0x7a,
-	0x83, 0xf7, 0xb9, 0x3c, 0xf7, 0xab, 0x28, 0xd9, 0x04, 0x88, 0x44, 0x82, 0xd2, 0x14, 0x1e, 0xfb,
-	0xab, 0xb4, 0x3d, 0xea, 0xfa, 0x0d, 0x85, 0x3c, 0x04, 0x08, 0x35, 0x72, 0x8b, 0xd1, 0x29, 0xb7,
-	0xfd, 0x0e, 0x75, 0x46, 0x3d, 0x6f, 0xe0, 0x56, 0x03, 0x71, 0x17, 0x13, 0x73, 0x5f, 0x2e, 0x26,
-	0xe6, 0x77, 0x6b, 0xfa, 0xd8, 0x0e, 0x0f, 0x61, 0xfd, 0x92, 0xc1, 0x3f, 0xdd, 0x39, 0x8d, 0xee,
-	0x6e, 0xc2, 0xea, 0x0c, 0x73, 0x9c, 0x95, 0x2d, 0xb7, 0xfd, 0xea, 0x63, 0x78, 0x04, 0xbd, 0x86,
-	0x5b, 0x72, 0x07, 0xba, 0x85, 0xdf, 0x53, 0x21, 0xcf, 0x54, 0x7d, 0xfb, 0x6a, 0x21, 0x3c, 0x95,
-	0x67, 0xea, 0x3f, 0x19, 0xb6, 0x61, 0xe3, 0x09, 0xda, 0x45, 0x12, 0xbc, 0xc8, 0xd0, 0xfc, 0x73,
-	0xe4, 0xc3, 0x43, 0x20, 0x4d, 0xd0, 0xa4, 0x4a, 0x1a, 0x24, 0x5b, 0xb0, 0x96, 0x56, 0x52, 0x09,
-	0xf7, 0xbc, 0x5e, 0x73, 0x82, 0x8b, 0x98, 0x77, 0x01, 0x70, 0x82, 0xc1, 0x8b, 0x6a, 0xa9, 0x48,
-	0x08, 0xf0, 0x37, 0x15, 0xb9, 0x55, 0xdc, 0x58, 0xf2, 0x30, 0xb8, 0x7d, 0x59, 0xae, 0x2a, 0x0e,
-	0xef, 0x7f, 0xfa, 0xf1, 0xf3, 0x4b, 0x8b, 0x92, 0x4d, 0x96, 0x4f, 0x18, 0x4f, 0xf8, 0x7b, 0x25,
-	0x59, 0x5d, 0x86, 0x15, 0x46, 0xd9, 0x87, 0xe2, 0xf9, 0xf1, 0xd1, 0x77, 0xe7, 0xf3, 0xf1, 0x37,
-	0x87, 0xbc, 0x82, 0xde, 0x09, 0x06, 0xb4, 0x2e, 0x3d, 0x3c, 0x86, 0x8e, 0x9f, 0x09, 0xfa, 0x4c,
-	0x90, 0xed, 0xa9, 0xb5, 0xa9, 0x39, 0x60, 0x2c, 0x16, 0x76, 0x9a, 0x05, 0x6e, 0xa8, 0x12, 0xa6,
-	0xa5, 0x88, 0x30, 0x67, 0xb1, 0x1a, 0xcf, 0x31, 0xa8, 0x7f, 0x81, 0xc1, 0x35, 0x9d, 0x89, 0xa3,
-	0x08, 0x73, 0x2d, 0x45, 0x01, 0x79, 0xed, 0x89, 0xbb, 0xbb, 0xe3, 0x38, 0xde, 0x75, 0x9e, 0xa6,
-	0x33, 0x11, 0x96, 0x7b, 0xcb, 0xde, 0x18, 0x25, 0x0f, 0x96, 0x14, 0xff, 0x10, 0xda, 0xfb, 0xbb,
-	0xfb, 0x64, 0x1f, 0x76, 0x7c, 0xb4, 0x99, 0x96, 0x18, 0xd1, 0xf9, 0x14, 0x25, 0xb5, 0x53, 0xa4,
-	0x1a, 0x8d, 0xca, 0x74, 0x88, 0x34, 0x52, 0x68, 0xa8, 0x54, 0x96, 0xe2, 0x5b, 0x61, 0xac, 0x4b,
-	0x3a, 0xb0, 0xf2, 0xb5, 0xe5, 0xac, 0xbd, 0xbe, 0x12, 0x74, 0xca, 0xd5, 0xd9, 0xfb, 0x1d, 0x00,
-	0x00, 0xff, 0xff, 0x02, 0xb3, 0x31, 0x85, 0x93, 0x03, 0x00, 0x00,
+func (m *GetProductByCodeRequest) Reset()         { *m = GetProductByCodeRequest{} }
+func (m *GetProductByCodeRequest) String() string { return proto.CompactTextString(m) }
+func (*GetProductByCodeRequest) ProtoMessage()    {}
+func (*GetProductByCodeRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_c181f2f37fbaedca, []int{5}
 }
 
-// Reference imports to suppress errors if they are not otherwise used.
-var _ context.Context
-var _ grpc.ClientConn
+func (m *GetProductByCodeRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetProductByCodeRequest.Unmarshal(m, b)
+}
+func (m *GetProductByCodeRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetProductByCodeRequest.Marshal(b, m, deterministic)
+}
+func (m *GetProductByCodeRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetProductByCodeRequest.Merge(m, src)
+}
+func (m *GetProductByCodeRequest) XXX_Size() int {
+	return xxx_messageInfo_GetProductByCodeRequest.Size(m)
+}
+func (m *GetProductByCodeRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetProductByCodeRequest.DiscardUnknown(m)
+}
 
-// This is a compile-time assertion to ensure that this generated file
-// is compatible with the grpc package it is being compiled against.
-const _ = grpc.SupportPackageIsVersion4
+var xxx_messageInfo_GetProductByCodeRequest proto.InternalMessageInfo
 
-// WebScraperClient is the client API for WebScraper service.
-//
-// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
-type WebScraperClient interface {
-	//This end point takes Amazon Product ASIN, and returns name, categories, ranks and dimensions
-	GetProduct(ctx context.Context, in *GetProductRequest, opts ...grpc.CallOption) (*GetProductResponse, error)
+func (m *GetProductByCodeRequest) GetCode() string {
+	if m != nil {
+		return m.Code
+	}
+	return ""
 }
 
-type webScraperClient struct {
-	cc *grpc.ClientConn
+func (m *GetProductByCodeRequest) GetCodeType() string {
+	if m != nil {
+		return m.CodeType
+	}
+	return ""
 }
 
-func NewWebScraperClient(cc *grpc.ClientConn) WebScraperClient {
-	return &webScraperClient{cc}
+func (m *GetProductByCodeRequest) GetMarketplace() string {
+	if m != nil {
+		return m.Marketplace
+	}
+	return ""
 }
 
-func (c *webScraperClient) GetProduct(ctx context.Context, in *GetProductRequest, opts ...grpc.CallOption) (*GetProductResponse, error) {
-	out := new(GetProductResponse)
-	err := c.cc.Invoke(ctx, "/v1.WebScraper/GetProduct", in, out, opts...)
-	if err != nil {
-		return nil, err
+//Expected Response From GetProductByCode
+type GetProductByCodeResponse struct {
+	Product              *Product `protobuf:"bytes,1,opt,name=product,proto3" json:"product,omitempty"`
+	Stale                bool     `protobuf:"varint,2,opt,name=stale,proto3" json:"stale,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetProductByCodeResponse) Reset()         { *m = GetProductByCodeResponse{} }
+func (m *GetProductByCodeResponse) String() string { return proto.CompactTextString(m) }
+func (*GetProductByCodeResponse) ProtoMessage()    {}
+func (*GetProductByCodeResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_c181f2f37fbaedca, []int{6}
+}
+
+func (m *GetProductByCodeResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetProductByCodeResponse.Unmarshal(m, b)
+}
+func (m *GetProductByCodeResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetProductByCodeResponse.Marshal(b, m, deterministic)
+}
+func (m *GetProductByCodeResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetProductByCodeResponse.Merge(m, src)
+}
+func (m *GetProductByCodeResponse) XXX_Size() int {
+	return xxx_messageInfo_GetProductByCodeResponse.Size(m)
+}
+func (m *GetProductByCodeResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetProductByCodeResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetProductByCodeResponse proto.InternalMessageInfo
+
+func (m *GetProductByCodeResponse) GetProduct() *Product {
+	if m != nil {
+		return m.Product
 	}
-	return out, nil
+	return nil
 }
 
-// WebScraperServer is the server API for WebScraper service.
-type WebScraperServer interface {
-	//This end point takes Amazon Product ASIN, and returns name, categories, ranks and dimensions
-	GetProduct(context.Context, *GetProductRequest) (*GetProductResponse, error)
+func (m *GetProductByCodeResponse) GetStale() bool {
+	if m != nil {
+		return m.Stale
+	}
+	return false
 }
 
-func RegisterWebScraperServer(s *grpc.Server, srv WebScraperServer) {
-	s.RegisterService(&_WebScraper_serviceDesc, srv)
+//A scrape of a product, with what changed since the previous snapshot
+type ProductSnapshot struct {
+	Product              *Product       `protobuf:"bytes,1,opt,name=product,proto3" json:"product,omitempty"`
+	Changes              []*FieldChange `protobuf:"bytes,2,rep,name=changes,proto3" json:"changes,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}       `json:"-"`
+	XXX_unrecognized     []byte         `json:"-"`
+	XXX_sizecache        int32          `json:"-"`
 }
 
-func _WebScraper_GetProduct_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(GetProductRequest)
-	if err := dec(in); err != nil {
-		return nil, err
+func (m *ProductSnapshot) Reset()         { *m = ProductSnapshot{} }
+func (m *ProductSnapshot) String() string { return proto.CompactTextString(m) }
+func (*ProductSnapshot) ProtoMessage()    {}
+func (*ProductSnapshot) Descriptor() ([]byte, []int) {
+	return fileDescriptor_c181f2f37fbaedca, []int{7}
+}
+
+func (m *ProductSnapshot) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ProductSnapshot.Unmarshal(m, b)
+}
+func (m *ProductSnapshot) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ProductSnapshot.Marshal(b, m, deterministic)
+}
+func (m *ProductSnapshot) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ProductSnapshot.Merge(m, src)
+}
+func (m *ProductSnapshot) XXX_Size() int {
+	return xxx_messageInfo_ProductSnapshot.Size(m)
+}
+func (m *ProductSnapshot) XXX_DiscardUnknown() {
+	xxx_messageInfo_ProductSnapshot.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ProductSnapshot proto.InternalMessageInfo
+
+func (m *ProductSnapshot) GetProduct() *Product {
+	if m != nil {
+		return m.Product
 	}
-	if interceptor == nil {
-		return srv.(WebScraperServer).GetProduct(ctx, in)
+	return nil
+}
+
+func (m *ProductSnapshot) GetChanges() []*FieldChange {
+	if m != nil {
+		return m.Changes
 	}
-	info := &grpc.UnaryServerInfo{
-		Server:     srv,
-		FullMethod: "/v1.WebScraper/GetProduct",
+	return nil
+}
+
+//A product field that changed between two snapshots
+type FieldChange struct {
+	Field                string   `protobuf:"bytes,1,opt,name=field,proto3" json:"field,omitempty"`
+	Before               []string `protobuf:"bytes,2,rep,name=before,proto3" json:"before,omitempty"`
+	After                []string `protobuf:"bytes,3,rep,name=after,proto3" json:"after,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *FieldChange) Reset()         { *m = FieldChange{} }
+func (m *FieldChange) String() string { return proto.CompactTextString(m) }
+func (*FieldChange) ProtoMessage()    {}
+func (*FieldChange) Descriptor() ([]byte, []int) {
+	return fileDescriptor_c181f2f37fbaedca, []int{8}
+}
+
+func (m *FieldChange) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_FieldChange.Unmarshal(m, b)
+}
+func (m *FieldChange) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_FieldChange.Marshal(b, m, deterministic)
+}
+func (m *FieldChange) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_FieldChange.Merge(m, src)
+}
+func (m *FieldChange) XXX_Size() int {
+	return xxx_messageInfo_FieldChange.Size(m)
+}
+func (m *FieldChange) XXX_DiscardUnknown() {
+	xxx_messageInfo_FieldChange.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_FieldChange proto.InternalMessageInfo
+
+func (m *FieldChange) GetField() string {
+	if m != nil {
+		return m.Field
 	}
-	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(WebScraperServer).GetProduct(ctx, req.(*GetProductRequest))
+	return ""
+}
+
+func (m *FieldChange) GetBefore() []string {
+	if m != nil {
+		return m.Before
 	}
-	return interceptor(ctx, in, info, handler)
+	return nil
 }
 
-var _WebScraper_serviceDesc = grpc.ServiceDesc{
-	ServiceName: "v1.WebScraper",
-	HandlerType: (*WebScraperServer)(nil),
-	Methods: []grpc.MethodDesc{
-		{
-			MethodName: "GetProduct",
-			Handler:    _WebScraper_GetProduct_Handler,
+func (m *FieldChange) GetAfter() []string {
+	if m != nil {
+		return m.After
+	}
+	return nil
+}
+
+//Expected Request For GetProductHistory
+type GetProductHistoryRequest struct {
+	Asin                 string               `protobuf:"bytes,1,opt,name=asin,proto3" json:"asin,omitempty"`
+	From                 *timestamp.Timestamp `protobuf:"bytes,2,opt,name=from,proto3" json:"from,omitempty"`
+	To                   *timestamp.Timestamp `protobuf:"bytes,3,opt,name=to,proto3" json:"to,omitempty"`
+	Interval             int64                `protobuf:"varint,4,opt,name=interval,proto3" json:"interval,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
+	XXX_unrecognized     []byte               `json:"-"`
+	XXX_sizecache        int32                `json:"-"`
+}
+
+func (m *GetProductHistoryRequest) Reset()         { *m = GetProductHistoryRequest{} }
+func (m *GetProductHistoryRequest) String() string { return proto.CompactTextString(m) }
+func (*GetProductHistoryRequest) ProtoMessage()    {}
+func (*GetProductHistoryRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_c181f2f37fbaedca, []int{9}
+}
+
+func (m *GetProductHistoryRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetProductHistoryRequest.Unmarshal(m, b)
+}
+func (m *GetProductHistoryRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetProductHistoryRequest.Marshal(b, m, deterministic)
+}
+func (m *GetProductHistoryRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetProductHistoryRequest.Merge(m, src)
+}
+func (m *GetProductHistoryRequest) XXX_Size() int {
+	return xxx_messageInfo_GetProductHistoryRequest.Size(m)
+}
+func (m *GetProductHistoryRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetProductHistoryRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetProductHistoryRequest proto.InternalMessageInfo
+
+func (m *GetProductHistoryRequest) GetAsin() string {
+	if m != nil {
+		return m.Asin
+	}
+	return ""
+}
+
+func (m *GetProductHistoryRequest) GetFrom() *timestamp.Timestamp {
+	if m != nil {
+		return m.From
+	}
+	return nil
+}
+
+func (m *GetProductHistoryRequest) GetTo() *timestamp.Timestamp {
+	if m != nil {
+		return m.To
+	}
+	return nil
+}
+
+func (m *GetProductHistoryRequest) GetInterval() int64 {
+	if m != nil {
+		return m.Interval
+	}
+	return 0
+}
+
+//Expected Response From GetProductHistory
+type GetProductHistoryResponse struct {
+	Snapshots            []*ProductSnapshot `protobuf:"bytes,1,rep,name=snapshots,proto3" json:"snapshots,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}           `json:"-"`
+	XXX_unrecognized     []byte             `json:"-"`
+	XXX_sizecache        int32              `json:"-"`
+}
+
+func (m *GetProductHistoryResponse) Reset()         { *m = GetProductHistoryResponse{} }
+func (m *GetProductHistoryResponse) String() string { return proto.CompactTextString(m) }
+func (*GetProductHistoryResponse) ProtoMessage()    {}
+func (*GetProductHistoryResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_c181f2f37fbaedca, []int{10}
+}
+
+func (m *GetProductHistoryResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetProductHistoryResponse.Unmarshal(m, b)
+}
+func (m *GetProductHistoryResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetProductHistoryResponse.Marshal(b, m, deterministic)
+}
+func (m *GetProductHistoryResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetProductHistoryResponse.Merge(m, src)
+}
+func (m *GetProductHistoryResponse) XXX_Size() int {
+	return xxx_messageInfo_GetProductHistoryResponse.Size(m)
+}
+func (m *GetProductHistoryResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetProductHistoryResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetProductHistoryResponse proto.InternalMessageInfo
+
+func (m *GetProductHistoryResponse) GetSnapshots() []*ProductSnapshot {
+	if m != nil {
+		return m.Snapshots
+	}
+	return nil
+}
+
+//Expected Request For ListProducts
+type ListProductsRequest struct {
+	//
+	// A page holds at most page_size products, like Redis SCAN it may hold fewer
+	// and may be empty while next_page_token is set. Keep paging until next_page_token is empty.
+	// next_page_token only continues a listing with the same filters
+	PageSize             int32                `protobuf:"varint,1,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	PageToken            string               `protobuf:"bytes,2,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
+	Category             string               `protobuf:"bytes,3,opt,name=category,proto3" json:"category,omitempty"`
+	MinRank              int64                `protobuf:"varint,4,opt,name=min_rank,json=minRank,proto3" json:"min_rank,omitempty"`
+	MaxRank              int64                `protobuf:"varint,5,opt,name=max_rank,json=maxRank,proto3" json:"max_rank,omitempty"`
+	CreatedAfter         *timestamp.Timestamp `protobuf:"bytes,6,opt,name=created_after,json=createdAfter,proto3" json:"created_after,omitempty"`
+	CreatedBefore        *timestamp.Timestamp `protobuf:"bytes,7,opt,name=created_before,json=createdBefore,proto3" json:"created_before,omitempty"`
+	Marketplace          string               `protobuf:"bytes,8,opt,name=marketplace,proto3" json:"marketplace,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
+	XXX_unrecognized     []byte               `json:"-"`
+	XXX_sizecache        int32                `json:"-"`
+}
+
+func (m *ListProductsRequest) Reset()         { *m = ListProductsRequest{} }
+func (m *ListProductsRequest) String() string { return proto.CompactTextString(m) }
+func (*ListProductsRequest) ProtoMessage()    {}
+func (*ListProductsRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_c181f2f37fbaedca, []int{11}
+}
+
+func (m *ListProductsRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListProductsRequest.Unmarshal(m, b)
+}
+func (m *ListProductsRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListProductsRequest.Marshal(b, m, deterministic)
+}
+func (m *ListProductsRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListProductsRequest.Merge(m, src)
+}
+func (m *ListProductsRequest) XXX_Size() int {
+	return xxx_messageInfo_ListProductsRequest.Size(m)
+}
+func (m *ListProductsRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListProductsRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListProductsRequest proto.InternalMessageInfo
+
+func (m *ListProductsRequest) GetPageSize() int32 {
+	if m != nil {
+		return m.PageSize
+	}
+	return 0
+}
+
+func (m *ListProductsRequest) GetPageToken() string {
+	if m != nil {
+		return m.PageToken
+	}
+	return ""
+}
+
+func (m *ListProductsRequest) GetCategory() string {
+	if m != nil {
+		return m.Category
+	}
+	return ""
+}
+
+func (m *ListProductsRequest) GetMinRank() int64 {
+	if m != nil {
+		return m.MinRank
+	}
+	return 0
+}
+
+func (m *ListProductsRequest) GetMaxRank() int64 {
+	if m != nil {
+		return m.MaxRank
+	}
+	return 0
+}
+
+func (m *ListProductsRequest) GetCreatedAfter() *timestamp.Timestamp {
+	if m != nil {
+		return m.CreatedAfter
+	}
+	return nil
+}
+
+func (m *ListProductsRequest) GetCreatedBefore() *timestamp.Timestamp {
+	if m != nil {
+		return m.CreatedBefore
+	}
+	return nil
+}
+
+func (m *ListProductsRequest) GetMarketplace() string {
+	if m != nil {
+		return m.Marketplace
+	}
+	return ""
+}
+
+//Expected Response From ListProducts
+type ListProductsResponse struct {
+	Products             []*Product `protobuf:"bytes,1,rep,name=products,proto3" json:"products,omitempty"`
+	NextPageToken        string     `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}   `json:"-"`
+	XXX_unrecognized     []byte     `json:"-"`
+	XXX_sizecache        int32      `json:"-"`
+}
+
+func (m *ListProductsResponse) Reset()         { *m = ListProductsResponse{} }
+func (m *ListProductsResponse) String() string { return proto.CompactTextString(m) }
+func (*ListProductsResponse) ProtoMessage()    {}
+func (*ListProductsResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_c181f2f37fbaedca, []int{12}
+}
+
+func (m *ListProductsResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListProductsResponse.Unmarshal(m, b)
+}
+func (m *ListProductsResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListProductsResponse.Marshal(b, m, deterministic)
+}
+func (m *ListProductsResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListProductsResponse.Merge(m, src)
+}
+func (m *ListProductsResponse) XXX_Size() int {
+	return xxx_messageInfo_ListProductsResponse.Size(m)
+}
+func (m *ListProductsResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListProductsResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListProductsResponse proto.InternalMessageInfo
+
+func (m *ListProductsResponse) GetProducts() []*Product {
+	if m != nil {
+		return m.Products
+	}
+	return nil
+}
+
+func (m *ListProductsResponse) GetNextPageToken() string {
+	if m != nil {
+		return m.NextPageToken
+	}
+	return ""
+}
+
+//Expected Request For ListTopRankedProducts
+type ListTopRankedProductsRequest struct {
+	Category             string   `protobuf:"bytes,1,opt,name=category,proto3" json:"category,omitempty"`
+	Limit                int32    `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ListTopRankedProductsRequest) Reset()         { *m = ListTopRankedProductsRequest{} }
+func (m *ListTopRankedProductsRequest) String() string { return proto.CompactTextString(m) }
+func (*ListTopRankedProductsRequest) ProtoMessage()    {}
+func (*ListTopRankedProductsRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_c181f2f37fbaedca, []int{13}
+}
+
+func (m *ListTopRankedProductsRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListTopRankedProductsRequest.Unmarshal(m, b)
+}
+func (m *ListTopRankedProductsRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListTopRankedProductsRequest.Marshal(b, m, deterministic)
+}
+func (m *ListTopRankedProductsRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListTopRankedProductsRequest.Merge(m, src)
+}
+func (m *ListTopRankedProductsRequest) XXX_Size() int {
+	return xxx_messageInfo_ListTopRankedProductsRequest.Size(m)
+}
+func (m *ListTopRankedProductsRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListTopRankedProductsRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListTopRankedProductsRequest proto.InternalMessageInfo
+
+func (m *ListTopRankedProductsRequest) GetCategory() string {
+	if m != nil {
+		return m.Category
+	}
+	return ""
+}
+
+func (m *ListTopRankedProductsRequest) GetLimit() int32 {
+	if m != nil {
+		return m.Limit
+	}
+	return 0
+}
+
+//Expected Response From ListTopRankedProducts
+type ListTopRankedProductsResponse struct {
+	Products             []*Product `protobuf:"bytes,1,rep,name=products,proto3" json:"products,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}   `json:"-"`
+	XXX_unrecognized     []byte     `json:"-"`
+	XXX_sizecache        int32      `json:"-"`
+}
+
+func (m *ListTopRankedProductsResponse) Reset()         { *m = ListTopRankedProductsResponse{} }
+func (m *ListTopRankedProductsResponse) String() string { return proto.CompactTextString(m) }
+func (*ListTopRankedProductsResponse) ProtoMessage()    {}
+func (*ListTopRankedProductsResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_c181f2f37fbaedca, []int{14}
+}
+
+func (m *ListTopRankedProductsResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListTopRankedProductsResponse.Unmarshal(m, b)
+}
+func (m *ListTopRankedProductsResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListTopRankedProductsResponse.Marshal(b, m, deterministic)
+}
+func (m *ListTopRankedProductsResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListTopRankedProductsResponse.Merge(m, src)
+}
+func (m *ListTopRankedProductsResponse) XXX_Size() int {
+	return xxx_messageInfo_ListTopRankedProductsResponse.Size(m)
+}
+func (m *ListTopRankedProductsResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListTopRankedProductsResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListTopRankedProductsResponse proto.InternalMessageInfo
+
+func (m *ListTopRankedProductsResponse) GetProducts() []*Product {
+	if m != nil {
+		return m.Products
+	}
+	return nil
+}
+
+//Expected Request For ListStaleProducts
+type ListStaleProductsRequest struct {
+	NotRefreshedFor      int64    `protobuf:"varint,1,opt,name=not_refreshed_for,json=notRefreshedFor,proto3" json:"not_refreshed_for,omitempty"`
+	Limit                int32    `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ListStaleProductsRequest) Reset()         { *m = ListStaleProductsRequest{} }
+func (m *ListStaleProductsRequest) String() string { return proto.CompactTextString(m) }
+func (*ListStaleProductsRequest) ProtoMessage()    {}
+func (*ListStaleProductsRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_c181f2f37fbaedca, []int{15}
+}
+
+func (m *ListStaleProductsRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListStaleProductsRequest.Unmarshal(m, b)
+}
+func (m *ListStaleProductsRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListStaleProductsRequest.Marshal(b, m, deterministic)
+}
+func (m *ListStaleProductsRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListStaleProductsRequest.Merge(m, src)
+}
+func (m *ListStaleProductsRequest) XXX_Size() int {
+	return xxx_messageInfo_ListStaleProductsRequest.Size(m)
+}
+func (m *ListStaleProductsRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListStaleProductsRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListStaleProductsRequest proto.InternalMessageInfo
+
+func (m *ListStaleProductsRequest) GetNotRefreshedFor() int64 {
+	if m != nil {
+		return m.NotRefreshedFor
+	}
+	return 0
+}
+
+func (m *ListStaleProductsRequest) GetLimit() int32 {
+	if m != nil {
+		return m.Limit
+	}
+	return 0
+}
+
+//Expected Response From ListStaleProducts
+type ListStaleProductsResponse struct {
+	Products             []*Product `protobuf:"bytes,1,rep,name=products,proto3" json:"products,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}   `json:"-"`
+	XXX_unrecognized     []byte     `json:"-"`
+	XXX_sizecache        int32      `json:"-"`
+}
+
+func (m *ListStaleProductsResponse) Reset()         { *m = ListStaleProductsResponse{} }
+func (m *ListStaleProductsResponse) String() string { return proto.CompactTextString(m) }
+func (*ListStaleProductsResponse) ProtoMessage()    {}
+func (*ListStaleProductsResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_c181f2f37fbaedca, []int{16}
+}
+
+func (m *ListStaleProductsResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListStaleProductsResponse.Unmarshal(m, b)
+}
+func (m *ListStaleProductsResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListStaleProductsResponse.Marshal(b, m, deterministic)
+}
+func (m *ListStaleProductsResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListStaleProductsResponse.Merge(m, src)
+}
+func (m *ListStaleProductsResponse) XXX_Size() int {
+	return xxx_messageInfo_ListStaleProductsResponse.Size(m)
+}
+func (m *ListStaleProductsResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListStaleProductsResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListStaleProductsResponse proto.InternalMessageInfo
+
+func (m *ListStaleProductsResponse) GetProducts() []*Product {
+	if m != nil {
+		return m.Products
+	}
+	return nil
+}
+
+//Expected Request For SearchStoredProducts
+type SearchStoredProductsRequest struct {
+	Query                string   `protobuf:"bytes,1,opt,name=query,proto3" json:"query,omitempty"`
+	Category             string   `protobuf:"bytes,2,opt,name=category,proto3" json:"category,omitempty"`
+	Limit                int32    `protobuf:"varint,3,opt,name=limit,proto3" json:"limit,omitempty"`
+	Offset               int32    `protobuf:"varint,4,opt,name=offset,proto3" json:"offset,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *SearchStoredProductsRequest) Reset()         { *m = SearchStoredProductsRequest{} }
+func (m *SearchStoredProductsRequest) String() string { return proto.CompactTextString(m) }
+func (*SearchStoredProductsRequest) ProtoMessage()    {}
+func (*SearchStoredProductsRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_c181f2f37fbaedca, []int{17}
+}
+
+func (m *SearchStoredProductsRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SearchStoredProductsRequest.Unmarshal(m, b)
+}
+func (m *SearchStoredProductsRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SearchStoredProductsRequest.Marshal(b, m, deterministic)
+}
+func (m *SearchStoredProductsRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SearchStoredProductsRequest.Merge(m, src)
+}
+func (m *SearchStoredProductsRequest) XXX_Size() int {
+	return xxx_messageInfo_SearchStoredProductsRequest.Size(m)
+}
+func (m *SearchStoredProductsRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_SearchStoredProductsRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SearchStoredProductsRequest proto.InternalMessageInfo
+
+func (m *SearchStoredProductsRequest) GetQuery() string {
+	if m != nil {
+		return m.Query
+	}
+	return ""
+}
+
+func (m *SearchStoredProductsRequest) GetCategory() string {
+	if m != nil {
+		return m.Category
+	}
+	return ""
+}
+
+func (m *SearchStoredProductsRequest) GetLimit() int32 {
+	if m != nil {
+		return m.Limit
+	}
+	return 0
+}
+
+func (m *SearchStoredProductsRequest) GetOffset() int32 {
+	if m != nil {
+		return m.Offset
+	}
+	return 0
+}
+
+//Matches in a field, wrapped in <em></em>
+type FieldHighlight struct {
+	Field                string   `protobuf:"bytes,1,opt,name=field,proto3" json:"field,omitempty"`
+	Fragments            []string `protobuf:"bytes,2,rep,name=fragments,proto3" json:"fragments,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *FieldHighlight) Reset()         { *m = FieldHighlight{} }
+func (m *FieldHighlight) String() string { return proto.CompactTextString(m) }
+func (*FieldHighlight) ProtoMessage()    {}
+func (*FieldHighlight) Descriptor() ([]byte, []int) {
+	return fileDescriptor_c181f2f37fbaedca, []int{18}
+}
+
+func (m *FieldHighlight) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_FieldHighlight.Unmarshal(m, b)
+}
+func (m *FieldHighlight) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_FieldHighlight.Marshal(b, m, deterministic)
+}
+func (m *FieldHighlight) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_FieldHighlight.Merge(m, src)
+}
+func (m *FieldHighlight) XXX_Size() int {
+	return xxx_messageInfo_FieldHighlight.Size(m)
+}
+func (m *FieldHighlight) XXX_DiscardUnknown() {
+	xxx_messageInfo_FieldHighlight.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_FieldHighlight proto.InternalMessageInfo
+
+func (m *FieldHighlight) GetField() string {
+	if m != nil {
+		return m.Field
+	}
+	return ""
+}
+
+func (m *FieldHighlight) GetFragments() []string {
+	if m != nil {
+		return m.Fragments
+	}
+	return nil
+}
+
+//A product matching a search
+type SearchHit struct {
+	Product              *Product          `protobuf:"bytes,1,opt,name=product,proto3" json:"product,omitempty"`
+	Score                float64           `protobuf:"fixed64,2,opt,name=score,proto3" json:"score,omitempty"`
+	Highlights           []*FieldHighlight `protobuf:"bytes,3,rep,name=highlights,proto3" json:"highlights,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
+	XXX_unrecognized     []byte            `json:"-"`
+	XXX_sizecache        int32             `json:"-"`
+}
+
+func (m *SearchHit) Reset()         { *m = SearchHit{} }
+func (m *SearchHit) String() string { return proto.CompactTextString(m) }
+func (*SearchHit) ProtoMessage()    {}
+func (*SearchHit) Descriptor() ([]byte, []int) {
+	return fileDescriptor_c181f2f37fbaedca, []int{19}
+}
+
+func (m *SearchHit) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SearchHit.Unmarshal(m, b)
+}
+func (m *SearchHit) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SearchHit.Marshal(b, m, deterministic)
+}
+func (m *SearchHit) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SearchHit.Merge(m, src)
+}
+func (m *SearchHit) XXX_Size() int {
+	return xxx_messageInfo_SearchHit.Size(m)
+}
+func (m *SearchHit) XXX_DiscardUnknown() {
+	xxx_messageInfo_SearchHit.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SearchHit proto.InternalMessageInfo
+
+func (m *SearchHit) GetProduct() *Product {
+	if m != nil {
+		return m.Product
+	}
+	return nil
+}
+
+func (m *SearchHit) GetScore() float64 {
+	if m != nil {
+		return m.Score
+	}
+	return 0
+}
+
+func (m *SearchHit) GetHighlights() []*FieldHighlight {
+	if m != nil {
+		return m.Highlights
+	}
+	return nil
+}
+
+//How many matching products are in a category
+type CategoryFacet struct {
+	Category             string   `protobuf:"bytes,1,opt,name=category,proto3" json:"category,omitempty"`
+	Count                int32    `protobuf:"varint,2,opt,name=count,proto3" json:"count,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *CategoryFacet) Reset()         { *m = CategoryFacet{} }
+func (m *CategoryFacet) String() string { return proto.CompactTextString(m) }
+func (*CategoryFacet) ProtoMessage()    {}
+func (*CategoryFacet) Descriptor() ([]byte, []int) {
+	return fileDescriptor_c181f2f37fbaedca, []int{20}
+}
+
+func (m *CategoryFacet) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CategoryFacet.Unmarshal(m, b)
+}
+func (m *CategoryFacet) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CategoryFacet.Marshal(b, m, deterministic)
+}
+func (m *CategoryFacet) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CategoryFacet.Merge(m, src)
+}
+func (m *CategoryFacet) XXX_Size() int {
+	return xxx_messageInfo_CategoryFacet.Size(m)
+}
+func (m *CategoryFacet) XXX_DiscardUnknown() {
+	xxx_messageInfo_CategoryFacet.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CategoryFacet proto.InternalMessageInfo
+
+func (m *CategoryFacet) GetCategory() string {
+	if m != nil {
+		return m.Category
+	}
+	return ""
+}
+
+func (m *CategoryFacet) GetCount() int32 {
+	if m != nil {
+		return m.Count
+	}
+	return 0
+}
+
+//Expected Response From SearchStoredProducts
+type SearchStoredProductsResponse struct {
+	Hits                 []*SearchHit     `protobuf:"bytes,1,rep,name=hits,proto3" json:"hits,omitempty"`
+	Total                int32            `protobuf:"varint,2,opt,name=total,proto3" json:"total,omitempty"`
+	Facets               []*CategoryFacet `protobuf:"bytes,3,rep,name=facets,proto3" json:"facets,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}         `json:"-"`
+	XXX_unrecognized     []byte           `json:"-"`
+	XXX_sizecache        int32            `json:"-"`
+}
+
+func (m *SearchStoredProductsResponse) Reset()         { *m = SearchStoredProductsResponse{} }
+func (m *SearchStoredProductsResponse) String() string { return proto.CompactTextString(m) }
+func (*SearchStoredProductsResponse) ProtoMessage()    {}
+func (*SearchStoredProductsResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_c181f2f37fbaedca, []int{21}
+}
+
+func (m *SearchStoredProductsResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SearchStoredProductsResponse.Unmarshal(m, b)
+}
+func (m *SearchStoredProductsResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SearchStoredProductsResponse.Marshal(b, m, deterministic)
+}
+func (m *SearchStoredProductsResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SearchStoredProductsResponse.Merge(m, src)
+}
+func (m *SearchStoredProductsResponse) XXX_Size() int {
+	return xxx_messageInfo_SearchStoredProductsResponse.Size(m)
+}
+func (m *SearchStoredProductsResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_SearchStoredProductsResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SearchStoredProductsResponse proto.InternalMessageInfo
+
+func (m *SearchStoredProductsResponse) GetHits() []*SearchHit {
+	if m != nil {
+		return m.Hits
+	}
+	return nil
+}
+
+func (m *SearchStoredProductsResponse) GetTotal() int32 {
+	if m != nil {
+		return m.Total
+	}
+	return 0
+}
+
+func (m *SearchStoredProductsResponse) GetFacets() []*CategoryFacet {
+	if m != nil {
+		return m.Facets
+	}
+	return nil
+}
+
+//Expected Request For RefreshProduct
+type RefreshProductRequest struct {
+	Asin                 string   `protobuf:"bytes,1,opt,name=asin,proto3" json:"asin,omitempty"`
+	Marketplace          string   `protobuf:"bytes,2,opt,name=marketplace,proto3" json:"marketplace,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RefreshProductRequest) Reset()         { *m = RefreshProductRequest{} }
+func (m *RefreshProductRequest) String() string { return proto.CompactTextString(m) }
+func (*RefreshProductRequest) ProtoMessage()    {}
+func (*RefreshProductRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_c181f2f37fbaedca, []int{22}
+}
+
+func (m *RefreshProductRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RefreshProductRequest.Unmarshal(m, b)
+}
+func (m *RefreshProductRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RefreshProductRequest.Marshal(b, m, deterministic)
+}
+func (m *RefreshProductRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RefreshProductRequest.Merge(m, src)
+}
+func (m *RefreshProductRequest) XXX_Size() int {
+	return xxx_messageInfo_RefreshProductRequest.Size(m)
+}
+func (m *RefreshProductRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_RefreshProductRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RefreshProductRequest proto.InternalMessageInfo
+
+func (m *RefreshProductRequest) GetAsin() string {
+	if m != nil {
+		return m.Asin
+	}
+	return ""
+}
+
+func (m *RefreshProductRequest) GetMarketplace() string {
+	if m != nil {
+		return m.Marketplace
+	}
+	return ""
+}
+
+//Expected Response From RefreshProduct
+type RefreshProductResponse struct {
+	Product              *Product       `protobuf:"bytes,1,opt,name=product,proto3" json:"product,omitempty"`
+	Previous             *Product       `protobuf:"bytes,2,opt,name=previous,proto3" json:"previous,omitempty"`
+	Changes              []*FieldChange `protobuf:"bytes,3,rep,name=changes,proto3" json:"changes,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}       `json:"-"`
+	XXX_unrecognized     []byte         `json:"-"`
+	XXX_sizecache        int32          `json:"-"`
+}
+
+func (m *RefreshProductResponse) Reset()         { *m = RefreshProductResponse{} }
+func (m *RefreshProductResponse) String() string { return proto.CompactTextString(m) }
+func (*RefreshProductResponse) ProtoMessage()    {}
+func (*RefreshProductResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_c181f2f37fbaedca, []int{23}
+}
+
+func (m *RefreshProductResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RefreshProductResponse.Unmarshal(m, b)
+}
+func (m *RefreshProductResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RefreshProductResponse.Marshal(b, m, deterministic)
+}
+func (m *RefreshProductResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RefreshProductResponse.Merge(m, src)
+}
+func (m *RefreshProductResponse) XXX_Size() int {
+	return xxx_messageInfo_RefreshProductResponse.Size(m)
+}
+func (m *RefreshProductResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_RefreshProductResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RefreshProductResponse proto.InternalMessageInfo
+
+func (m *RefreshProductResponse) GetProduct() *Product {
+	if m != nil {
+		return m.Product
+	}
+	return nil
+}
+
+func (m *RefreshProductResponse) GetPrevious() *Product {
+	if m != nil {
+		return m.Previous
+	}
+	return nil
+}
+
+func (m *RefreshProductResponse) GetChanges() []*FieldChange {
+	if m != nil {
+		return m.Changes
+	}
+	return nil
+}
+
+//Expected Request For AddToWatchlist
+type AddToWatchlistRequest struct {
+	Asin                 string   `protobuf:"bytes,1,opt,name=asin,proto3" json:"asin,omitempty"`
+	Interval             int64    `protobuf:"varint,2,opt,name=interval,proto3" json:"interval,omitempty"`
+	Marketplace          string   `protobuf:"bytes,3,opt,name=marketplace,proto3" json:"marketplace,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *AddToWatchlistRequest) Reset()         { *m = AddToWatchlistRequest{} }
+func (m *AddToWatchlistRequest) String() string { return proto.CompactTextString(m) }
+func (*AddToWatchlistRequest) ProtoMessage()    {}
+func (*AddToWatchlistRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_c181f2f37fbaedca, []int{24}
+}
+
+func (m *AddToWatchlistRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_AddToWatchlistRequest.Unmarshal(m, b)
+}
+func (m *AddToWatchlistRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_AddToWatchlistRequest.Marshal(b, m, deterministic)
+}
+func (m *AddToWatchlistRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_AddToWatchlistRequest.Merge(m, src)
+}
+func (m *AddToWatchlistRequest) XXX_Size() int {
+	return xxx_messageInfo_AddToWatchlistRequest.Size(m)
+}
+func (m *AddToWatchlistRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_AddToWatchlistRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_AddToWatchlistRequest proto.InternalMessageInfo
+
+func (m *AddToWatchlistRequest) GetAsin() string {
+	if m != nil {
+		return m.Asin
+	}
+	return ""
+}
+
+func (m *AddToWatchlistRequest) GetInterval() int64 {
+	if m != nil {
+		return m.Interval
+	}
+	return 0
+}
+
+func (m *AddToWatchlistRequest) GetMarketplace() string {
+	if m != nil {
+		return m.Marketplace
+	}
+	return ""
+}
+
+//Expected Response From AddToWatchlist
+type AddToWatchlistResponse struct {
+	Asin                 string               `protobuf:"bytes,1,opt,name=asin,proto3" json:"asin,omitempty"`
+	NextRefresh          *timestamp.Timestamp `protobuf:"bytes,2,opt,name=next_refresh,json=nextRefresh,proto3" json:"next_refresh,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
+	XXX_unrecognized     []byte               `json:"-"`
+	XXX_sizecache        int32                `json:"-"`
+}
+
+func (m *AddToWatchlistResponse) Reset()         { *m = AddToWatchlistResponse{} }
+func (m *AddToWatchlistResponse) String() string { return proto.CompactTextString(m) }
+func (*AddToWatchlistResponse) ProtoMessage()    {}
+func (*AddToWatchlistResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_c181f2f37fbaedca, []int{25}
+}
+
+func (m *AddToWatchlistResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_AddToWatchlistResponse.Unmarshal(m, b)
+}
+func (m *AddToWatchlistResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_AddToWatchlistResponse.Marshal(b, m, deterministic)
+}
+func (m *AddToWatchlistResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_AddToWatchlistResponse.Merge(m, src)
+}
+func (m *AddToWatchlistResponse) XXX_Size() int {
+	return xxx_messageInfo_AddToWatchlistResponse.Size(m)
+}
+func (m *AddToWatchlistResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_AddToWatchlistResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_AddToWatchlistResponse proto.InternalMessageInfo
+
+func (m *AddToWatchlistResponse) GetAsin() string {
+	if m != nil {
+		return m.Asin
+	}
+	return ""
+}
+
+func (m *AddToWatchlistResponse) GetNextRefresh() *timestamp.Timestamp {
+	if m != nil {
+		return m.NextRefresh
+	}
+	return nil
+}
+
+//Expected Request For RemoveFromWatchlist
+type RemoveFromWatchlistRequest struct {
+	Asin                 string   `protobuf:"bytes,1,opt,name=asin,proto3" json:"asin,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RemoveFromWatchlistRequest) Reset()         { *m = RemoveFromWatchlistRequest{} }
+func (m *RemoveFromWatchlistRequest) String() string { return proto.CompactTextString(m) }
+func (*RemoveFromWatchlistRequest) ProtoMessage()    {}
+func (*RemoveFromWatchlistRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_c181f2f37fbaedca, []int{26}
+}
+
+func (m *RemoveFromWatchlistRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RemoveFromWatchlistRequest.Unmarshal(m, b)
+}
+func (m *RemoveFromWatchlistRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RemoveFromWatchlistRequest.Marshal(b, m, deterministic)
+}
+func (m *RemoveFromWatchlistRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RemoveFromWatchlistRequest.Merge(m, src)
+}
+func (m *RemoveFromWatchlistRequest) XXX_Size() int {
+	return xxx_messageInfo_RemoveFromWatchlistRequest.Size(m)
+}
+func (m *RemoveFromWatchlistRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_RemoveFromWatchlistRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RemoveFromWatchlistRequest proto.InternalMessageInfo
+
+func (m *RemoveFromWatchlistRequest) GetAsin() string {
+	if m != nil {
+		return m.Asin
+	}
+	return ""
+}
+
+//Expected Response From RemoveFromWatchlist
+type RemoveFromWatchlistResponse struct {
+	Removed              bool     `protobuf:"varint,1,opt,name=removed,proto3" json:"removed,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RemoveFromWatchlistResponse) Reset()         { *m = RemoveFromWatchlistResponse{} }
+func (m *RemoveFromWatchlistResponse) String() string { return proto.CompactTextString(m) }
+func (*RemoveFromWatchlistResponse) ProtoMessage()    {}
+func (*RemoveFromWatchlistResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_c181f2f37fbaedca, []int{27}
+}
+
+func (m *RemoveFromWatchlistResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RemoveFromWatchlistResponse.Unmarshal(m, b)
+}
+func (m *RemoveFromWatchlistResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RemoveFromWatchlistResponse.Marshal(b, m, deterministic)
+}
+func (m *RemoveFromWatchlistResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RemoveFromWatchlistResponse.Merge(m, src)
+}
+func (m *RemoveFromWatchlistResponse) XXX_Size() int {
+	return xxx_messageInfo_RemoveFromWatchlistResponse.Size(m)
+}
+func (m *RemoveFromWatchlistResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_RemoveFromWatchlistResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RemoveFromWatchlistResponse proto.InternalMessageInfo
+
+func (m *RemoveFromWatchlistResponse) GetRemoved() bool {
+	if m != nil {
+		return m.Removed
+	}
+	return false
+}
+
+//A batch of products scraped in the background
+type Job struct {
+	Id                   string               `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Status               Job_Status           `protobuf:"varint,2,opt,name=status,proto3,enum=v1.Job_Status" json:"status,omitempty"`
+	Total                int32                `protobuf:"varint,3,opt,name=total,proto3" json:"total,omitempty"`
+	Succeeded            int32                `protobuf:"varint,4,opt,name=succeeded,proto3" json:"succeeded,omitempty"`
+	Failed               int32                `protobuf:"varint,5,opt,name=failed,proto3" json:"failed,omitempty"`
+	ForceRefresh         bool                 `protobuf:"varint,6,opt,name=force_refresh,json=forceRefresh,proto3" json:"force_refresh,omitempty"`
+	CreatedAt            *timestamp.Timestamp `protobuf:"bytes,7,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	StartedAt            *timestamp.Timestamp `protobuf:"bytes,8,opt,name=started_at,json=startedAt,proto3" json:"started_at,omitempty"`
+	FinishedAt           *timestamp.Timestamp `protobuf:"bytes,9,opt,name=finished_at,json=finishedAt,proto3" json:"finished_at,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
+	XXX_unrecognized     []byte               `json:"-"`
+	XXX_sizecache        int32                `json:"-"`
+}
+
+func (m *Job) Reset()         { *m = Job{} }
+func (m *Job) String() string { return proto.CompactTextString(m) }
+func (*Job) ProtoMessage()    {}
+func (*Job) Descriptor() ([]byte, []int) {
+	return fileDescriptor_c181f2f37fbaedca, []int{28}
+}
+
+func (m *Job) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Job.Unmarshal(m, b)
+}
+func (m *Job) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Job.Marshal(b, m, deterministic)
+}
+func (m *Job) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Job.Merge(m, src)
+}
+func (m *Job) XXX_Size() int {
+	return xxx_messageInfo_Job.Size(m)
+}
+func (m *Job) XXX_DiscardUnknown() {
+	xxx_messageInfo_Job.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Job proto.InternalMessageInfo
+
+func (m *Job) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *Job) GetStatus() Job_Status {
+	if m != nil {
+		return m.Status
+	}
+	return Job_PENDING
+}
+
+func (m *Job) GetTotal() int32 {
+	if m != nil {
+		return m.Total
+	}
+	return 0
+}
+
+func (m *Job) GetSucceeded() int32 {
+	if m != nil {
+		return m.Succeeded
+	}
+	return 0
+}
+
+func (m *Job) GetFailed() int32 {
+	if m != nil {
+		return m.Failed
+	}
+	return 0
+}
+
+func (m *Job) GetForceRefresh() bool {
+	if m != nil {
+		return m.ForceRefresh
+	}
+	return false
+}
+
+func (m *Job) GetCreatedAt() *timestamp.Timestamp {
+	if m != nil {
+		return m.CreatedAt
+	}
+	return nil
+}
+
+func (m *Job) GetStartedAt() *timestamp.Timestamp {
+	if m != nil {
+		return m.StartedAt
+	}
+	return nil
+}
+
+func (m *Job) GetFinishedAt() *timestamp.Timestamp {
+	if m != nil {
+		return m.FinishedAt
+	}
+	return nil
+}
+
+//The result of a product in a job
+type JobResult struct {
+	Asin                 string   `protobuf:"bytes,1,opt,name=asin,proto3" json:"asin,omitempty"`
+	Product              *Product `protobuf:"bytes,2,opt,name=product,proto3" json:"product,omitempty"`
+	Error                string   `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *JobResult) Reset()         { *m = JobResult{} }
+func (m *JobResult) String() string { return proto.CompactTextString(m) }
+func (*JobResult) ProtoMessage()    {}
+func (*JobResult) Descriptor() ([]byte, []int) {
+	return fileDescriptor_c181f2f37fbaedca, []int{29}
+}
+
+func (m *JobResult) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_JobResult.Unmarshal(m, b)
+}
+func (m *JobResult) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_JobResult.Marshal(b, m, deterministic)
+}
+func (m *JobResult) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_JobResult.Merge(m, src)
+}
+func (m *JobResult) XXX_Size() int {
+	return xxx_messageInfo_JobResult.Size(m)
+}
+func (m *JobResult) XXX_DiscardUnknown() {
+	xxx_messageInfo_JobResult.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_JobResult proto.InternalMessageInfo
+
+func (m *JobResult) GetAsin() string {
+	if m != nil {
+		return m.Asin
+	}
+	return ""
+}
+
+func (m *JobResult) GetProduct() *Product {
+	if m != nil {
+		return m.Product
+	}
+	return nil
+}
+
+func (m *JobResult) GetError() string {
+	if m != nil {
+		return m.Error
+	}
+	return ""
+}
+
+//Expected Request For SubmitScrapeJob
+type SubmitScrapeJobRequest struct {
+	Asins                []string `protobuf:"bytes,1,rep,name=asins,proto3" json:"asins,omitempty"`
+	ForceRefresh         bool     `protobuf:"varint,2,opt,name=force_refresh,json=forceRefresh,proto3" json:"force_refresh,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *SubmitScrapeJobRequest) Reset()         { *m = SubmitScrapeJobRequest{} }
+func (m *SubmitScrapeJobRequest) String() string { return proto.CompactTextString(m) }
+func (*SubmitScrapeJobRequest) ProtoMessage()    {}
+func (*SubmitScrapeJobRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_c181f2f37fbaedca, []int{30}
+}
+
+func (m *SubmitScrapeJobRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SubmitScrapeJobRequest.Unmarshal(m, b)
+}
+func (m *SubmitScrapeJobRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SubmitScrapeJobRequest.Marshal(b, m, deterministic)
+}
+func (m *SubmitScrapeJobRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SubmitScrapeJobRequest.Merge(m, src)
+}
+func (m *SubmitScrapeJobRequest) XXX_Size() int {
+	return xxx_messageInfo_SubmitScrapeJobRequest.Size(m)
+}
+func (m *SubmitScrapeJobRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_SubmitScrapeJobRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SubmitScrapeJobRequest proto.InternalMessageInfo
+
+func (m *SubmitScrapeJobRequest) GetAsins() []string {
+	if m != nil {
+		return m.Asins
+	}
+	return nil
+}
+
+func (m *SubmitScrapeJobRequest) GetForceRefresh() bool {
+	if m != nil {
+		return m.ForceRefresh
+	}
+	return false
+}
+
+//Expected Response From SubmitScrapeJob
+type SubmitScrapeJobResponse struct {
+	Job                  *Job     `protobuf:"bytes,1,opt,name=job,proto3" json:"job,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *SubmitScrapeJobResponse) Reset()         { *m = SubmitScrapeJobResponse{} }
+func (m *SubmitScrapeJobResponse) String() string { return proto.CompactTextString(m) }
+func (*SubmitScrapeJobResponse) ProtoMessage()    {}
+func (*SubmitScrapeJobResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_c181f2f37fbaedca, []int{31}
+}
+
+func (m *SubmitScrapeJobResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SubmitScrapeJobResponse.Unmarshal(m, b)
+}
+func (m *SubmitScrapeJobResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SubmitScrapeJobResponse.Marshal(b, m, deterministic)
+}
+func (m *SubmitScrapeJobResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SubmitScrapeJobResponse.Merge(m, src)
+}
+func (m *SubmitScrapeJobResponse) XXX_Size() int {
+	return xxx_messageInfo_SubmitScrapeJobResponse.Size(m)
+}
+func (m *SubmitScrapeJobResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_SubmitScrapeJobResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SubmitScrapeJobResponse proto.InternalMessageInfo
+
+func (m *SubmitScrapeJobResponse) GetJob() *Job {
+	if m != nil {
+		return m.Job
+	}
+	return nil
+}
+
+//Expected Request For GetJob
+type GetJobRequest struct {
+	Id                   string   `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetJobRequest) Reset()         { *m = GetJobRequest{} }
+func (m *GetJobRequest) String() string { return proto.CompactTextString(m) }
+func (*GetJobRequest) ProtoMessage()    {}
+func (*GetJobRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_c181f2f37fbaedca, []int{32}
+}
+
+func (m *GetJobRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetJobRequest.Unmarshal(m, b)
+}
+func (m *GetJobRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetJobRequest.Marshal(b, m, deterministic)
+}
+func (m *GetJobRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetJobRequest.Merge(m, src)
+}
+func (m *GetJobRequest) XXX_Size() int {
+	return xxx_messageInfo_GetJobRequest.Size(m)
+}
+func (m *GetJobRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetJobRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetJobRequest proto.InternalMessageInfo
+
+func (m *GetJobRequest) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+//Expected Response From GetJob
+type GetJobResponse struct {
+	Job                  *Job     `protobuf:"bytes,1,opt,name=job,proto3" json:"job,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetJobResponse) Reset()         { *m = GetJobResponse{} }
+func (m *GetJobResponse) String() string { return proto.CompactTextString(m) }
+func (*GetJobResponse) ProtoMessage()    {}
+func (*GetJobResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_c181f2f37fbaedca, []int{33}
+}
+
+func (m *GetJobResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetJobResponse.Unmarshal(m, b)
+}
+func (m *GetJobResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetJobResponse.Marshal(b, m, deterministic)
+}
+func (m *GetJobResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetJobResponse.Merge(m, src)
+}
+func (m *GetJobResponse) XXX_Size() int {
+	return xxx_messageInfo_GetJobResponse.Size(m)
+}
+func (m *GetJobResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetJobResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetJobResponse proto.InternalMessageInfo
+
+func (m *GetJobResponse) GetJob() *Job {
+	if m != nil {
+		return m.Job
+	}
+	return nil
+}
+
+//Expected Request For ListJobs
+type ListJobsRequest struct {
+	PageSize             int32    `protobuf:"varint,1,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	PageToken            string   `protobuf:"bytes,2,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ListJobsRequest) Reset()         { *m = ListJobsRequest{} }
+func (m *ListJobsRequest) String() string { return proto.CompactTextString(m) }
+func (*ListJobsRequest) ProtoMessage()    {}
+func (*ListJobsRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_c181f2f37fbaedca, []int{34}
+}
+
+func (m *ListJobsRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListJobsRequest.Unmarshal(m, b)
+}
+func (m *ListJobsRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListJobsRequest.Marshal(b, m, deterministic)
+}
+func (m *ListJobsRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListJobsRequest.Merge(m, src)
+}
+func (m *ListJobsRequest) XXX_Size() int {
+	return xxx_messageInfo_ListJobsRequest.Size(m)
+}
+func (m *ListJobsRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListJobsRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListJobsRequest proto.InternalMessageInfo
+
+func (m *ListJobsRequest) GetPageSize() int32 {
+	if m != nil {
+		return m.PageSize
+	}
+	return 0
+}
+
+func (m *ListJobsRequest) GetPageToken() string {
+	if m != nil {
+		return m.PageToken
+	}
+	return ""
+}
+
+//Expected Response From ListJobs
+type ListJobsResponse struct {
+	Jobs                 []*Job   `protobuf:"bytes,1,rep,name=jobs,proto3" json:"jobs,omitempty"`
+	NextPageToken        string   `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ListJobsResponse) Reset()         { *m = ListJobsResponse{} }
+func (m *ListJobsResponse) String() string { return proto.CompactTextString(m) }
+func (*ListJobsResponse) ProtoMessage()    {}
+func (*ListJobsResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_c181f2f37fbaedca, []int{35}
+}
+
+func (m *ListJobsResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListJobsResponse.Unmarshal(m, b)
+}
+func (m *ListJobsResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListJobsResponse.Marshal(b, m, deterministic)
+}
+func (m *ListJobsResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListJobsResponse.Merge(m, src)
+}
+func (m *ListJobsResponse) XXX_Size() int {
+	return xxx_messageInfo_ListJobsResponse.Size(m)
+}
+func (m *ListJobsResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListJobsResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListJobsResponse proto.InternalMessageInfo
+
+func (m *ListJobsResponse) GetJobs() []*Job {
+	if m != nil {
+		return m.Jobs
+	}
+	return nil
+}
+
+func (m *ListJobsResponse) GetNextPageToken() string {
+	if m != nil {
+		return m.NextPageToken
+	}
+	return ""
+}
+
+//Expected Request For CancelJob
+type CancelJobRequest struct {
+	Id                   string   `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *CancelJobRequest) Reset()         { *m = CancelJobRequest{} }
+func (m *CancelJobRequest) String() string { return proto.CompactTextString(m) }
+func (*CancelJobRequest) ProtoMessage()    {}
+func (*CancelJobRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_c181f2f37fbaedca, []int{36}
+}
+
+func (m *CancelJobRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CancelJobRequest.Unmarshal(m, b)
+}
+func (m *CancelJobRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CancelJobRequest.Marshal(b, m, deterministic)
+}
+func (m *CancelJobRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CancelJobRequest.Merge(m, src)
+}
+func (m *CancelJobRequest) XXX_Size() int {
+	return xxx_messageInfo_CancelJobRequest.Size(m)
+}
+func (m *CancelJobRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_CancelJobRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CancelJobRequest proto.InternalMessageInfo
+
+func (m *CancelJobRequest) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+//Expected Response From CancelJob
+type CancelJobResponse struct {
+	Job                  *Job     `protobuf:"bytes,1,opt,name=job,proto3" json:"job,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *CancelJobResponse) Reset()         { *m = CancelJobResponse{} }
+func (m *CancelJobResponse) String() string { return proto.CompactTextString(m) }
+func (*CancelJobResponse) ProtoMessage()    {}
+func (*CancelJobResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_c181f2f37fbaedca, []int{37}
+}
+
+func (m *CancelJobResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CancelJobResponse.Unmarshal(m, b)
+}
+func (m *CancelJobResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CancelJobResponse.Marshal(b, m, deterministic)
+}
+func (m *CancelJobResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CancelJobResponse.Merge(m, src)
+}
+func (m *CancelJobResponse) XXX_Size() int {
+	return xxx_messageInfo_CancelJobResponse.Size(m)
+}
+func (m *CancelJobResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_CancelJobResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CancelJobResponse proto.InternalMessageInfo
+
+func (m *CancelJobResponse) GetJob() *Job {
+	if m != nil {
+		return m.Job
+	}
+	return nil
+}
+
+//Expected Request For GetJobResults
+type GetJobResultsRequest struct {
+	Id                   string   `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	PageSize             int32    `protobuf:"varint,2,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	PageToken            string   `protobuf:"bytes,3,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetJobResultsRequest) Reset()         { *m = GetJobResultsRequest{} }
+func (m *GetJobResultsRequest) String() string { return proto.CompactTextString(m) }
+func (*GetJobResultsRequest) ProtoMessage()    {}
+func (*GetJobResultsRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_c181f2f37fbaedca, []int{38}
+}
+
+func (m *GetJobResultsRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetJobResultsRequest.Unmarshal(m, b)
+}
+func (m *GetJobResultsRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetJobResultsRequest.Marshal(b, m, deterministic)
+}
+func (m *GetJobResultsRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetJobResultsRequest.Merge(m, src)
+}
+func (m *GetJobResultsRequest) XXX_Size() int {
+	return xxx_messageInfo_GetJobResultsRequest.Size(m)
+}
+func (m *GetJobResultsRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetJobResultsRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetJobResultsRequest proto.InternalMessageInfo
+
+func (m *GetJobResultsRequest) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *GetJobResultsRequest) GetPageSize() int32 {
+	if m != nil {
+		return m.PageSize
+	}
+	return 0
+}
+
+func (m *GetJobResultsRequest) GetPageToken() string {
+	if m != nil {
+		return m.PageToken
+	}
+	return ""
+}
+
+//Expected Response From GetJobResults
+type GetJobResultsResponse struct {
+	Results              []*JobResult `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
+	NextPageToken        string       `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}     `json:"-"`
+	XXX_unrecognized     []byte       `json:"-"`
+	XXX_sizecache        int32        `json:"-"`
+}
+
+func (m *GetJobResultsResponse) Reset()         { *m = GetJobResultsResponse{} }
+func (m *GetJobResultsResponse) String() string { return proto.CompactTextString(m) }
+func (*GetJobResultsResponse) ProtoMessage()    {}
+func (*GetJobResultsResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_c181f2f37fbaedca, []int{39}
+}
+
+func (m *GetJobResultsResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetJobResultsResponse.Unmarshal(m, b)
+}
+func (m *GetJobResultsResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetJobResultsResponse.Marshal(b, m, deterministic)
+}
+func (m *GetJobResultsResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetJobResultsResponse.Merge(m, src)
+}
+func (m *GetJobResultsResponse) XXX_Size() int {
+	return xxx_messageInfo_GetJobResultsResponse.Size(m)
+}
+func (m *GetJobResultsResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetJobResultsResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetJobResultsResponse proto.InternalMessageInfo
+
+func (m *GetJobResultsResponse) GetResults() []*JobResult {
+	if m != nil {
+		return m.Results
+	}
+	return nil
+}
+
+func (m *GetJobResultsResponse) GetNextPageToken() string {
+	if m != nil {
+		return m.NextPageToken
+	}
+	return ""
+}
+
+//Expected Request For DeleteProduct, an admin RPC
+type DeleteProductRequest struct {
+	Asin                 string   `protobuf:"bytes,1,opt,name=asin,proto3" json:"asin,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *DeleteProductRequest) Reset()         { *m = DeleteProductRequest{} }
+func (m *DeleteProductRequest) String() string { return proto.CompactTextString(m) }
+func (*DeleteProductRequest) ProtoMessage()    {}
+func (*DeleteProductRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_c181f2f37fbaedca, []int{40}
+}
+
+func (m *DeleteProductRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_DeleteProductRequest.Unmarshal(m, b)
+}
+func (m *DeleteProductRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_DeleteProductRequest.Marshal(b, m, deterministic)
+}
+func (m *DeleteProductRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DeleteProductRequest.Merge(m, src)
+}
+func (m *DeleteProductRequest) XXX_Size() int {
+	return xxx_messageInfo_DeleteProductRequest.Size(m)
+}
+func (m *DeleteProductRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_DeleteProductRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DeleteProductRequest proto.InternalMessageInfo
+
+func (m *DeleteProductRequest) GetAsin() string {
+	if m != nil {
+		return m.Asin
+	}
+	return ""
+}
+
+//Expected Response From DeleteProduct
+type DeleteProductResponse struct {
+	Deleted              bool     `protobuf:"varint,1,opt,name=deleted,proto3" json:"deleted,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *DeleteProductResponse) Reset()         { *m = DeleteProductResponse{} }
+func (m *DeleteProductResponse) String() string { return proto.CompactTextString(m) }
+func (*DeleteProductResponse) ProtoMessage()    {}
+func (*DeleteProductResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_c181f2f37fbaedca, []int{41}
+}
+
+func (m *DeleteProductResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_DeleteProductResponse.Unmarshal(m, b)
+}
+func (m *DeleteProductResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_DeleteProductResponse.Marshal(b, m, deterministic)
+}
+func (m *DeleteProductResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DeleteProductResponse.Merge(m, src)
+}
+func (m *DeleteProductResponse) XXX_Size() int {
+	return xxx_messageInfo_DeleteProductResponse.Size(m)
+}
+func (m *DeleteProductResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_DeleteProductResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DeleteProductResponse proto.InternalMessageInfo
+
+func (m *DeleteProductResponse) GetDeleted() bool {
+	if m != nil {
+		return m.Deleted
+	}
+	return false
+}
+
+//Expected Request For InvalidateCache, an admin RPC
+type InvalidateCacheRequest struct {
+	Asins                []string `protobuf:"bytes,1,rep,name=asins,proto3" json:"asins,omitempty"`
+	Pattern              string   `protobuf:"bytes,2,opt,name=pattern,proto3" json:"pattern,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *InvalidateCacheRequest) Reset()         { *m = InvalidateCacheRequest{} }
+func (m *InvalidateCacheRequest) String() string { return proto.CompactTextString(m) }
+func (*InvalidateCacheRequest) ProtoMessage()    {}
+func (*InvalidateCacheRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_c181f2f37fbaedca, []int{42}
+}
+
+func (m *InvalidateCacheRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_InvalidateCacheRequest.Unmarshal(m, b)
+}
+func (m *InvalidateCacheRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_InvalidateCacheRequest.Marshal(b, m, deterministic)
+}
+func (m *InvalidateCacheRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_InvalidateCacheRequest.Merge(m, src)
+}
+func (m *InvalidateCacheRequest) XXX_Size() int {
+	return xxx_messageInfo_InvalidateCacheRequest.Size(m)
+}
+func (m *InvalidateCacheRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_InvalidateCacheRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_InvalidateCacheRequest proto.InternalMessageInfo
+
+func (m *InvalidateCacheRequest) GetAsins() []string {
+	if m != nil {
+		return m.Asins
+	}
+	return nil
+}
+
+func (m *InvalidateCacheRequest) GetPattern() string {
+	if m != nil {
+		return m.Pattern
+	}
+	return ""
+}
+
+//Expected Response From InvalidateCache
+type InvalidateCacheResponse struct {
+	Invalidated          int64    `protobuf:"varint,1,opt,name=invalidated,proto3" json:"invalidated,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *InvalidateCacheResponse) Reset()         { *m = InvalidateCacheResponse{} }
+func (m *InvalidateCacheResponse) String() string { return proto.CompactTextString(m) }
+func (*InvalidateCacheResponse) ProtoMessage()    {}
+func (*InvalidateCacheResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_c181f2f37fbaedca, []int{43}
+}
+
+func (m *InvalidateCacheResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_InvalidateCacheResponse.Unmarshal(m, b)
+}
+func (m *InvalidateCacheResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_InvalidateCacheResponse.Marshal(b, m, deterministic)
+}
+func (m *InvalidateCacheResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_InvalidateCacheResponse.Merge(m, src)
+}
+func (m *InvalidateCacheResponse) XXX_Size() int {
+	return xxx_messageInfo_InvalidateCacheResponse.Size(m)
+}
+func (m *InvalidateCacheResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_InvalidateCacheResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_InvalidateCacheResponse proto.InternalMessageInfo
+
+func (m *InvalidateCacheResponse) GetInvalidated() int64 {
+	if m != nil {
+		return m.Invalidated
+	}
+	return 0
+}
+
+func init() {
+	proto.RegisterEnum("v1.Job_Status", Job_Status_name, Job_Status_value)
+	proto.RegisterType((*Product)(nil), "v1.Product")
+	proto.RegisterType((*ProductCategory)(nil), "v1.ProductCategory")
+	proto.RegisterType((*ProductRank)(nil), "v1.ProductRank")
+	proto.RegisterType((*GetProductRequest)(nil), "v1.GetProductRequest")
+	proto.RegisterType((*GetProductResponse)(nil), "v1.GetProductResponse")
+	proto.RegisterType((*GetProductByCodeRequest)(nil), "v1.GetProductByCodeRequest")
+	proto.RegisterType((*GetProductByCodeResponse)(nil), "v1.GetProductByCodeResponse")
+	proto.RegisterType((*ProductSnapshot)(nil), "v1.ProductSnapshot")
+	proto.RegisterType((*FieldChange)(nil), "v1.FieldChange")
+	proto.RegisterType((*GetProductHistoryRequest)(nil), "v1.GetProductHistoryRequest")
+	proto.RegisterType((*GetProductHistoryResponse)(nil), "v1.GetProductHistoryResponse")
+	proto.RegisterType((*ListProductsRequest)(nil), "v1.ListProductsRequest")
+	proto.RegisterType((*ListProductsResponse)(nil), "v1.ListProductsResponse")
+	proto.RegisterType((*ListTopRankedProductsRequest)(nil), "v1.ListTopRankedProductsRequest")
+	proto.RegisterType((*ListTopRankedProductsResponse)(nil), "v1.ListTopRankedProductsResponse")
+	proto.RegisterType((*ListStaleProductsRequest)(nil), "v1.ListStaleProductsRequest")
+	proto.RegisterType((*ListStaleProductsResponse)(nil), "v1.ListStaleProductsResponse")
+	proto.RegisterType((*SearchStoredProductsRequest)(nil), "v1.SearchStoredProductsRequest")
+	proto.RegisterType((*FieldHighlight)(nil), "v1.FieldHighlight")
+	proto.RegisterType((*SearchHit)(nil), "v1.SearchHit")
+	proto.RegisterType((*CategoryFacet)(nil), "v1.CategoryFacet")
+	proto.RegisterType((*SearchStoredProductsResponse)(nil), "v1.SearchStoredProductsResponse")
+	proto.RegisterType((*RefreshProductRequest)(nil), "v1.RefreshProductRequest")
+	proto.RegisterType((*RefreshProductResponse)(nil), "v1.RefreshProductResponse")
+	proto.RegisterType((*AddToWatchlistRequest)(nil), "v1.AddToWatchlistRequest")
+	proto.RegisterType((*AddToWatchlistResponse)(nil), "v1.AddToWatchlistResponse")
+	proto.RegisterType((*RemoveFromWatchlistRequest)(nil), "v1.RemoveFromWatchlistRequest")
+	proto.RegisterType((*RemoveFromWatchlistResponse)(nil), "v1.RemoveFromWatchlistResponse")
+	proto.RegisterType((*Job)(nil), "v1.Job")
+	proto.RegisterType((*JobResult)(nil), "v1.JobResult")
+	proto.RegisterType((*SubmitScrapeJobRequest)(nil), "v1.SubmitScrapeJobRequest")
+	proto.RegisterType((*SubmitScrapeJobResponse)(nil), "v1.SubmitScrapeJobResponse")
+	proto.RegisterType((*GetJobRequest)(nil), "v1.GetJobRequest")
+	proto.RegisterType((*GetJobResponse)(nil), "v1.GetJobResponse")
+	proto.RegisterType((*ListJobsRequest)(nil), "v1.ListJobsRequest")
+	proto.RegisterType((*ListJobsResponse)(nil), "v1.ListJobsResponse")
+	proto.RegisterType((*CancelJobRequest)(nil), "v1.CancelJobRequest")
+	proto.RegisterType((*CancelJobResponse)(nil), "v1.CancelJobResponse")
+	proto.RegisterType((*GetJobResultsRequest)(nil), "v1.GetJobResultsRequest")
+	proto.RegisterType((*GetJobResultsResponse)(nil), "v1.GetJobResultsResponse")
+	proto.RegisterType((*DeleteProductRequest)(nil), "v1.DeleteProductRequest")
+	proto.RegisterType((*DeleteProductResponse)(nil), "v1.DeleteProductResponse")
+	proto.RegisterType((*InvalidateCacheRequest)(nil), "v1.InvalidateCacheRequest")
+	proto.RegisterType((*InvalidateCacheResponse)(nil), "v1.InvalidateCacheResponse")
+}
+
+func init() { proto.RegisterFile("web-scraper.proto", fileDescriptor_c181f2f37fbaedca) }
+
+var fileDescriptor_c181f2f37fbaedca = []byte{
+	// 2284 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xac, 0x59, 0x4f, 0x73, 0xdb, 0xc6,
+	0x15, 0x0f, 0x49, 0x51, 0x24, 0x1f, 0xf5, 0x77, 0x4d, 0x49, 0x10, 0x28, 0xdb, 0x0c, 0xd2, 0x38,
+	0x8a, 0x52, 0x93, 0x91, 0xec, 0x69, 0x27, 0x72, 0x3b, 0x8d, 0x2c, 0xf9, 0xef, 0xc4, 0x8a, 0x03,
+	0x39, 0xe3, 0x99, 0x4c, 0xa6, 0xea, 0x12, 0x58, 0x92, 0x6b, 0x83, 0x58, 0x06, 0x58, 0xd2, 0x92,
+	0x5d, 0xcd, 0x74, 0x32, 0xfd, 0x04, 0xcd, 0xa5, 0x97, 0x5e, 0xfb, 0x25, 0x7a, 0xe8, 0xb9, 0xe7,
+	0x7e, 0x85, 0x9e, 0x7b, 0xec, 0xb9, 0xb3, 0x8b, 0x05, 0x08, 0x80, 0xa0, 0x28, 0xcf, 0xe4, 0x62,
+	0xeb, 0xfd, 0xc1, 0xfb, 0xbd, 0xf7, 0xf6, 0xed, 0x7b, 0x6f, 0x25, 0x58, 0x7d, 0x43, 0xda, 0xb7,
+	0x7d, 0xcb, 0xc3, 0x03, 0xe2, 0x35, 0x07, 0x1e, 0xe3, 0x0c, 0xe5, 0x47, 0xbb, 0xfa, 0xcd, 0x2e,
+	0x63, 0x5d, 0x87, 0xb4, 0x24, 0xa7, 0x3d, 0xec, 0xb4, 0x38, 0xed, 0x13, 0x9f, 0xe3, 0xfe, 0x20,
+	0x50, 0xd2, 0xb7, 0x94, 0x02, 0x1e, 0xd0, 0x16, 0x76, 0x5d, 0xc6, 0x31, 0xa7, 0xcc, 0xf5, 0x95,
+	0xf4, 0x97, 0xf2, 0x3f, 0xeb, 0x76, 0x97, 0xb8, 0xb7, 0xfd, 0x37, 0xb8, 0xdb, 0x25, 0x5e, 0x8b,
+	0x0d, 0xa4, 0xc6, 0xa4, 0xb6, 0xf1, 0xbf, 0x3c, 0x94, 0x9e, 0x7b, 0xcc, 0x1e, 0x5a, 0x1c, 0x21,
+	0x98, 0xc3, 0x3e, 0x75, 0xb5, 0x5c, 0x23, 0xb7, 0x5d, 0x31, 0xe5, 0xcf, 0x82, 0xe7, 0xe2, 0x3e,
+	0xd1, 0xf2, 0x01, 0x4f, 0xfc, 0x8c, 0xee, 0x00, 0x58, 0x98, 0x93, 0x2e, 0xf3, 0x28, 0xf1, 0xb5,
+	0x42, 0xa3, 0xb0, 0x5d, 0xdd, 0xbb, 0xd6, 0x1c, 0xed, 0x36, 0x95, 0xa1, 0xc3, 0x40, 0x78, 0x6e,
+	0xc6, 0xd4, 0xd0, 0xc7, 0x50, 0xf4, 0xb0, 0xfb, 0xda, 0xd7, 0xe6, 0xa4, 0xfe, 0x72, 0x4c, 0xdf,
+	0xc4, 0xee, 0x6b, 0x33, 0x90, 0xa2, 0x1b, 0x00, 0x36, 0xed, 0x13, 0xd7, 0x17, 0x3e, 0x6a, 0xc5,
+	0x46, 0x61, 0xbb, 0x62, 0xc6, 0x38, 0xe8, 0x0b, 0x00, 0xcb, 0x23, 0x98, 0x13, 0xfb, 0x14, 0x73,
+	0x6d, 0xbe, 0x91, 0xdb, 0xae, 0xee, 0xe9, 0xcd, 0x20, 0x21, 0xcd, 0x30, 0x63, 0xcd, 0x17, 0x61,
+	0xc6, 0xcc, 0x8a, 0xd2, 0x3e, 0xe0, 0xa8, 0x01, 0xd5, 0x3e, 0xf6, 0x5e, 0x13, 0x3e, 0x70, 0xb0,
+	0x45, 0xb4, 0x92, 0x8c, 0x28, 0xce, 0x42, 0x35, 0x28, 0x0e, 0x3c, 0x6a, 0x11, 0xad, 0x2c, 0x65,
+	0x01, 0x81, 0x0c, 0x58, 0xc0, 0x23, 0x4c, 0x1d, 0xdc, 0xa6, 0x0e, 0xe5, 0xe7, 0x5a, 0x45, 0x0a,
+	0x13, 0x3c, 0xf1, 0x65, 0xdb, 0xc3, 0xae, 0xad, 0x41, 0xf0, 0xa5, 0x24, 0x90, 0x0e, 0xe5, 0x0e,
+	0xc1, 0x7c, 0xe8, 0x11, 0x5f, 0xab, 0xca, 0x50, 0x22, 0xda, 0xb8, 0x07, 0xcb, 0xa9, 0x74, 0x45,
+	0xb9, 0xce, 0xc5, 0x72, 0x5d, 0x83, 0xa2, 0x43, 0x46, 0xc4, 0x91, 0x07, 0x50, 0x30, 0x03, 0xc2,
+	0xf8, 0x12, 0xaa, 0xb1, 0xdc, 0xa1, 0x3a, 0x54, 0x44, 0xf6, 0x4e, 0xa9, 0xdb, 0x61, 0xea, 0xeb,
+	0xb2, 0x60, 0x3c, 0x71, 0x3b, 0x6c, 0x8a, 0x85, 0x3f, 0xe7, 0x60, 0xf5, 0x11, 0xe1, 0xa1, 0x15,
+	0xf2, 0xc3, 0x90, 0xf8, 0xd9, 0x15, 0xb0, 0x01, 0xa5, 0x3e, 0x3e, 0x3b, 0xc5, 0x5d, 0xa2, 0x2c,
+	0xcc, 0xf7, 0xf1, 0xd9, 0x41, 0x97, 0xa0, 0x8f, 0x60, 0xb1, 0xc3, 0x3c, 0x8b, 0x9c, 0x7a, 0xa4,
+	0xe3, 0x11, 0xbf, 0xa7, 0x15, 0x1a, 0xb9, 0xed, 0xb2, 0xb9, 0x20, 0x99, 0x66, 0xc0, 0x43, 0xd7,
+	0x45, 0xad, 0x58, 0x3d, 0x72, 0xca, 0x5c, 0xe7, 0x5c, 0x9b, 0x93, 0x1a, 0x15, 0xc9, 0xf9, 0xda,
+	0x75, 0xce, 0x8d, 0x6f, 0x00, 0xc5, 0xbd, 0xf0, 0x07, 0xcc, 0xf5, 0x09, 0xfa, 0x18, 0x4a, 0x83,
+	0x80, 0x25, 0x3d, 0xa9, 0xee, 0x55, 0xe3, 0xd5, 0x12, 0xca, 0x44, 0x64, 0x3e, 0xc7, 0x4e, 0xe0,
+	0x57, 0xd9, 0x0c, 0x08, 0xc3, 0x81, 0x8d, 0xb1, 0xc9, 0xfb, 0xe7, 0x87, 0xcc, 0x26, 0xb1, 0xf0,
+	0x2c, 0x66, 0x47, 0x09, 0x16, 0x3f, 0x8b, 0xdc, 0x89, 0xff, 0x4f, 0xf9, 0xf9, 0x20, 0xac, 0xf2,
+	0xb2, 0x60, 0xbc, 0x38, 0x1f, 0x90, 0x74, 0xc9, 0x14, 0x26, 0x4a, 0xc6, 0x78, 0x09, 0xda, 0x24,
+	0xda, 0xcf, 0x11, 0x86, 0x15, 0xd5, 0xc7, 0x89, 0x8b, 0x07, 0x7e, 0x8f, 0xf1, 0xab, 0xda, 0xfb,
+	0x14, 0x4a, 0x56, 0x0f, 0xbb, 0x5d, 0xe2, 0x6b, 0xf9, 0xf1, 0x5d, 0x7b, 0x48, 0x89, 0x63, 0x1f,
+	0x4a, 0xbe, 0x19, 0xca, 0x8d, 0x6f, 0xa0, 0x1a, 0xe3, 0x0b, 0x4f, 0x3a, 0x82, 0x54, 0x09, 0x0a,
+	0x08, 0xb4, 0x0e, 0xf3, 0x6d, 0xd2, 0x61, 0x1e, 0x91, 0xe6, 0x2a, 0xa6, 0xa2, 0x84, 0x36, 0xee,
+	0x70, 0xe2, 0xc9, 0x0e, 0x50, 0x31, 0x03, 0xc2, 0xf8, 0x7b, 0x2e, 0x9e, 0x91, 0xc7, 0xd4, 0xe7,
+	0xa2, 0x13, 0x5c, 0x52, 0x5f, 0x4d, 0x98, 0xeb, 0x78, 0xac, 0xaf, 0xe5, 0x67, 0xde, 0x65, 0xa9,
+	0x87, 0x76, 0x20, 0xcf, 0x99, 0x56, 0x98, 0xa9, 0x9d, 0xe7, 0x4c, 0x5c, 0x40, 0xea, 0x72, 0xe2,
+	0x8d, 0xb0, 0x23, 0x6b, 0xaf, 0x60, 0x46, 0xb4, 0x71, 0x0c, 0x9b, 0x19, 0x7e, 0xaa, 0xa3, 0xdb,
+	0x85, 0x8a, 0xaf, 0xd2, 0xee, 0x6b, 0xb9, 0x89, 0x0e, 0x17, 0x1e, 0x89, 0x39, 0xd6, 0x32, 0xfe,
+	0x95, 0x87, 0x6b, 0x5f, 0x51, 0x3f, 0xb4, 0xe8, 0x87, 0x31, 0xd7, 0xa1, 0x32, 0xc0, 0x5d, 0x72,
+	0xea, 0xd3, 0xb7, 0x41, 0xe5, 0x15, 0xcd, 0xb2, 0x60, 0x9c, 0xd0, 0xb7, 0x44, 0x5c, 0x0f, 0x29,
+	0xe4, 0xec, 0x35, 0x71, 0x55, 0xf9, 0x49, 0xf5, 0x17, 0x82, 0x21, 0xfc, 0x57, 0x2d, 0xf4, 0x5c,
+	0x15, 0x5f, 0x44, 0xa3, 0x4d, 0x28, 0xf7, 0xa9, 0x7b, 0x2a, 0xee, 0xb9, 0x8a, 0xad, 0xd4, 0xa7,
+	0xae, 0xec, 0x07, 0x42, 0x84, 0xcf, 0x02, 0x51, 0x51, 0x89, 0xf0, 0x99, 0x14, 0xfd, 0x0e, 0x16,
+	0xa3, 0xfe, 0x29, 0x0f, 0x6f, 0x76, 0x0b, 0x5d, 0x08, 0x5b, 0xa8, 0xd0, 0x47, 0x07, 0xb0, 0x14,
+	0x1a, 0x50, 0x55, 0x51, 0x9a, 0x69, 0x21, 0x84, 0xbc, 0x1f, 0x14, 0x4e, 0xea, 0x56, 0x95, 0x27,
+	0x6f, 0x55, 0x17, 0x6a, 0xc9, 0x54, 0xaa, 0x63, 0xf9, 0x04, 0xca, 0xaa, 0xca, 0xc3, 0x53, 0x49,
+	0x5c, 0x81, 0x48, 0x88, 0x6e, 0xc1, 0xb2, 0x4b, 0xce, 0xf8, 0xe9, 0x44, 0x72, 0x17, 0x05, 0xfb,
+	0x79, 0x98, 0x60, 0xe3, 0x39, 0x6c, 0x09, 0xa0, 0x17, 0x6c, 0x20, 0xb2, 0x43, 0xec, 0xf4, 0xe1,
+	0xc5, 0x0f, 0x20, 0x97, 0x3a, 0x00, 0xd1, 0x58, 0x69, 0x9f, 0x72, 0x69, 0xb9, 0x68, 0x06, 0x84,
+	0xf1, 0x18, 0xae, 0x4f, 0xb1, 0xf8, 0x9e, 0x31, 0x18, 0xdf, 0x83, 0x26, 0x2c, 0x9d, 0x88, 0x76,
+	0x90, 0xf6, 0x6b, 0x07, 0x56, 0x5d, 0xc6, 0xc3, 0xce, 0x4b, 0xec, 0xd3, 0x0e, 0xf3, 0xa4, 0x83,
+	0x05, 0x73, 0xd9, 0x65, 0xdc, 0x0c, 0xf9, 0x0f, 0x99, 0x37, 0xc5, 0xcf, 0x23, 0xd8, 0xcc, 0xb0,
+	0xfe, 0xbe, 0x3e, 0x5e, 0x40, 0xfd, 0x84, 0x60, 0xcf, 0xea, 0x9d, 0x70, 0xe6, 0x4d, 0xa6, 0xaf,
+	0x06, 0xc5, 0x1f, 0x86, 0x24, 0xca, 0x5d, 0x40, 0x24, 0x92, 0x9a, 0x9f, 0x96, 0xd4, 0x42, 0xcc,
+	0x59, 0xd1, 0x82, 0x58, 0xa7, 0xe3, 0x13, 0x2e, 0x2b, 0xbd, 0x68, 0x2a, 0xca, 0x38, 0x82, 0x25,
+	0xd9, 0xbf, 0x1e, 0xd3, 0x6e, 0xcf, 0xa1, 0xdd, 0x1e, 0x9f, 0xd2, 0xc2, 0xb6, 0xa0, 0xd2, 0xf1,
+	0x70, 0xb7, 0x4f, 0x5c, 0xee, 0xab, 0x2e, 0x36, 0x66, 0x18, 0x7f, 0x84, 0x4a, 0x10, 0xc4, 0x63,
+	0xca, 0xdf, 0xa7, 0x69, 0x5b, 0x41, 0x4f, 0xcc, 0x6d, 0xe7, 0xcc, 0x80, 0x40, 0x7b, 0x00, 0xbd,
+	0xd0, 0x95, 0x70, 0x33, 0x42, 0x51, 0xf7, 0x8d, 0xbc, 0x34, 0x63, 0x5a, 0xc6, 0x01, 0x2c, 0x86,
+	0x1b, 0xc0, 0x43, 0x6c, 0x91, 0x99, 0x35, 0x67, 0xb1, 0xa1, 0x1b, 0x9d, 0xa5, 0x24, 0x8c, 0x1f,
+	0x73, 0xb0, 0x95, 0x7d, 0x0c, 0xea, 0x3c, 0x3f, 0x84, 0xb9, 0x1e, 0x8d, 0xce, 0x72, 0x51, 0x78,
+	0x14, 0x45, 0x6c, 0x4a, 0x91, 0xb0, 0xcc, 0x19, 0xc7, 0x4e, 0x68, 0x59, 0x12, 0xe8, 0x53, 0x98,
+	0xef, 0x08, 0xa7, 0xc2, 0x60, 0x56, 0xc5, 0xa7, 0x09, 0x77, 0x4d, 0xa5, 0x60, 0x3c, 0x83, 0x35,
+	0x55, 0x76, 0x57, 0x58, 0x2a, 0x52, 0x2d, 0x20, 0x3f, 0xd9, 0x02, 0x7e, 0xca, 0xc1, 0x7a, 0xda,
+	0xde, 0xfb, 0xcd, 0x55, 0x59, 0xc4, 0x64, 0x44, 0xd9, 0xd0, 0xd7, 0xf2, 0x93, 0x7a, 0x91, 0x30,
+	0x3e, 0x30, 0x0b, 0x33, 0x06, 0x26, 0x85, 0xb5, 0x03, 0xdb, 0x7e, 0xc1, 0x5e, 0x62, 0x6e, 0xf5,
+	0x1c, 0xea, 0x5f, 0x1a, 0x64, 0x7c, 0xfa, 0xe4, 0x93, 0xd3, 0xe7, 0x0a, 0x9b, 0xc5, 0x6b, 0x58,
+	0x4f, 0x43, 0xa9, 0xf8, 0xb3, 0xb0, 0x7e, 0x0b, 0x0b, 0xb2, 0xe1, 0x85, 0xbb, 0xd8, 0xec, 0x69,
+	0x5a, 0x15, 0xfa, 0x2a, 0xc3, 0xc6, 0xe7, 0xa0, 0x9b, 0xa4, 0xcf, 0x46, 0xe4, 0xa1, 0xc7, 0xfa,
+	0x57, 0x09, 0xce, 0xf8, 0x35, 0xd4, 0x33, 0xbf, 0x50, 0x3e, 0x6a, 0x50, 0xf2, 0xa4, 0x38, 0xb8,
+	0x89, 0x65, 0x33, 0x24, 0x8d, 0xbf, 0x15, 0xa0, 0xf0, 0x94, 0xb5, 0xd1, 0x12, 0xe4, 0x69, 0x78,
+	0x4d, 0xf3, 0xd4, 0x46, 0xb7, 0x60, 0xde, 0xe7, 0x98, 0xab, 0xc3, 0x5a, 0xda, 0x5b, 0x12, 0x87,
+	0xf0, 0x94, 0xb5, 0x9b, 0x27, 0x92, 0x6b, 0x2a, 0xe9, 0xb8, 0x50, 0x0b, 0xf1, 0x42, 0xdd, 0x82,
+	0x8a, 0x3f, 0xb4, 0x2c, 0x42, 0x6c, 0x62, 0xab, 0x26, 0x31, 0x66, 0x88, 0xfe, 0xd1, 0xc1, 0xd4,
+	0x21, 0xb6, 0x1c, 0x87, 0x45, 0x53, 0x51, 0x93, 0x2b, 0xec, 0x7c, 0xc6, 0x0a, 0x9b, 0x7c, 0x72,
+	0x94, 0xde, 0xe7, 0xc9, 0xf1, 0x05, 0x80, 0xcf, 0xb1, 0xa7, 0x3e, 0x2d, 0xcf, 0xfe, 0x54, 0x69,
+	0x1f, 0x70, 0x74, 0x0f, 0xaa, 0x1d, 0xea, 0x52, 0xd9, 0xdc, 0x31, 0xd7, 0x2a, 0x33, 0xbf, 0x85,
+	0x50, 0xfd, 0x80, 0x1b, 0xfb, 0x30, 0x1f, 0x64, 0x0d, 0x55, 0xa1, 0xf4, 0xfc, 0xc1, 0xf1, 0xd1,
+	0x93, 0xe3, 0x47, 0x2b, 0x1f, 0x08, 0xc2, 0xfc, 0xf6, 0xf8, 0x58, 0x10, 0x39, 0x54, 0x86, 0xb9,
+	0xa3, 0xaf, 0x8f, 0x1f, 0xac, 0xe4, 0xd1, 0x02, 0x94, 0x0f, 0x0f, 0x8e, 0x0f, 0x1f, 0x7c, 0xf5,
+	0xe0, 0x68, 0xa5, 0x60, 0x7c, 0x0f, 0x95, 0xa7, 0xac, 0x6d, 0x12, 0x7f, 0xe8, 0x64, 0x97, 0x75,
+	0xec, 0xfa, 0xe5, 0x2f, 0xef, 0x90, 0xc4, 0xf3, 0x98, 0xa7, 0x6a, 0x3b, 0x20, 0x8c, 0x13, 0x58,
+	0x3f, 0x19, 0xb6, 0xfb, 0x94, 0x9f, 0xc8, 0x77, 0xaf, 0x44, 0x8a, 0x66, 0x85, 0x30, 0x1f, 0x34,
+	0xa9, 0x8a, 0x19, 0x10, 0x93, 0x27, 0x94, 0x9f, 0x3c, 0x21, 0xe3, 0x2e, 0x6c, 0x4c, 0x18, 0x55,
+	0x75, 0xb8, 0x09, 0x85, 0x57, 0xac, 0xad, 0xfa, 0x44, 0x49, 0x95, 0x94, 0x29, 0x78, 0xc6, 0x4d,
+	0x58, 0x7c, 0x44, 0x78, 0xcc, 0x83, 0x54, 0x45, 0x1a, 0x9f, 0xc1, 0x52, 0xa8, 0x30, 0xdb, 0xda,
+	0x33, 0x58, 0x16, 0xf3, 0xf4, 0x29, 0x6b, 0xff, 0x1c, 0x9b, 0x9f, 0xf1, 0x12, 0x56, 0xc6, 0xe6,
+	0x14, 0x7a, 0x1d, 0xe6, 0x5e, 0xb1, 0x76, 0xd8, 0xc5, 0x23, 0x78, 0xc9, 0xbc, 0xf2, 0xc6, 0x63,
+	0xc0, 0xca, 0x21, 0x76, 0x2d, 0xe2, 0x5c, 0x12, 0x78, 0x13, 0x56, 0x63, 0x3a, 0xb3, 0x63, 0x6f,
+	0x43, 0x2d, 0x4a, 0xd4, 0xd0, 0xe1, 0xfe, 0x14, 0xbb, 0xc9, 0x84, 0xe4, 0x2f, 0x4d, 0x48, 0x21,
+	0x9d, 0x90, 0x1e, 0xac, 0xa5, 0x30, 0xa2, 0x5d, 0xa5, 0xe4, 0x05, 0xac, 0xf8, 0x78, 0x8b, 0x14,
+	0xcd, 0x50, 0x7a, 0xe5, 0x0c, 0xed, 0x40, 0xed, 0x88, 0x38, 0x84, 0x93, 0xd9, 0x73, 0xcc, 0xd8,
+	0x85, 0xb5, 0x94, 0xee, 0xb8, 0xff, 0xd9, 0x52, 0x10, 0xf5, 0x3f, 0x45, 0x1a, 0x8f, 0x61, 0xfd,
+	0x89, 0x3b, 0xc2, 0x0e, 0xb5, 0x31, 0x27, 0x87, 0xe2, 0x25, 0x7c, 0xf9, 0x0d, 0xd0, 0xa0, 0x34,
+	0xc0, 0x9c, 0x13, 0x2f, 0x74, 0x37, 0x24, 0x8d, 0x7b, 0xb0, 0x31, 0x61, 0x49, 0xc1, 0x37, 0xa0,
+	0x4a, 0x23, 0x91, 0xad, 0x36, 0xc3, 0x38, 0x6b, 0xef, 0xbf, 0x4b, 0x00, 0x2f, 0x49, 0x3b, 0xb8,
+	0x31, 0x1e, 0x3a, 0x07, 0x18, 0xbf, 0x86, 0xd0, 0x9a, 0x48, 0xe1, 0xc4, 0xaf, 0x07, 0xf4, 0xf5,
+	0x34, 0x3b, 0x40, 0x33, 0x7e, 0xf3, 0xe3, 0xbf, 0xff, 0xf3, 0x53, 0xfe, 0x57, 0xe8, 0x46, 0x6b,
+	0xb4, 0xdb, 0xc2, 0x7d, 0xfc, 0x96, 0xb9, 0x2d, 0xd5, 0x06, 0x5a, 0x22, 0x88, 0xd6, 0x3b, 0xf1,
+	0xef, 0xc5, 0x77, 0x35, 0x84, 0x26, 0x35, 0xd0, 0x10, 0x56, 0xd2, 0x4f, 0x68, 0x54, 0x4f, 0x22,
+	0x25, 0x9e, 0xf1, 0xfa, 0x56, 0xb6, 0x50, 0x39, 0x73, 0x4b, 0x3a, 0xd3, 0xc8, 0x74, 0x46, 0x3c,
+	0xec, 0x5b, 0xef, 0xc4, 0xbf, 0x17, 0xe8, 0x4f, 0x89, 0xdf, 0x80, 0xa8, 0x07, 0x20, 0x4a, 0xd9,
+	0x4e, 0xbe, 0x5f, 0xf5, 0xeb, 0x53, 0xa4, 0x0a, 0xba, 0x29, 0xa1, 0xb7, 0xd1, 0xad, 0xcb, 0xf3,
+	0xd0, 0xea, 0x29, 0xb0, 0xdf, 0xc3, 0x42, 0xfc, 0x99, 0x83, 0x36, 0x84, 0xf9, 0x8c, 0x37, 0xa4,
+	0xae, 0x4d, 0x0a, 0x14, 0x64, 0x5d, 0x42, 0xae, 0xa1, 0x6b, 0x93, 0x90, 0x3e, 0xba, 0x80, 0xb5,
+	0xcc, 0xb7, 0x08, 0x6a, 0x84, 0xf6, 0xa6, 0x3d, 0x7c, 0xf4, 0x0f, 0x2f, 0xd1, 0x50, 0xd0, 0x37,
+	0x25, 0xf4, 0x26, 0xda, 0xc8, 0x80, 0x6e, 0x71, 0x36, 0x40, 0x3e, 0xac, 0x4e, 0x3c, 0x31, 0x82,
+	0x04, 0x4f, 0x7b, 0xd7, 0xe8, 0xd7, 0xa7, 0x48, 0x15, 0xe4, 0x87, 0x12, 0xb2, 0x8e, 0x36, 0xb3,
+	0x20, 0xe5, 0xef, 0x4d, 0xd0, 0x05, 0xd4, 0xb2, 0x56, 0x61, 0x74, 0x73, 0xbc, 0xf4, 0x66, 0xbe,
+	0x55, 0xf4, 0xc6, 0x74, 0x05, 0x85, 0x6e, 0x48, 0xf4, 0x2d, 0xa4, 0x67, 0xa2, 0xcb, 0x2f, 0xd1,
+	0x5b, 0x58, 0x4a, 0x6e, 0xad, 0x68, 0x53, 0xd8, 0xcd, 0xdc, 0x8c, 0x75, 0x3d, 0x4b, 0xa4, 0xc0,
+	0x76, 0x25, 0xd8, 0x67, 0xc6, 0xac, 0x5a, 0x52, 0x73, 0x71, 0x3f, 0xb7, 0x83, 0x7a, 0xb0, 0x94,
+	0xdc, 0x18, 0x03, 0xec, 0xcc, 0x85, 0x55, 0xd7, 0xb3, 0x44, 0xc9, 0x93, 0x35, 0x6a, 0x31, 0xec,
+	0x37, 0xa1, 0x96, 0x40, 0x7a, 0x07, 0xd7, 0x32, 0x96, 0x3f, 0x74, 0x23, 0x88, 0x67, 0xda, 0x1e,
+	0xa9, 0xdf, 0x9c, 0x2a, 0x57, 0xc0, 0x1f, 0x49, 0xe0, 0xeb, 0x3b, 0xf5, 0x2c, 0x60, 0x15, 0x31,
+	0xea, 0xc0, 0x72, 0x6a, 0xda, 0x23, 0x19, 0x4c, 0xf6, 0x5e, 0xa1, 0xd7, 0x33, 0x65, 0x0a, 0x50,
+	0x97, 0x80, 0x35, 0x63, 0x39, 0x06, 0x28, 0xc6, 0xa9, 0x08, 0xf2, 0x19, 0xcc, 0x07, 0x13, 0x07,
+	0xad, 0xaa, 0x6b, 0x1f, 0xb3, 0x8a, 0xe2, 0x2c, 0x65, 0x6c, 0x4b, 0x1a, 0x5b, 0x47, 0xb5, 0x94,
+	0xb1, 0xd6, 0x3b, 0x6a, 0x5f, 0xa0, 0x63, 0x28, 0x87, 0x13, 0x1d, 0x5d, 0x0b, 0xcb, 0x3c, 0xb6,
+	0x2e, 0xe8, 0xb5, 0x24, 0x53, 0x19, 0xdd, 0x90, 0x46, 0x57, 0x51, 0xda, 0x43, 0xf4, 0x07, 0xa8,
+	0x44, 0x43, 0x1a, 0xd5, 0x82, 0x77, 0x59, 0x72, 0xae, 0xeb, 0x6b, 0x29, 0x6e, 0xb2, 0x43, 0x1a,
+	0xf5, 0x2c, 0x3f, 0x5b, 0x96, 0xd4, 0x17, 0x09, 0x78, 0x35, 0x5e, 0x90, 0x82, 0x09, 0xaa, 0x25,
+	0x82, 0x8e, 0x4d, 0x7a, 0x7d, 0x33, 0x43, 0xa2, 0xd0, 0x7e, 0x21, 0xd1, 0x6e, 0xa0, 0xad, 0x4c,
+	0xb4, 0x70, 0x38, 0x3b, 0xb0, 0x98, 0x18, 0xa4, 0x01, 0x56, 0xd6, 0x1c, 0xd6, 0x37, 0x33, 0x24,
+	0xc9, 0xc8, 0x76, 0x66, 0x0c, 0x22, 0xe4, 0xc1, 0x72, 0x6a, 0x72, 0x06, 0x25, 0x94, 0x3d, 0x98,
+	0xf5, 0x7a, 0xa6, 0xec, 0x92, 0x6c, 0xca, 0x5f, 0x70, 0xb7, 0xc6, 0xe3, 0x76, 0x3f, 0xb7, 0x73,
+	0xff, 0x9f, 0xb9, 0xbf, 0x1c, 0xfc, 0x23, 0x87, 0xbe, 0x85, 0xea, 0x4b, 0xd2, 0x6e, 0xa8, 0xb9,
+	0x6b, 0x1c, 0xc0, 0xbc, 0x39, 0xa4, 0x8d, 0x63, 0x8a, 0x3e, 0xe9, 0x71, 0x3e, 0xf0, 0xf7, 0x5b,
+	0xad, 0x2e, 0xe5, 0xbd, 0x61, 0xbb, 0x69, 0xb1, 0x7e, 0xcb, 0x73, 0xa9, 0x4d, 0x46, 0xad, 0x2e,
+	0xbb, 0xfd, 0x86, 0xb4, 0xd5, 0x5f, 0x8a, 0xf4, 0x25, 0x6f, 0x48, 0xbf, 0xb4, 0xc9, 0xc8, 0x73,
+	0xa9, 0x50, 0xda, 0x2b, 0xec, 0x36, 0x3f, 0xdf, 0xce, 0xed, 0xad, 0xe0, 0xc1, 0xc0, 0xa1, 0x96,
+	0xfc, 0xeb, 0x4e, 0xeb, 0x95, 0xcf, 0xdc, 0xfd, 0x09, 0x8e, 0xb9, 0x0f, 0x85, 0xbb, 0x9f, 0xdf,
+	0x45, 0x77, 0x60, 0xc7, 0x24, 0x7c, 0xe8, 0xb9, 0xc4, 0x6e, 0xbc, 0xe9, 0x11, 0xb7, 0xc1, 0x7b,
+	0xa4, 0xe1, 0x11, 0x9f, 0x0d, 0x3d, 0x8b, 0x34, 0x6c, 0x46, 0xfc, 0x86, 0xcb, 0x78, 0x83, 0x9c,
+	0x51, 0x9f, 0x37, 0x51, 0x11, 0x0a, 0x7f, 0xcd, 0x97, 0xbe, 0xfb, 0xa0, 0x3d, 0x2f, 0x5f, 0x1d,
+	0x77, 0xfe, 0x3f, 0x00, 0xd3, 0x8d, 0x1a, 0x4f, 0xb8, 0x1a, 0x00, 0x00,
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// WebScraperClient is the client API for WebScraper service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type WebScraperClient interface {
+	//This end point takes Amazon Product ASIN, and returns name, brand, features, categories, ranks, dimensions, price and availability
+	GetProduct(ctx context.Context, in *GetProductRequest, opts ...grpc.CallOption) (*GetProductResponse, error)
+	//This end point takes a UPC, EAN or GTIN, resolves it to an ASIN, and returns the product like GetProduct
+	GetProductByCode(ctx context.Context, in *GetProductByCodeRequest, opts ...grpc.CallOption) (*GetProductByCodeResponse, error)
+	//This end point returns the stored snapshots of a product and what changed between them
+	GetProductHistory(ctx context.Context, in *GetProductHistoryRequest, opts ...grpc.CallOption) (*GetProductHistoryResponse, error)
+	//This end point lists stored products, filtered by category, rank, scrape time and marketplace
+	ListProducts(ctx context.Context, in *ListProductsRequest, opts ...grpc.CallOption) (*ListProductsResponse, error)
+	//This end point returns the best ranked stored products in a category
+	ListTopRankedProducts(ctx context.Context, in *ListTopRankedProductsRequest, opts ...grpc.CallOption) (*ListTopRankedProductsResponse, error)
+	//This end point returns stored products that weren't scraped again for a while
+	ListStaleProducts(ctx context.Context, in *ListStaleProductsRequest, opts ...grpc.CallOption) (*ListStaleProductsResponse, error)
+	//This end point searches stored products by keywords in their name, brand, features and categories
+	SearchStoredProducts(ctx context.Context, in *SearchStoredProductsRequest, opts ...grpc.CallOption) (*SearchStoredProductsResponse, error)
+	//This end point scrapes a product again regardless of its cache, and returns what changed
+	RefreshProduct(ctx context.Context, in *RefreshProductRequest, opts ...grpc.CallOption) (*RefreshProductResponse, error)
+	//This end point refreshes a product in the background every interval
+	AddToWatchlist(ctx context.Context, in *AddToWatchlistRequest, opts ...grpc.CallOption) (*AddToWatchlistResponse, error)
+	//This end point stops refreshing a product in the background
+	RemoveFromWatchlist(ctx context.Context, in *RemoveFromWatchlistRequest, opts ...grpc.CallOption) (*RemoveFromWatchlistResponse, error)
+	//This end point scrapes a list of products in the background, and returns the job right away
+	SubmitScrapeJob(ctx context.Context, in *SubmitScrapeJobRequest, opts ...grpc.CallOption) (*SubmitScrapeJobResponse, error)
+	//This end point returns the progress of a job
+	GetJob(ctx context.Context, in *GetJobRequest, opts ...grpc.CallOption) (*GetJobResponse, error)
+	//This end point lists jobs, newest first
+	ListJobs(ctx context.Context, in *ListJobsRequest, opts ...grpc.CallOption) (*ListJobsResponse, error)
+	//This end point stops a job, products already scraped keep their results
+	CancelJob(ctx context.Context, in *CancelJobRequest, opts ...grpc.CallOption) (*CancelJobResponse, error)
+	//This end point returns the products and errors of a job
+	GetJobResults(ctx context.Context, in *GetJobResultsRequest, opts ...grpc.CallOption) (*GetJobResultsResponse, error)
+	//This end point deletes a stored product with its cache, indexes and history, it needs an admin token
+	DeleteProduct(ctx context.Context, in *DeleteProductRequest, opts ...grpc.CallOption) (*DeleteProductResponse, error)
+	//This end point drops cached products so they are scraped again, it needs an admin token
+	InvalidateCache(ctx context.Context, in *InvalidateCacheRequest, opts ...grpc.CallOption) (*InvalidateCacheResponse, error)
+}
+
+type webScraperClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewWebScraperClient(cc *grpc.ClientConn) WebScraperClient {
+	return &webScraperClient{cc}
+}
+
+func (c *webScraperClient) GetProduct(ctx context.Context, in *GetProductRequest, opts ...grpc.CallOption) (*GetProductResponse, error) {
+	out := new(GetProductResponse)
+	err := c.cc.Invoke(ctx, "/v1.WebScraper/GetProduct", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *webScraperClient) GetProductByCode(ctx context.Context, in *GetProductByCodeRequest, opts ...grpc.CallOption) (*GetProductByCodeResponse, error) {
+	out := new(GetProductByCodeResponse)
+	err := c.cc.Invoke(ctx, "/v1.WebScraper/GetProductByCode", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *webScraperClient) GetProductHistory(ctx context.Context, in *GetProductHistoryRequest, opts ...grpc.CallOption) (*GetProductHistoryResponse, error) {
+	out := new(GetProductHistoryResponse)
+	err := c.cc.Invoke(ctx, "/v1.WebScraper/GetProductHistory", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *webScraperClient) ListProducts(ctx context.Context, in *ListProductsRequest, opts ...grpc.CallOption) (*ListProductsResponse, error) {
+	out := new(ListProductsResponse)
+	err := c.cc.Invoke(ctx, "/v1.WebScraper/ListProducts", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *webScraperClient) ListTopRankedProducts(ctx context.Context, in *ListTopRankedProductsRequest, opts ...grpc.CallOption) (*ListTopRankedProductsResponse, error) {
+	out := new(ListTopRankedProductsResponse)
+	err := c.cc.Invoke(ctx, "/v1.WebScraper/ListTopRankedProducts", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *webScraperClient) ListStaleProducts(ctx context.Context, in *ListStaleProductsRequest, opts ...grpc.CallOption) (*ListStaleProductsResponse, error) {
+	out := new(ListStaleProductsResponse)
+	err := c.cc.Invoke(ctx, "/v1.WebScraper/ListStaleProducts", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *webScraperClient) SearchStoredProducts(ctx context.Context, in *SearchStoredProductsRequest, opts ...grpc.CallOption) (*SearchStoredProductsResponse, error) {
+	out := new(SearchStoredProductsResponse)
+	err := c.cc.Invoke(ctx, "/v1.WebScraper/SearchStoredProducts", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *webScraperClient) RefreshProduct(ctx context.Context, in *RefreshProductRequest, opts ...grpc.CallOption) (*RefreshProductResponse, error) {
+	out := new(RefreshProductResponse)
+	err := c.cc.Invoke(ctx, "/v1.WebScraper/RefreshProduct", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *webScraperClient) AddToWatchlist(ctx context.Context, in *AddToWatchlistRequest, opts ...grpc.CallOption) (*AddToWatchlistResponse, error) {
+	out := new(AddToWatchlistResponse)
+	err := c.cc.Invoke(ctx, "/v1.WebScraper/AddToWatchlist", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *webScraperClient) RemoveFromWatchlist(ctx context.Context, in *RemoveFromWatchlistRequest, opts ...grpc.CallOption) (*RemoveFromWatchlistResponse, error) {
+	out := new(RemoveFromWatchlistResponse)
+	err := c.cc.Invoke(ctx, "/v1.WebScraper/RemoveFromWatchlist", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *webScraperClient) SubmitScrapeJob(ctx context.Context, in *SubmitScrapeJobRequest, opts ...grpc.CallOption) (*SubmitScrapeJobResponse, error) {
+	out := new(SubmitScrapeJobResponse)
+	err := c.cc.Invoke(ctx, "/v1.WebScraper/SubmitScrapeJob", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *webScraperClient) GetJob(ctx context.Context, in *GetJobRequest, opts ...grpc.CallOption) (*GetJobResponse, error) {
+	out := new(GetJobResponse)
+	err := c.cc.Invoke(ctx, "/v1.WebScraper/GetJob", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *webScraperClient) ListJobs(ctx context.Context, in *ListJobsRequest, opts ...grpc.CallOption) (*ListJobsResponse, error) {
+	out := new(ListJobsResponse)
+	err := c.cc.Invoke(ctx, "/v1.WebScraper/ListJobs", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *webScraperClient) CancelJob(ctx context.Context, in *CancelJobRequest, opts ...grpc.CallOption) (*CancelJobResponse, error) {
+	out := new(CancelJobResponse)
+	err := c.cc.Invoke(ctx, "/v1.WebScraper/CancelJob", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *webScraperClient) GetJobResults(ctx context.Context, in *GetJobResultsRequest, opts ...grpc.CallOption) (*GetJobResultsResponse, error) {
+	out := new(GetJobResultsResponse)
+	err := c.cc.Invoke(ctx, "/v1.WebScraper/GetJobResults", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *webScraperClient) DeleteProduct(ctx context.Context, in *DeleteProductRequest, opts ...grpc.CallOption) (*DeleteProductResponse, error) {
+	out := new(DeleteProductResponse)
+	err := c.cc.Invoke(ctx, "/v1.WebScraper/DeleteProduct", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *webScraperClient) InvalidateCache(ctx context.Context, in *InvalidateCacheRequest, opts ...grpc.CallOption) (*InvalidateCacheResponse, error) {
+	out := new(InvalidateCacheResponse)
+	err := c.cc.Invoke(ctx, "/v1.WebScraper/InvalidateCache", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// WebScraperServer is the server API for WebScraper service.
+type WebScraperServer interface {
+	//This end point takes Amazon Product ASIN, and returns name, brand, features, categories, ranks, dimensions, price and availability
+	GetProduct(context.Context, *GetProductRequest) (*GetProductResponse, error)
+	//This end point takes a UPC, EAN or GTIN, resolves it to an ASIN, and returns the product like GetProduct
+	GetProductByCode(context.Context, *GetProductByCodeRequest) (*GetProductByCodeResponse, error)
+	//This end point returns the stored snapshots of a product and what changed between them
+	GetProductHistory(context.Context, *GetProductHistoryRequest) (*GetProductHistoryResponse, error)
+	//This end point lists stored products, filtered by category, rank, scrape time and marketplace
+	ListProducts(context.Context, *ListProductsRequest) (*ListProductsResponse, error)
+	//This end point returns the best ranked stored products in a category
+	ListTopRankedProducts(context.Context, *ListTopRankedProductsRequest) (*ListTopRankedProductsResponse, error)
+	//This end point returns stored products that weren't scraped again for a while
+	ListStaleProducts(context.Context, *ListStaleProductsRequest) (*ListStaleProductsResponse, error)
+	//This end point searches stored products by keywords in their name, brand, features and categories
+	SearchStoredProducts(context.Context, *SearchStoredProductsRequest) (*SearchStoredProductsResponse, error)
+	//This end point scrapes a product again regardless of its cache, and returns what changed
+	RefreshProduct(context.Context, *RefreshProductRequest) (*RefreshProductResponse, error)
+	//This end point refreshes a product in the background every interval
+	AddToWatchlist(context.Context, *AddToWatchlistRequest) (*AddToWatchlistResponse, error)
+	//This end point stops refreshing a product in the background
+	RemoveFromWatchlist(context.Context, *RemoveFromWatchlistRequest) (*RemoveFromWatchlistResponse, error)
+	//This end point scrapes a list of products in the background, and returns the job right away
+	SubmitScrapeJob(context.Context, *SubmitScrapeJobRequest) (*SubmitScrapeJobResponse, error)
+	//This end point returns the progress of a job
+	GetJob(context.Context, *GetJobRequest) (*GetJobResponse, error)
+	//This end point lists jobs, newest first
+	ListJobs(context.Context, *ListJobsRequest) (*ListJobsResponse, error)
+	//This end point stops a job, products already scraped keep their results
+	CancelJob(context.Context, *CancelJobRequest) (*CancelJobResponse, error)
+	//This end point returns the products and errors of a job
+	GetJobResults(context.Context, *GetJobResultsRequest) (*GetJobResultsResponse, error)
+	//This end point deletes a stored product with its cache, indexes and history, it needs an admin token
+	DeleteProduct(context.Context, *DeleteProductRequest) (*DeleteProductResponse, error)
+	//This end point drops cached products so they are scraped again, it needs an admin token
+	InvalidateCache(context.Context, *InvalidateCacheRequest) (*InvalidateCacheResponse, error)
+}
+
+func RegisterWebScraperServer(s *grpc.Server, srv WebScraperServer) {
+	s.RegisterService(&_WebScraper_serviceDesc, srv)
+}
+
+func _WebScraper_GetProduct_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetProductRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WebScraperServer).GetProduct(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/v1.WebScraper/GetProduct",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WebScraperServer).GetProduct(ctx, req.(*GetProductRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WebScraper_GetProductByCode_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetProductByCodeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WebScraperServer).GetProductByCode(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/v1.WebScraper/GetProductByCode",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WebScraperServer).GetProductByCode(ctx, req.(*GetProductByCodeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WebScraper_GetProductHistory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetProductHistoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WebScraperServer).GetProductHistory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/v1.WebScraper/GetProductHistory",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WebScraperServer).GetProductHistory(ctx, req.(*GetProductHistoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WebScraper_ListProducts_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListProductsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WebScraperServer).ListProducts(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/v1.WebScraper/ListProducts",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WebScraperServer).ListProducts(ctx, req.(*ListProductsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WebScraper_ListTopRankedProducts_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListTopRankedProductsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WebScraperServer).ListTopRankedProducts(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/v1.WebScraper/ListTopRankedProducts",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WebScraperServer).ListTopRankedProducts(ctx, req.(*ListTopRankedProductsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WebScraper_ListStaleProducts_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListStaleProductsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WebScraperServer).ListStaleProducts(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/v1.WebScraper/ListStaleProducts",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WebScraperServer).ListStaleProducts(ctx, req.(*ListStaleProductsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WebScraper_SearchStoredProducts_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SearchStoredProductsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WebScraperServer).SearchStoredProducts(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/v1.WebScraper/SearchStoredProducts",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WebScraperServer).SearchStoredProducts(ctx, req.(*SearchStoredProductsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WebScraper_RefreshProduct_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RefreshProductRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WebScraperServer).RefreshProduct(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/v1.WebScraper/RefreshProduct",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WebScraperServer).RefreshProduct(ctx, req.(*RefreshProductRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WebScraper_AddToWatchlist_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddToWatchlistRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WebScraperServer).AddToWatchlist(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/v1.WebScraper/AddToWatchlist",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WebScraperServer).AddToWatchlist(ctx, req.(*AddToWatchlistRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WebScraper_RemoveFromWatchlist_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RemoveFromWatchlistRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WebScraperServer).RemoveFromWatchlist(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/v1.WebScraper/RemoveFromWatchlist",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WebScraperServer).RemoveFromWatchlist(ctx, req.(*RemoveFromWatchlistRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WebScraper_SubmitScrapeJob_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SubmitScrapeJobRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WebScraperServer).SubmitScrapeJob(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/v1.WebScraper/SubmitScrapeJob",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WebScraperServer).SubmitScrapeJob(ctx, req.(*SubmitScrapeJobRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WebScraper_GetJob_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetJobRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WebScraperServer).GetJob(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/v1.WebScraper/GetJob",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WebScraperServer).GetJob(ctx, req.(*GetJobRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WebScraper_ListJobs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListJobsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WebScraperServer).ListJobs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/v1.WebScraper/ListJobs",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WebScraperServer).ListJobs(ctx, req.(*ListJobsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WebScraper_CancelJob_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CancelJobRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WebScraperServer).CancelJob(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/v1.WebScraper/CancelJob",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WebScraperServer).CancelJob(ctx, req.(*CancelJobRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WebScraper_GetJobResults_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetJobResultsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WebScraperServer).GetJobResults(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/v1.WebScraper/GetJobResults",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WebScraperServer).GetJobResults(ctx, req.(*GetJobResultsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WebScraper_DeleteProduct_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteProductRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WebScraperServer).DeleteProduct(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/v1.WebScraper/DeleteProduct",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WebScraperServer).DeleteProduct(ctx, req.(*DeleteProductRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WebScraper_InvalidateCache_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(InvalidateCacheRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WebScraperServer).InvalidateCache(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/v1.WebScraper/InvalidateCache",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WebScraperServer).InvalidateCache(ctx, req.(*InvalidateCacheRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _WebScraper_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "v1.WebScraper",
+	HandlerType: (*WebScraperServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetProduct",
+			Handler:    _WebScraper_GetProduct_Handler,
+		},
+		{
+			MethodName: "GetProductByCode",
+			Handler:    _WebScraper_GetProductByCode_Handler,
+		},
+		{
+			MethodName: "GetProductHistory",
+			Handler:    _WebScraper_GetProductHistory_Handler,
+		},
+		{
+			MethodName: "ListProducts",
+			Handler:    _WebScraper_ListProducts_Handler,
+		},
+		{
+			MethodName: "ListTopRankedProducts",
+			Handler:    _WebScraper_ListTopRankedProducts_Handler,
+		},
+		{
+			MethodName: "ListStaleProducts",
+			Handler:    _WebScraper_ListStaleProducts_Handler,
+		},
+		{
+			MethodName: "SearchStoredProducts",
+			Handler:    _WebScraper_SearchStoredProducts_Handler,
+		},
+		{
+			MethodName: "RefreshProduct",
+			Handler:    _WebScraper_RefreshProduct_Handler,
+		},
+		{
+			MethodName: "AddToWatchlist",
+			Handler:    _WebScraper_AddToWatchlist_Handler,
+		},
+		{
+			MethodName: "RemoveFromWatchlist",
+			Handler:    _WebScraper_RemoveFromWatchlist_Handler,
+		},
+		{
+			MethodName: "SubmitScrapeJob",
+			Handler:    _WebScraper_SubmitScrapeJob_Handler,
+		},
+		{
+			MethodName: "GetJob",
+			Handler:    _WebScraper_GetJob_Handler,
+		},
+		{
+			MethodName: "ListJobs",
+			Handler:    _WebScraper_ListJobs_Handler,
+		},
+		{
+			MethodName: "CancelJob",
+			Handler:    _WebScraper_CancelJob_Handler,
+		},
+		{
+			MethodName: "GetJobResults",
+			Handler:    _WebScraper_GetJobResults_Handler,
+		},
+		{
+			MethodName: "DeleteProduct",
+			Handler:    _WebScraper_DeleteProduct_Handler,
+		},
+		{
+			MethodName: "InvalidateCache",
+			Handler:    _WebScraper_InvalidateCache_Handler,
 		},
 	},
 	Streams:  []grpc.StreamDesc{},
@@ -6,6 +6,9 @@ import (
 	"time"
 
 	"github.com/go-redis/redis"
+	//SQL drivers for SQLDriver
+	_ "github.com/lib/pq"
+	_ "github.com/mattn/go-sqlite3"
 	"github.com/rnidev/go-webscraper/pkg/logger"
 	"github.com/rnidev/go-webscraper/pkg/protocol/grpc"
 	"github.com/rnidev/go-webscraper/pkg/protocol/rest"
@@ -13,12 +16,120 @@ import (
 	"go.uber.org/zap"
 )
 
+const (
+	//StorageRedis stores and caches products in Redis, shared by every replica
+	StorageRedis = "redis"
+	//StorageMemory stores and caches products in process, for running without Redis
+	StorageMemory = "memory"
+	//StorageBolt stores and caches products in a BoltDB file, for single node deployments
+	StorageBolt = "bolt"
+
+	//ModeAll serves the API and scrapes in the same process
+	ModeAll = "all"
+	//ModeAPI serves the API and queues scrapes for workers in Redis
+	ModeAPI = "api"
+	//ModeWorker only scrapes products queued by API replicas
+	ModeWorker = "worker"
+)
+
 // Config is configuration for Server
 type Config struct {
+	//Mode is the role of this process, "all", "api" or "worker",
+	//api and worker replicas share a scrape queue and need Redis storage
+	Mode          string
 	GRPCPort      string
 	RESTPort      string
 	RedisHost     string
 	RedisPassword string
+	//Storage is where products are stored and cached, "redis", "memory" or "bolt"
+	Storage string
+	//MigrateProducts rewrites and indexes product hashes stored with an older encoding before serving
+	MigrateProducts bool
+	//MemoryCapacity is how many products and cache entries memory storage keeps, 0 keeps everything
+	MemoryCapacity int
+	//BoltPath is the BoltDB file bolt storage keeps products in
+	BoltPath string
+	//SQLDriver stores products in SQL instead, postgres or sqlite3,
+	//while Storage still caches them. Empty disables it
+	SQLDriver string
+	//SQLDSN is the data source name of the SQL database
+	SQLDSN string
+
+	//ScrapeMaxAttempts is how many times a failed scrape is tried in total
+	ScrapeMaxAttempts int
+	//ScrapeBaseBackoff is the wait before the first retry, doubled on every retry after
+	ScrapeBaseBackoff time.Duration
+	//ScrapeMaxBackoff caps the wait between retries
+	ScrapeMaxBackoff time.Duration
+	//ScrapeJitter is the fraction (0 to 1) of each wait that is randomized
+	ScrapeJitter float64
+	//ScrapeRetryStatusCodes are HTTP status codes that trigger a retry
+	ScrapeRetryStatusCodes []int
+
+	//ScrapeConcurrency is how many requests may be in flight per Amazon domain across all scrapes
+	ScrapeConcurrency int
+	//ScrapeInterval is the minimum time between two requests per Amazon domain across all scrapes
+	ScrapeInterval time.Duration
+	//ScrapeMaxQueue is how many scrapes may wait per domain before requests get ResourceExhausted
+	ScrapeMaxQueue int
+
+	//GlobalScrapeRate is how many requests per second all replicas together may send
+	//per Amazon domain, coordinated through Redis, 0 disables it
+	GlobalScrapeRate float64
+	//GlobalScrapeBurst is how many requests all replicas may send at once per domain
+	GlobalScrapeBurst int
+
+	//MaxStaleness is how old a stored product may be to be served while it is refreshed
+	//after its cache expired, 0 makes callers wait for a fresh scrape
+	MaxStaleness time.Duration
+	//NotFoundTTL is how long an ASIN without a product page answers NotFound
+	//without being scraped again, 0 disables it
+	NotFoundTTL time.Duration
+	//MaxRobotChecks is how many robot check pages an ASIN may get within
+	//RobotCheckWindow before it isn't scraped for the rest of the window, 0 disables it
+	MaxRobotChecks   int
+	RobotCheckWindow time.Duration
+	//HistoryMaxAge drops product snapshots older than it, 0 keeps them forever
+	HistoryMaxAge time.Duration
+	//HistoryMaxSnapshots is how many snapshots are kept per product, 0 keeps all
+	HistoryMaxSnapshots int
+	//CacheTTL is how long a scraped product is cached
+	CacheTTL time.Duration
+	//AdaptiveTTL lengthens the cache TTL of products that didn't change
+	//for AdaptiveTTLAfter refreshes and shortens it for products that did,
+	//within MinCacheTTL and MaxCacheTTL
+	AdaptiveTTL      bool
+	AdaptiveTTLAfter int
+	MinCacheTTL      time.Duration
+	MaxCacheTTL      time.Duration
+	//Search indexes stored products in RediSearch for keyword search, it needs Redis with the module
+	Search bool
+	//SearchReindexInterval indexes every stored product again this often, for products stored
+	//without going through a replica. 0 only indexes on scrapes, and every product when the index is created
+	SearchReindexInterval time.Duration
+	//WatchlistRate is how many watched products per second all replicas refresh at most,
+	//0 stops refreshing them
+	WatchlistRate float64
+	//JobWorkers is how many scrape jobs this replica runs at once, 0 leaves them to other replicas
+	JobWorkers int
+	//QueueConcurrency is how many queued scrapes a worker runs at once
+	QueueConcurrency int
+	//QueueMaxDeliveries is how often a queued scrape is tried before it is dead-lettered
+	QueueMaxDeliveries int
+	//QueueRetryAfter is how long a failed queued scrape waits before it is tried again
+	QueueRetryAfter time.Duration
+	//QueueConsumer names a worker in the queue's consumer group, it must be unique among workers
+	QueueConsumer string
+	//AdminTokens are bearer tokens allowed to delete products and invalidate the cache,
+	//empty disables those RPCs
+	AdminTokens []string
+
+	//ProxyURLs are HTTP or SOCKS5 proxies scrapes rotate through, empty disables proxies
+	ProxyURLs []string
+	//ProxyCooldown is how long a proxy rests after being blocked
+	ProxyCooldown time.Duration
+	//ProxyMinInterval is the minimum time between two requests through the same proxy
+	ProxyMinInterval time.Duration
 }
 
 // StartServer runs gRPC server and REST gateway
@@ -29,21 +140,182 @@ func StartServer(cfg *Config) error {
 		return fmt.Errorf("failed to start logger: %v", err)
 	}
 
-	client := redis.NewClient(&redis.Options{
-		Addr:         cfg.RedisHost,
-		Password:     cfg.RedisPassword,
-		DialTimeout:  10 * time.Second,
-		ReadTimeout:  30 * time.Second,
-		WriteTimeout: 30 * time.Second,
-		PoolSize:     10,
-		PoolTimeout:  30 * time.Second,
-	}).WithContext(ctx)
-	err := client.Ping().Err()
-	if err != nil {
-		logger.Log.Warn("Redis server is not available", zap.String("error:", err.Error()))
+	var (
+		client    *redis.Client
+		boltStore *v1.BoltStore
+		err       error
+	)
+	switch cfg.Storage {
+	case "", StorageRedis:
+		client = redis.NewClient(&redis.Options{
+			Addr:         cfg.RedisHost,
+			Password:     cfg.RedisPassword,
+			DialTimeout:  10 * time.Second,
+			ReadTimeout:  30 * time.Second,
+			WriteTimeout: 30 * time.Second,
+			PoolSize:     10,
+			PoolTimeout:  30 * time.Second,
+		}).WithContext(ctx)
+		//every request needs Redis, so don't start without it
+		if err = client.Ping().Err(); err != nil {
+			return fmt.Errorf("redis server is not available, use -storage=%s to run without it: %v", StorageMemory, err)
+		}
+		if cfg.MigrateProducts {
+			migrated, err := v1.MigrateProducts(client)
+			if err != nil {
+				return fmt.Errorf("failed to migrate products: %v", err)
+			}
+			logger.Log.Info("migrated products", zap.Int("products:", migrated))
+		}
+	case StorageMemory:
+		logger.Log.Info("storing products in memory", zap.Int("capacity:", cfg.MemoryCapacity))
+	case StorageBolt:
+		boltStore, err = v1.NewBoltStore(cfg.BoltPath)
+		if err != nil {
+			return fmt.Errorf("failed to open %s: %v", cfg.BoltPath, err)
+		}
+		defer boltStore.Close()
+		logger.Log.Info("storing products in BoltDB", zap.String("path:", cfg.BoltPath))
+	default:
+		return fmt.Errorf("unknown storage %q, expected %s, %s or %s", cfg.Storage, StorageRedis, StorageMemory, StorageBolt)
+	}
+	switch cfg.Mode {
+	case "", ModeAll:
+	case ModeAPI, ModeWorker:
+		if client == nil {
+			return fmt.Errorf("-mode=%s shares a scrape queue in Redis, use -storage=%s", cfg.Mode, StorageRedis)
+		}
+	default:
+		return fmt.Errorf("unknown mode %q, expected %s, %s or %s", cfg.Mode, ModeAll, ModeAPI, ModeWorker)
 	}
 
-	v1API := v1.NewScraperServer(client)
+	scraper := v1.NewScraper(v1.RetryPolicy{
+		MaxAttempts:          cfg.ScrapeMaxAttempts,
+		BaseBackoff:          cfg.ScrapeBaseBackoff,
+		MaxBackoff:           cfg.ScrapeMaxBackoff,
+		Jitter:               cfg.ScrapeJitter,
+		RetryableStatusCodes: cfg.ScrapeRetryStatusCodes,
+		RetryRobotCheck:      true,
+	}, v1.NewScrapeScheduler(cfg.ScrapeConcurrency, cfg.ScrapeInterval, cfg.ScrapeMaxQueue))
+	if cfg.GlobalScrapeRate > 0 && client == nil {
+		logger.Log.Warn("global scrape rate needs Redis storage, only the local scheduler limits scrapes")
+	} else if cfg.GlobalScrapeRate > 0 {
+		//same client as the scraper server, so every replica on this Redis shares the budget
+		scraper.Limiter = v1.NewRedisRateLimiter(client, cfg.GlobalScrapeRate, cfg.GlobalScrapeBurst)
+	}
+	if len(cfg.ProxyURLs) > 0 {
+		scraper.Proxies, err = v1.NewProxyPool(cfg.ProxyURLs, cfg.ProxyCooldown, cfg.ProxyMinInterval)
+		if err != nil {
+			return fmt.Errorf("failed to set up proxies: %v", err)
+		}
+		logger.Log.Info("scraping through proxies", zap.Int("proxies:", len(cfg.ProxyURLs)))
+	}
+	opts := v1.ServerOptions{
+		MaxStaleness:     cfg.MaxStaleness,
+		NotFoundTTL:      cfg.NotFoundTTL,
+		MaxRobotChecks:   cfg.MaxRobotChecks,
+		RobotCheckWindow: cfg.RobotCheckWindow,
+		History: v1.HistoryRetention{
+			MaxAge:       cfg.HistoryMaxAge,
+			MaxSnapshots: cfg.HistoryMaxSnapshots,
+		},
+		TTL: v1.TTLPolicy{
+			Default:    cfg.CacheTTL,
+			Adaptive:   cfg.AdaptiveTTL,
+			AdaptAfter: cfg.AdaptiveTTLAfter,
+			MinTTL:     cfg.MinCacheTTL,
+			MaxTTL:     cfg.MaxCacheTTL,
+		},
+		AdminTokens: cfg.AdminTokens,
+	}
+	//without Redis there is a single process, the single flight coalesces scrapes without a lock
+	var (
+		store  v1.ProductStore
+		cache  v1.ProductCache
+		locker v1.ScrapeLocker
+	)
+	switch {
+	case client != nil:
+		redisStore := v1.NewRedisStore(client)
+		store, cache, locker = redisStore, redisStore, redisStore
+	case boltStore != nil:
+		store, cache = boltStore, boltStore
+	default:
+		memoryStore := v1.NewMemoryStore(cfg.MemoryCapacity)
+		store, cache = memoryStore, memoryStore
+	}
+	if cfg.SQLDriver != "" {
+		sqlStore, err := v1.NewSQLStore(cfg.SQLDriver, cfg.SQLDSN)
+		if err != nil {
+			return fmt.Errorf("failed to set up %s: %v", cfg.SQLDriver, err)
+		}
+		defer sqlStore.Close()
+		store = sqlStore
+		logger.Log.Info("storing products in SQL", zap.String("driver:", cfg.SQLDriver))
+	}
+	var queue *v1.RedisScrapeQueue
+	if client != nil {
+		queue = v1.NewRedisScrapeQueue(client)
+		if cfg.QueueMaxDeliveries > 0 {
+			queue.MaxDeliveries = int64(cfg.QueueMaxDeliveries)
+		}
+		if cfg.QueueRetryAfter > 0 {
+			queue.RetryAfter = cfg.QueueRetryAfter
+		}
+	}
+	if cfg.Mode == ModeWorker {
+		worker := v1.NewScrapeWorker(queue, cfg.QueueConsumer, store, cache, scraper, opts)
+		if cfg.QueueConcurrency > 0 {
+			worker.Concurrency = cfg.QueueConcurrency
+		}
+		logger.Log.Info("consuming the scrape queue", zap.String("consumer:", cfg.QueueConsumer),
+			zap.Int("concurrency:", worker.Concurrency))
+		return worker.Run(ctx)
+	}
+	if cfg.Mode == ModeAPI {
+		opts.Queue = queue
+	}
+	if cfg.Search {
+		if client == nil {
+			return fmt.Errorf("-search indexes products in RediSearch, use -storage=%s", StorageRedis)
+		}
+		search := v1.NewRediSearchIndex(client)
+		created, err := search.CreateIndex()
+		if err != nil {
+			return fmt.Errorf("failed to create search index: %v", err)
+		}
+		opts.Search = search
+		if lister, ok := store.(v1.ProductLister); ok {
+			//products stored before search was enabled are indexed once, by the replica creating the index
+			if created {
+				indexed, err := search.Rebuild(lister)
+				if err != nil {
+					return fmt.Errorf("failed to build search index: %v", err)
+				}
+				logger.Log.Info("indexed products for search", zap.Int("products:", indexed))
+			}
+			if cfg.SearchReindexInterval > 0 {
+				go search.RebuildEvery(ctx, lister, cfg.SearchReindexInterval)
+			}
+		}
+	}
+	v1API := v1.NewScraperServerWithStorage(store, cache, locker, scraper, opts)
+	if cfg.WatchlistRate > 0 {
+		if watchlist, ok := cache.(v1.WatchlistStore); ok {
+			scheduler := v1.NewWatchlistScheduler(v1API, watchlist, cfg.WatchlistRate)
+			if client != nil {
+				//replicas on the same Redis share the watchlist, and its budget
+				scheduler.Limiter = v1.NewRedisRateLimiter(client, cfg.WatchlistRate, 1)
+			}
+			go scheduler.Run(ctx)
+		} else {
+			logger.Log.Warn("the watchlist needs Redis or memory storage, watched products aren't refreshed")
+		}
+	}
+	//jobs are only kept in Redis, other storages answer the job RPCs with Unimplemented
+	if jobs, ok := cache.(v1.JobStore); ok && cfg.JobWorkers > 0 {
+		go v1.NewJobRunner(v1API, jobs, cfg.JobWorkers).Run(ctx)
+	}
 
 	// run REST gateway
 	go func() {
@@ -0,0 +1,150 @@
+package v1
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"strings"
+
+	v1 "github.com/rnidev/go-webscraper/pkg/api/v1"
+	"github.com/rnidev/go-webscraper/pkg/logger"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	//ErrMissingInvalidation returns if InvalidateCache has neither ASINs nor a pattern
+	ErrMissingInvalidation = errors.New("missing ASINs or pattern to invalidate")
+	//ErrInvalidPattern returns if an ASIN pattern has characters other than letters, digits, * and ?
+	ErrInvalidPattern = errors.New("pattern may only have letters, digits, * and ?")
+)
+
+//ProductDeleter deletes stored products, a ProductStore may implement it
+type ProductDeleter interface {
+	//DeleteProduct deletes a product with everything kept about it, deleted is false if it wasn't stored
+	DeleteProduct(asin string) (deleted bool, err error)
+}
+
+//CacheInvalidator drops cached products, a ProductCache may implement it
+type CacheInvalidator interface {
+	//InvalidateProducts drops the cached products of asins and returns how many were cached
+	InvalidateProducts(asins []string) (int64, error)
+	//InvalidatePattern drops the cached products whose ASIN matches a glob like "B07*"
+	InvalidatePattern(pattern string) (int64, error)
+}
+
+//DeleteProduct deletes a stored product with its cache, indexes and history
+func (s *webScraperServer) DeleteProduct(ctx context.Context, req *v1.DeleteProductRequest) (*v1.DeleteProductResponse, error) {
+	if err := s.authorizeAdmin(ctx); err != nil {
+		return &v1.DeleteProductResponse{}, err
+	}
+	asin, _, err := NormalizeASIN(req.Asin)
+	if err != nil {
+		return &v1.DeleteProductResponse{}, status.Error(codes.InvalidArgument, err.Error())
+	}
+	deleter, ok := s.store.(ProductDeleter)
+	if !ok {
+		return &v1.DeleteProductResponse{}, status.Error(codes.Unimplemented, "deleting products isn't supported by this storage")
+	}
+	deleted, err := deleter.DeleteProduct(asin)
+	if err != nil {
+		return &v1.DeleteProductResponse{}, err
+	}
+	//a store apart from the cache, like SQLStore, leaves the cache to clear
+	if interface{}(s.cache) != interface{}(s.store) {
+		if invalidator, ok := s.cache.(CacheInvalidator); ok {
+			if _, err = invalidator.InvalidateProducts([]string{asin}); err != nil {
+				return &v1.DeleteProductResponse{}, err
+			}
+		}
+		if err = s.cache.ClearNegativeCache(asin); err != nil {
+			return &v1.DeleteProductResponse{}, err
+		}
+	}
+	if s.opts.Search != nil {
+		if err = s.opts.Search.Remove(asin); err != nil {
+			return &v1.DeleteProductResponse{}, err
+		}
+	}
+	logger.Log.Info("deleted product", zap.String("asin", asin), zap.Bool("stored", deleted))
+	return &v1.DeleteProductResponse{Deleted: deleted}, nil
+}
+
+//InvalidateCache drops cached products, by ASIN or by pattern, so they are scraped again
+func (s *webScraperServer) InvalidateCache(ctx context.Context, req *v1.InvalidateCacheRequest) (*v1.InvalidateCacheResponse, error) {
+	if err := s.authorizeAdmin(ctx); err != nil {
+		return &v1.InvalidateCacheResponse{}, err
+	}
+	if len(req.Asins) == 0 && req.Pattern == "" {
+		return &v1.InvalidateCacheResponse{}, status.Error(codes.InvalidArgument, ErrMissingInvalidation.Error())
+	}
+	asins := make([]string, 0, len(req.Asins))
+	for _, value := range req.Asins {
+		asin, _, err := NormalizeASIN(value)
+		if err != nil {
+			return &v1.InvalidateCacheResponse{}, status.Error(codes.InvalidArgument, err.Error())
+		}
+		asins = append(asins, asin)
+	}
+	pattern := strings.ToUpper(strings.TrimSpace(req.Pattern))
+	if !validASINPattern(pattern) {
+		return &v1.InvalidateCacheResponse{}, status.Error(codes.InvalidArgument, ErrInvalidPattern.Error())
+	}
+	invalidator, ok := s.cache.(CacheInvalidator)
+	if !ok {
+		return &v1.InvalidateCacheResponse{}, status.Error(codes.Unimplemented, "invalidating the cache isn't supported by this storage")
+	}
+
+	res := &v1.InvalidateCacheResponse{}
+	invalidated, err := invalidator.InvalidateProducts(asins)
+	if err != nil {
+		return &v1.InvalidateCacheResponse{}, err
+	}
+	res.Invalidated += invalidated
+	if pattern != "" {
+		if invalidated, err = invalidator.InvalidatePattern(pattern); err != nil {
+			return &v1.InvalidateCacheResponse{}, err
+		}
+		res.Invalidated += invalidated
+	}
+	logger.Log.Info("invalidated cached products", zap.Strings("asins", asins),
+		zap.String("pattern", pattern), zap.Int64("invalidated", res.Invalidated))
+	return res, nil
+}
+
+//authorizeAdmin checks the request carries one of the admin tokens as "Authorization: Bearer {token}",
+//the REST gateway forwards the header as is
+func (s *webScraperServer) authorizeAdmin(ctx context.Context) error {
+	if len(s.opts.AdminTokens) == 0 {
+		return status.Error(codes.PermissionDenied, "admin RPCs are disabled, no admin token is configured")
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	headers := md.Get("authorization")
+	if len(headers) == 0 {
+		return status.Error(codes.Unauthenticated, "missing admin token")
+	}
+	for _, header := range headers {
+		if len(header) < len("bearer ") || !strings.EqualFold(header[:len("bearer ")], "bearer ") {
+			continue
+		}
+		token := []byte(strings.TrimSpace(header[len("bearer "):]))
+		for _, adminToken := range s.opts.AdminTokens {
+			if subtle.ConstantTimeCompare(token, []byte(adminToken)) == 1 {
+				return nil
+			}
+		}
+	}
+	return status.Error(codes.PermissionDenied, "invalid admin token")
+}
+
+//validASINPattern allows globs that mean the same to Redis SCAN and path.Match
+func validASINPattern(pattern string) bool {
+	for _, r := range pattern {
+		if !(r >= 'A' && r <= 'Z') && !(r >= '0' && r <= '9') && r != '*' && r != '?' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,191 @@
+package v1
+
+import (
+	"errors"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+const (
+	//defaultMarketplace is the Amazon domain scraped when none is given
+	defaultMarketplace = "www.amazon.com"
+)
+
+var (
+	//ErrInvalidASIN returns if input is not a valid ASIN, ISBN or product URL
+	ErrInvalidASIN = errors.New("invalid ASIN, expected 10 alphanumeric characters, an ISBN-13 or an Amazon product URL")
+	//ErrInvalidISBN returns if an ISBN-13 fails its checksum or cannot map to ISBN-10
+	ErrInvalidISBN = errors.New("invalid ISBN-13")
+	//ErrInvalidProductURL returns if a URL is not an Amazon product page
+	ErrInvalidProductURL = errors.New("invalid Amazon product URL")
+	//ErrInvalidMarketplace returns if a domain is not an Amazon marketplace
+	ErrInvalidMarketplace = errors.New("invalid Amazon marketplace")
+
+	asinPattern   = regexp.MustCompile(`^[A-Z0-9]{10}$`)
+	isbn10Pattern = regexp.MustCompile(`^[0-9]{9}[0-9X]$`)
+	isbn13Pattern = regexp.MustCompile(`^97[89][0-9]{10}$`)
+	//Known product page paths, e.g. /Some-Name/dp/B07FSH5L52/ref=... or /gp/product/B07FSH5L52
+	productPathPattern = regexp.MustCompile(`(?i)/(?:dp|gp/product|gp/aw/d|exec/obidos/asin|o/asin)/([a-z0-9]{10})(?:[/?]|$)`)
+
+	//amazonMarketplaces are the Amazon retail domains products are scraped from
+	amazonMarketplaces = map[string]bool{
+		"amazon.com":    true,
+		"amazon.ca":     true,
+		"amazon.com.mx": true,
+		"amazon.com.br": true,
+		"amazon.co.uk":  true,
+		"amazon.de":     true,
+		"amazon.fr":     true,
+		"amazon.it":     true,
+		"amazon.es":     true,
+		"amazon.nl":     true,
+		"amazon.se":     true,
+		"amazon.pl":     true,
+		"amazon.com.be": true,
+		"amazon.com.tr": true,
+		"amazon.ae":     true,
+		"amazon.sa":     true,
+		"amazon.eg":     true,
+		"amazon.in":     true,
+		"amazon.co.jp":  true,
+		"amazon.sg":     true,
+		"amazon.com.au": true,
+		"amazon.cn":     true,
+	}
+)
+
+//NormalizeASIN validates a raw ASIN, ISBN-13 or Amazon product URL,
+//and returns an upper-cased ASIN with the marketplace domain to scrape
+func NormalizeASIN(input string) (asin string, marketplace string, err error) {
+	input = strings.TrimSpace(input)
+	if input == "" {
+		err = ErrMissingASIN
+		return
+	}
+	if strings.Contains(input, "/") {
+		return parseProductURL(input)
+	}
+
+	candidate := strings.ToUpper(strings.Replace(input, "-", "", -1))
+	switch {
+	case isbn13Pattern.MatchString(candidate):
+		asin, err = ISBN13ToISBN10(candidate)
+	case isbn10Pattern.MatchString(candidate):
+		asin = candidate
+		if !validISBN10(candidate) {
+			err = ErrInvalidASIN
+		}
+	case asinPattern.MatchString(candidate) && !strings.Contains(input, "-"):
+		asin = candidate
+	default:
+		err = ErrInvalidASIN
+	}
+	if err != nil {
+		return "", "", err
+	}
+	return asin, defaultMarketplace, nil
+}
+
+//parseProductURL extracts ASIN and marketplace from an Amazon product URL
+func parseProductURL(rawURL string) (asin string, marketplace string, err error) {
+	if !strings.Contains(rawURL, "://") {
+		rawURL = "https://" + rawURL
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		err = ErrInvalidProductURL
+		return
+	}
+	marketplace, err = NormalizeMarketplace(u.Hostname())
+	if err != nil {
+		err = ErrInvalidProductURL
+		return
+	}
+	match := productPathPattern.FindStringSubmatch(u.Path)
+	if len(match) < 2 {
+		return "", "", ErrInvalidProductURL
+	}
+	asin = strings.ToUpper(match[1])
+	return
+}
+
+//NormalizeMarketplace validates an Amazon domain and returns its www form,
+//an empty domain falls back to www.amazon.com
+func NormalizeMarketplace(domain string) (marketplace string, err error) {
+	domain = strings.ToLower(strings.TrimSpace(domain))
+	if domain == "" {
+		return defaultMarketplace, nil
+	}
+	//smile.amazon.* and bare amazon.* serve the same catalog as www
+	for _, prefix := range []string{"www.", "smile."} {
+		if strings.HasPrefix(domain, prefix) {
+			domain = strings.TrimPrefix(domain, prefix)
+			break
+		}
+	}
+	if !amazonMarketplaces[domain] {
+		err = ErrInvalidMarketplace
+		return
+	}
+	marketplace = "www." + domain
+	return
+}
+
+//productMarketplace is the marketplace product was scraped from,
+//products stored before marketplaces were tracked are from the default one
+func productMarketplace(product *AmazonProduct) string {
+	if product.Marketplace == "" {
+		return defaultMarketplace
+	}
+	return product.Marketplace
+}
+
+//ISBN13ToISBN10 converts a 978-prefixed ISBN-13 into the ISBN-10 Amazon uses as ASIN
+func ISBN13ToISBN10(isbn13 string) (isbn10 string, err error) {
+	if !isbn13Pattern.MatchString(isbn13) || !validISBN13(isbn13) {
+		err = ErrInvalidISBN
+		return
+	}
+	//979 prefixed ISBNs have no ISBN-10 equivalent
+	if !strings.HasPrefix(isbn13, "978") {
+		err = ErrInvalidISBN
+		return
+	}
+	body := isbn13[3:12]
+	sum := 0
+	for i, r := range body {
+		sum += (10 - i) * int(r-'0')
+	}
+	check := (11 - sum%11) % 11
+	if check == 10 {
+		isbn10 = body + "X"
+	} else {
+		isbn10 = body + string(rune('0'+check))
+	}
+	return
+}
+
+func validISBN13(isbn13 string) bool {
+	sum := 0
+	for i, r := range isbn13 {
+		digit := int(r - '0')
+		if i%2 == 1 {
+			digit *= 3
+		}
+		sum += digit
+	}
+	return sum%10 == 0
+}
+
+func validISBN10(isbn10 string) bool {
+	sum := 0
+	for i, r := range isbn10 {
+		digit := int(r - '0')
+		if r == 'X' {
+			digit = 10
+		}
+		sum += (10 - i) * digit
+	}
+	return sum%11 == 0
+}
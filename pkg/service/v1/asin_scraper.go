@@ -1,59 +1,69 @@
 package v1
 
 import (
+	"context"
 	"strings"
-	"time"
 
 	"github.com/gocolly/colly"
-	"github.com/gocolly/colly/extensions"
 )
 
 //AmazonProduct is the default product struct for ASIN service
 type AmazonProduct struct {
-	Asin       string   `json:"asin"`
-	Name       string   `json:"name"`
-	Categories []string `json:"categories"`
-	Ranks      []string `json:"ranks"`
-	Dimensions []string `json:"dimensions"`
-	CreatedAt  string   `json:"created_at"`
+	Asin         string   `json:"asin"`
+	Name         string   `json:"name"`
+	Brand        string   `json:"brand,omitempty"`
+	Features     []string `json:"features,omitempty"`
+	Categories   []string `json:"categories"`
+	Ranks        []string `json:"ranks"`
+	Dimensions   []string `json:"dimensions"`
+	Price        string   `json:"price,omitempty"`
+	Availability string   `json:"availability,omitempty"`
+	Marketplace  string   `json:"marketplace,omitempty"`
+	CreatedAt    string   `json:"created_at"`
 }
 
-//GetProductInfoByASIN takes asin, build target url, and returns product info
-func (product *AmazonProduct) GetProductInfoByASIN() (res *colly.Response, err error) {
-	//ToDo: Take domain as a request for Phase 2
-	domain := "www.amazon.com"
-	var productURL string
-	productURL = "https://" + domain + "/dp/" + product.Asin
+//newCollector instantiates the long-lived collector for domain,
+//scrapes run on clones of it, which share its HTTP backend, cookie jar and proxy settings
+func newCollector(domain string) *colly.Collector {
 	// Instantiate default collector
 	c := colly.NewCollector(
 		//Only allow whitelisted domains to be visited
 		colly.AllowedDomains(domain),
 		colly.Async(true),
+		//retries and repeated lookups visit the same URL again
+		colly.AllowURLRevisit(),
 	)
+	c.SetRequestTimeout(scrapeRequestTimeout)
+	return c
+}
+
+//GetProductInfoByASIN takes asin, build target url, and returns product info
+func (product *AmazonProduct) GetProductInfoByASIN() (res *colly.Response, err error) {
+	return DefaultScraper.ScrapeProduct(context.Background(), product)
+}
 
-	cookie := c.Cookies(productURL)
-	err = c.SetCookies(productURL, cookie)
-	if err != nil {
-		return
+//ScrapeProduct scrapes product info by ASIN, retrying with the scraper's policy
+func (s *Scraper) ScrapeProduct(ctx context.Context, product *AmazonProduct) (res *colly.Response, err error) {
+	domain := product.Marketplace
+	if domain == "" {
+		domain = defaultMarketplace
 	}
-	//Randomize useragent to avoid bot detection
-	extensions.RandomUserAgent(c)
-	// Limit the number of threads started by colly to two
-	// To avoid bot detection
-	// when visiting links which domains' matches "*amazon.*" glob
-	// Set random redlay to 2 secs
-	c.Limit(&colly.LimitRule{
-		DomainGlob:  "*amazon.*",
-		Parallelism: 2,
-		Delay:       2 * time.Second,
-	})
-
-	// Error Handling
-	c.OnError(func(r *colly.Response, rerr error) {
-		res = r
-		err = rerr
-		return
-	})
+	var productURL string
+	productURL = "https://" + domain + "/dp/" + product.Asin
+	return s.Collect(ctx, domain, productURL, product.registerScrapeHandlers)
+}
+
+//registerScrapeHandlers resets scraped fields and registers the product page callbacks
+func (product *AmazonProduct) registerScrapeHandlers(c *colly.Collector) {
+	//drop whatever a previous attempt collected
+	product.Name = ""
+	product.Brand = ""
+	product.Features = nil
+	product.Categories = nil
+	product.Ranks = nil
+	product.Dimensions = nil
+	product.Price = ""
+	product.Availability = ""
 
 	// Start scraping product information
 	/*
@@ -78,6 +88,45 @@ func (product *AmazonProduct) GetProductInfoByASIN() (res *colly.Response, err e
 			product.Name = productName
 		})
 
+	/*
+		Target: Product Brand, the byline under the title
+		"Visit the Amazon Store" and "Brand: Amazon" bylines are both reduced to the brand
+	*/
+	c.OnHTML("#bylineInfo",
+		func(e *colly.HTMLElement) {
+			brand := strings.Join(strings.Fields(e.Text), " ")
+			brand = strings.TrimPrefix(brand, "Brand: ")
+			if strings.HasPrefix(brand, "Visit the ") && strings.HasSuffix(brand, " Store") {
+				brand = strings.TrimSuffix(strings.TrimPrefix(brand, "Visit the "), " Store")
+			}
+			product.Brand = ConvertHTMLEntities(brand)
+		})
+
+	//Target: Product Features, the "About this item" bullets, hidden ones are left out
+	c.OnHTML("#feature-bullets ul li:not(.aok-hidden) span.a-list-item",
+		func(e *colly.HTMLElement) {
+			if feature := strings.Join(strings.Fields(e.Text), " "); feature != "" {
+				product.Features = append(product.Features, ConvertHTMLEntities(feature))
+			}
+		})
+
+	/*
+		Target: Product Price, as shown on the page with its currency
+		The buy box shows the regular, deal or sale price, the first one found is kept
+	*/
+	c.OnHTML("#priceblock_ourprice, #priceblock_dealprice, #priceblock_saleprice, #price_inside_buybox",
+		func(e *colly.HTMLElement) {
+			if price := strings.TrimSpace(e.Text); product.Price == "" && price != "" {
+				product.Price = ConvertHTMLEntities(price)
+			}
+		})
+
+	//Target: Product Availability, e.g. "In Stock." or "Currently unavailable."
+	c.OnHTML("#availability",
+		func(e *colly.HTMLElement) {
+			product.Availability = ConvertHTMLEntities(strings.Join(strings.Fields(e.Text), " "))
+		})
+
 	//There are three different layouts for product details: Ranks and Dimensions
 	//Try Table View
 	/*
@@ -190,9 +239,4 @@ func (product *AmazonProduct) GetProductInfoByASIN() (res *colly.Response, err e
 				}
 			}
 		})
-
-	c.Visit(productURL)
-	//Wait for collector to finish
-	c.Wait()
-	return
 }
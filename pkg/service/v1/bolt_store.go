@@ -0,0 +1,529 @@
+package v1
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"math"
+	"path"
+	"sync"
+	"time"
+
+	"github.com/rnidev/go-webscraper/pkg/logger"
+	"go.uber.org/zap"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	boltProducts = []byte("products")
+	boltCodes    = []byte("codes")
+	boltCache    = []byte("cache")
+	boltHistory  = []byte("history")
+
+	//boltSweepInterval is how often expired cache entries are dropped from the file
+	boltSweepInterval = 10 * time.Minute
+)
+
+//BoltStore is a ProductStore and ProductCache in a BoltDB file, for single node deployments
+//without Redis. Products survive restarts, cache entries are dropped once they expire
+type BoltStore struct {
+	db *bolt.DB
+	//stop ends the sweep of expired cache entries, done closes once it ended
+	stop      chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+//boltEntry is a cache entry with its expiry, a zero ExpiresAt never expires
+type boltEntry struct {
+	Value     json.RawMessage `json:"value"`
+	ExpiresAt int64           `json:"expires_at"`
+}
+
+//boltTTLState is what AdaptTTL keeps per product
+type boltTTLState struct {
+	Factor    float64 `json:"factor"`
+	Unchanged int     `json:"unchanged"`
+}
+
+//NewBoltStore opens or creates the BoltDB file at path, drops expired cache entries
+//and keeps dropping them every boltSweepInterval until Close
+func NewBoltStore(path string) (*BoltStore, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, err
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, bucket := range [][]byte{boltProducts, boltCodes, boltCache, boltHistory} {
+			if _, err := tx.CreateBucketIfNotExists(bucket); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	store := &BoltStore{db: db, stop: make(chan struct{}), done: make(chan struct{})}
+	if err = store.DeleteExpired(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	go store.sweep(boltSweepInterval)
+	return store, nil
+}
+
+//Close stops the sweep and closes the BoltDB file
+func (b *BoltStore) Close() error {
+	b.closeOnce.Do(func() {
+		close(b.stop)
+		<-b.done
+	})
+	return b.db.Close()
+}
+
+//sweep drops expired cache entries every interval until Close
+func (b *BoltStore) sweep(interval time.Duration) {
+	defer close(b.done)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-b.stop:
+			return
+		case <-ticker.C:
+			if err := b.DeleteExpired(); err != nil {
+				logger.Log.Warn("failed to drop expired cache entries", zap.String("error", err.Error()))
+			}
+		}
+	}
+}
+
+//DeleteExpired drops expired cache entries, they are ignored on read anyway
+func (b *BoltStore) DeleteExpired() error {
+	now := time.Now().UnixNano()
+	return b.db.Update(func(tx *bolt.Tx) error {
+		cursor := tx.Bucket(boltCache).Cursor()
+		for key, value := cursor.First(); key != nil; key, value = cursor.Next() {
+			var entry boltEntry
+			if json.Unmarshal(value, &entry) != nil || (entry.ExpiresAt != 0 && entry.ExpiresAt <= now) {
+				if err := cursor.Delete(); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
+
+//StoreProduct saves product in the products bucket
+func (b *BoltStore) StoreProduct(product *AmazonProduct) error {
+	if product.Asin == "" {
+		return ErrMissingASIN
+	}
+	if product.Name == "" {
+		return errMissingProductName
+	}
+	if len(product.Categories) == 0 {
+		return errMissingProductCategory
+	}
+	productJSON, err := json.Marshal(product)
+	if err != nil {
+		return err
+	}
+	return b.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(boltProducts).Put([]byte(product.Asin), productJSON)
+	})
+}
+
+//FetchProduct reads a product from the products bucket
+func (b *BoltStore) FetchProduct(asin string) (product AmazonProduct, err error) {
+	if asin == "" {
+		err = ErrMissingASIN
+		return
+	}
+	err = b.db.View(func(tx *bolt.Tx) error {
+		value := tx.Bucket(boltProducts).Get([]byte(asin))
+		if value == nil {
+			return ErrProductNotFound
+		}
+		return json.Unmarshal(value, &product)
+	})
+	return
+}
+
+//FetchProducts reads products from the products bucket in one transaction
+func (b *BoltStore) FetchProducts(asins []string) (products []AmazonProduct, err error) {
+	err = b.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(boltProducts)
+		for _, asin := range asins {
+			value := bucket.Get([]byte(asin))
+			if value == nil {
+				continue
+			}
+			var product AmazonProduct
+			if err := json.Unmarshal(value, &product); err != nil {
+				return err
+			}
+			products = append(products, product)
+		}
+		return nil
+	})
+	return
+}
+
+//ScanProducts returns products of the products bucket in key order, after the ASIN in cursor
+func (b *BoltStore) ScanProducts(cursor string, count int) (products []AmazonProduct, next string, err error) {
+	err = b.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(boltProducts).Cursor()
+		key, value := c.Seek([]byte(cursor))
+		if key != nil && string(key) == cursor {
+			key, value = c.Next()
+		}
+		for ; key != nil; key, value = c.Next() {
+			if len(products) == count {
+				next = products[count-1].Asin
+				return nil
+			}
+			var product AmazonProduct
+			if err := json.Unmarshal(value, &product); err != nil {
+				return err
+			}
+			products = append(products, product)
+		}
+		return nil
+	})
+	return
+}
+
+//DeleteProduct drops a product with its history and everything cached about it
+func (b *BoltStore) DeleteProduct(asin string) (deleted bool, err error) {
+	if asin == "" {
+		err = ErrMissingASIN
+		return
+	}
+	err = b.db.Update(func(tx *bolt.Tx) error {
+		products := tx.Bucket(boltProducts)
+		deleted = products.Get([]byte(asin)) != nil
+		if err := products.Delete([]byte(asin)); err != nil {
+			return err
+		}
+		cache := tx.Bucket(boltCache)
+		for _, key := range []string{"cacheProduct:", "notFound:", "robotCheck:", "cacheTTL:"} {
+			if err := cache.Delete([]byte(key + asin)); err != nil {
+				return err
+			}
+		}
+		prefix := []byte(asin + "/")
+		cursor := tx.Bucket(boltHistory).Cursor()
+		for key, _ := cursor.Seek(prefix); key != nil && bytes.HasPrefix(key, prefix); key, _ = cursor.Seek(prefix) {
+			if err := cursor.Delete(); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return
+}
+
+//StoreCodeMapping saves the ASIN a product code resolves to in the codes bucket
+func (b *BoltStore) StoreCodeMapping(codeType string, code string, asin string) error {
+	if asin == "" {
+		return ErrMissingASIN
+	}
+	if code == "" {
+		return ErrMissingCode
+	}
+	return b.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(boltCodes).Put([]byte(codeType+":"+code), []byte(asin))
+	})
+}
+
+//FetchASINByCode reads the ASIN a product code resolved to
+func (b *BoltStore) FetchASINByCode(codeType string, code string) (asin string, err error) {
+	err = b.db.View(func(tx *bolt.Tx) error {
+		asin = string(tx.Bucket(boltCodes).Get([]byte(codeType + ":" + code)))
+		return nil
+	})
+	return
+}
+
+//GetProduct reads cacheProduct:{ASIN} from the cache bucket
+func (b *BoltStore) GetProduct(asin string) (product AmazonProduct, err error) {
+	ok, err := b.getCache("cacheProduct:"+asin, &product)
+	if err == nil && !ok {
+		err = ErrCacheMiss
+	}
+	return
+}
+
+//AddProduct caches product for ttl
+func (b *BoltStore) AddProduct(product *AmazonProduct, ttl time.Duration) error {
+	if product.Name == "" {
+		return errEmptyProduct
+	}
+	if ttl == 0 {
+		return errMissingTTLDuration
+	}
+	return b.setCache("cacheProduct:"+product.Asin, product, ttl)
+}
+
+//InvalidateProducts drops the cached copies of asins, it returns how many were cached
+func (b *BoltStore) InvalidateProducts(asins []string) (invalidated int64, err error) {
+	err = b.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(boltCache)
+		for _, asin := range asins {
+			live, err := deleteCacheEntry(bucket, []byte("cacheProduct:"+asin))
+			if err != nil {
+				return err
+			}
+			if live {
+				invalidated++
+			}
+		}
+		return nil
+	})
+	return
+}
+
+//InvalidatePattern drops the cached copies of ASINs matching the glob pattern
+func (b *BoltStore) InvalidatePattern(pattern string) (invalidated int64, err error) {
+	err = b.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(boltCache)
+		prefix := []byte("cacheProduct:")
+		var keys [][]byte
+		cursor := bucket.Cursor()
+		for key, _ := cursor.Seek(prefix); key != nil && bytes.HasPrefix(key, prefix); key, _ = cursor.Next() {
+			matched, err := path.Match(pattern, string(key[len(prefix):]))
+			if err != nil {
+				return err
+			}
+			if matched {
+				keys = append(keys, append([]byte(nil), key...))
+			}
+		}
+		for _, key := range keys {
+			live, err := deleteCacheEntry(bucket, key)
+			if err != nil {
+				return err
+			}
+			if live {
+				invalidated++
+			}
+		}
+		return nil
+	})
+	return
+}
+
+//MarkNotFound remembers for ttl that an ASIN has no product page
+func (b *BoltStore) MarkNotFound(asin string, ttl time.Duration) error {
+	if asin == "" {
+		return ErrMissingASIN
+	}
+	if ttl == 0 {
+		return errMissingTTLDuration
+	}
+	return b.setCache("notFound:"+asin, time.Now().UnixNano(), ttl)
+}
+
+//NotFoundSince returns when an ASIN was marked as not found
+func (b *BoltStore) NotFoundSince(asin string) (since time.Time, err error) {
+	var nanos int64
+	ok, err := b.getCache("notFound:"+asin, &nanos)
+	if err != nil || !ok {
+		return
+	}
+	return time.Unix(0, nanos), nil
+}
+
+//RecordRobotCheck counts a robot check, the count is reset window after the first one
+func (b *BoltStore) RecordRobotCheck(asin string, window time.Duration) (count int64, err error) {
+	if asin == "" {
+		err = ErrMissingASIN
+		return
+	}
+	key := []byte("robotCheck:" + asin)
+	err = b.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(boltCache)
+		entry := boltEntry{ExpiresAt: time.Now().Add(window).UnixNano()}
+		var current boltEntry
+		if value := bucket.Get(key); value != nil && json.Unmarshal(value, &current) == nil &&
+			current.ExpiresAt > time.Now().UnixNano() {
+			//the window keeps running from the first robot check
+			json.Unmarshal(current.Value, &count)
+			entry.ExpiresAt = current.ExpiresAt
+		}
+		count++
+		var err error
+		if entry.Value, err = json.Marshal(count); err != nil {
+			return err
+		}
+		value, err := json.Marshal(entry)
+		if err != nil {
+			return err
+		}
+		return bucket.Put(key, value)
+	})
+	return
+}
+
+//RobotCheckCount returns the robot checks of an ASIN in its current window
+func (b *BoltStore) RobotCheckCount(asin string) (count int64, err error) {
+	_, err = b.getCache("robotCheck:"+asin, &count)
+	return
+}
+
+//ClearNegativeCache forgets that an ASIN was not found or blocked
+func (b *BoltStore) ClearNegativeCache(asin string) error {
+	return b.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(boltCache)
+		if err := bucket.Delete([]byte("notFound:" + asin)); err != nil {
+			return err
+		}
+		return bucket.Delete([]byte("robotCheck:" + asin))
+	})
+}
+
+//TTLState returns the adaptive TTL state of a product
+func (b *BoltStore) TTLState(asin string) (factor float64, unchanged int, err error) {
+	var state boltTTLState
+	_, err = b.getCache("cacheTTL:"+asin, &state)
+	return state.Factor, state.Unchanged, err
+}
+
+//SetTTLState saves the adaptive TTL state of a product
+func (b *BoltStore) SetTTLState(asin string, factor float64, unchanged int) error {
+	return b.setCache("cacheTTL:"+asin, boltTTLState{Factor: factor, Unchanged: unchanged}, 0)
+}
+
+//AddSnapshot adds product to the history bucket, keyed by ASIN and scrape time,
+//and drops snapshots beyond retention
+func (b *BoltStore) AddSnapshot(product *AmazonProduct, retention HistoryRetention) error {
+	if product.Asin == "" {
+		return ErrMissingASIN
+	}
+	createdAt, err := time.Parse(time.RFC3339Nano, product.CreatedAt)
+	if err != nil {
+		return err
+	}
+	snapshot, err := json.Marshal(product)
+	if err != nil {
+		return err
+	}
+	return b.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(boltHistory)
+		//the sequence keeps snapshots scraped in the same nanosecond apart
+		seq, err := bucket.NextSequence()
+		if err != nil {
+			return err
+		}
+		if err = bucket.Put(boltHistoryKey(product.Asin, createdAt, seq), snapshot); err != nil {
+			return err
+		}
+
+		prefix := []byte(product.Asin + "/")
+		var keys [][]byte
+		cursor := bucket.Cursor()
+		for key, _ := cursor.Seek(prefix); key != nil && bytes.HasPrefix(key, prefix); key, _ = cursor.Next() {
+			keys = append(keys, append([]byte(nil), key...))
+		}
+		//keys are ordered by scrape time, oldest first
+		drop := 0
+		if retention.MaxAge > 0 {
+			cutoff := boltHistoryKey(product.Asin, time.Now().Add(-retention.MaxAge), 0)
+			for drop < len(keys) && bytes.Compare(keys[drop], cutoff) < 0 {
+				drop++
+			}
+		}
+		if retention.MaxSnapshots > 0 && len(keys)-drop > retention.MaxSnapshots {
+			drop = len(keys) - retention.MaxSnapshots
+		}
+		for _, key := range keys[:drop] {
+			if err := bucket.Delete(key); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+//Snapshots returns the snapshots of a product scraped within from and to
+func (b *BoltStore) Snapshots(asin string, from time.Time, to time.Time) (snapshots []AmazonProduct, err error) {
+	last := boltHistoryKey(asin, to, math.MaxUint64)
+	err = b.db.View(func(tx *bolt.Tx) error {
+		cursor := tx.Bucket(boltHistory).Cursor()
+		for key, value := cursor.Seek(boltHistoryKey(asin, from, 0)); key != nil && bytes.Compare(key, last) <= 0; key, value = cursor.Next() {
+			var snapshot AmazonProduct
+			if err := json.Unmarshal(value, &snapshot); err != nil {
+				return err
+			}
+			snapshots = append(snapshots, snapshot)
+		}
+		return nil
+	})
+	return
+}
+
+//boltHistoryKey is the ASIN, a slash, the big endian scrape time and a big endian sequence,
+//so keys sort by time per ASIN
+func boltHistoryKey(asin string, t time.Time, seq uint64) []byte {
+	key := make([]byte, len(asin)+1+16)
+	copy(key, asin+"/")
+	binary.BigEndian.PutUint64(key[len(asin)+1:], uint64(t.UnixNano()))
+	binary.BigEndian.PutUint64(key[len(asin)+9:], seq)
+	return key
+}
+
+//getCache decodes the cache entry at key into v, ok is false if it is missing or expired
+func (b *BoltStore) getCache(key string, v interface{}) (ok bool, err error) {
+	err = b.db.View(func(tx *bolt.Tx) error {
+		value := tx.Bucket(boltCache).Get([]byte(key))
+		if value == nil {
+			return nil
+		}
+		var entry boltEntry
+		if err := json.Unmarshal(value, &entry); err != nil {
+			return err
+		}
+		if entry.ExpiresAt != 0 && entry.ExpiresAt <= time.Now().UnixNano() {
+			return nil
+		}
+		ok = true
+		return json.Unmarshal(entry.Value, v)
+	})
+	return
+}
+
+//deleteCacheEntry deletes key from the cache bucket, live is false if it was missing or expired
+func deleteCacheEntry(bucket *bolt.Bucket, key []byte) (live bool, err error) {
+	if value := bucket.Get(key); value != nil {
+		var entry boltEntry
+		live = json.Unmarshal(value, &entry) == nil &&
+			(entry.ExpiresAt == 0 || entry.ExpiresAt > time.Now().UnixNano())
+	}
+	return live, bucket.Delete(key)
+}
+
+//setCache stores v at key in the cache bucket, a ttl of 0 never expires
+func (b *BoltStore) setCache(key string, v interface{}, ttl time.Duration) error {
+	var (
+		entry boltEntry
+		err   error
+	)
+	if entry.Value, err = json.Marshal(v); err != nil {
+		return err
+	}
+	if ttl > 0 {
+		entry.ExpiresAt = time.Now().Add(ttl).UnixNano()
+	}
+	value, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	return b.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(boltCache).Put([]byte(key), value)
+	})
+}
@@ -0,0 +1,103 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	v1 "github.com/rnidev/go-webscraper/pkg/api/v1"
+	"google.golang.org/grpc/metadata"
+)
+
+var (
+	//ErrNegativeMaxAge returns if max_age is below zero
+	ErrNegativeMaxAge = errors.New("max_age can't be negative")
+	//ErrConflictingCacheControl returns if a request asks to both bypass and only use the cache
+	ErrConflictingCacheControl = errors.New("force_refresh and cache_only can't be used together")
+
+	//cacheControlKeys are metadata keys a Cache-Control header arrives with,
+	//the REST gateway prefixes permanent HTTP headers with grpcgateway-
+	cacheControlKeys = []string{"cache-control", "grpcgateway-cache-control"}
+)
+
+//cacheOptions is how fresh a caller needs its product to be
+type cacheOptions struct {
+	//maxAge is the oldest product the caller accepts, 0 accepts whatever the cache holds
+	maxAge time.Duration
+	//forceRefresh skips cache and stored copies and always scrapes
+	forceRefresh bool
+	//cacheOnly never scrapes, products that aren't cached are NotFound
+	cacheOnly bool
+}
+
+//cacheOptionsFromRequest reads cache control fields of GetProductRequest,
+//falling back to a Cache-Control header for the fields that aren't set
+func cacheOptionsFromRequest(ctx context.Context, req *v1.GetProductRequest) (opts cacheOptions, err error) {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		for _, key := range cacheControlKeys {
+			for _, header := range md.Get(key) {
+				opts = opts.merge(parseCacheControl(header))
+			}
+		}
+	}
+	if req.MaxAge < 0 {
+		err = ErrNegativeMaxAge
+		return
+	}
+	if req.MaxAge > 0 {
+		opts.maxAge = time.Duration(req.MaxAge) * time.Second
+	}
+	opts.forceRefresh = opts.forceRefresh || req.ForceRefresh
+	opts.cacheOnly = opts.cacheOnly || req.CacheOnly
+	if opts.forceRefresh && opts.cacheOnly {
+		err = ErrConflictingCacheControl
+	}
+	return
+}
+
+//parseCacheControl maps max-age, no-cache and only-if-cached directives,
+//max-age=0 asks for a fresh product like no-cache does
+func parseCacheControl(header string) (opts cacheOptions) {
+	for _, directive := range strings.Split(header, ",") {
+		directive = strings.ToLower(strings.TrimSpace(directive))
+		switch {
+		case directive == "no-cache":
+			opts.forceRefresh = true
+		case directive == "only-if-cached":
+			opts.cacheOnly = true
+		case strings.HasPrefix(directive, "max-age="):
+			seconds, err := strconv.ParseInt(strings.TrimPrefix(directive, "max-age="), 10, 64)
+			if err != nil || seconds < 0 {
+				continue
+			}
+			if seconds == 0 {
+				opts.forceRefresh = true
+				continue
+			}
+			opts.maxAge = time.Duration(seconds) * time.Second
+		}
+	}
+	return
+}
+
+func (o cacheOptions) merge(other cacheOptions) cacheOptions {
+	if other.maxAge > 0 && (o.maxAge == 0 || other.maxAge < o.maxAge) {
+		o.maxAge = other.maxAge
+	}
+	o.forceRefresh = o.forceRefresh || other.forceRefresh
+	o.cacheOnly = o.cacheOnly || other.cacheOnly
+	return o
+}
+
+//notBefore is the oldest scrape time the caller accepts, zero accepts any
+func (o cacheOptions) notBefore() time.Time {
+	if o.forceRefresh {
+		return time.Now()
+	}
+	if o.maxAge > 0 {
+		return time.Now().Add(-o.maxAge)
+	}
+	return time.Time{}
+}
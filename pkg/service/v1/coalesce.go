@@ -0,0 +1,220 @@
+package v1
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/rnidev/go-webscraper/pkg/logger"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	//scrapeLockTTL bounds how long a replica may lead a scrape,
+	//it has to outlast a scrape with all its retries
+	scrapeLockTTL = 2 * time.Minute
+	//scrapeLockPoll is how often followers check for the leader's result
+	scrapeLockPoll = 250 * time.Millisecond
+)
+
+//coalescedScrape scrapes an uncached ASIN once for all concurrent callers.
+//Callers in this process share a single flight, and across replicas
+//only the holder of lock:scrape:{ASIN} scrapes while the others wait for its result.
+//A product cached meanwhile is used if it was scraped from marketplace at or after notBefore,
+//a zero notBefore accepts any cached product
+func (s *webScraperServer) coalescedScrape(ctx context.Context, asin string, marketplace string, notBefore time.Time) (AmazonProduct, error) {
+	//callers asking for fresher data don't join a flight that may settle for the cache,
+	//and callers asking for another marketplace want another product
+	key := marketplace + "/" + asin
+	if !notBefore.IsZero() {
+		key += "@" + strconv.FormatInt(notBefore.Unix(), 10)
+	}
+	//the shared scrape must not fail because the caller that started it went away
+	result := s.inflight.DoChan(key, func() (interface{}, error) {
+		return s.leadOrFollow(context.Background(), asin, marketplace, notBefore)
+	})
+	select {
+	case r := <-result:
+		if r.Err != nil {
+			return AmazonProduct{}, r.Err
+		}
+		return r.Val.(AmazonProduct), nil
+	case <-ctx.Done():
+		return AmazonProduct{}, ctx.Err()
+	}
+}
+
+//leadOrFollow scrapes the ASIN if this replica gets the lock,
+//otherwise waits for the leading replica to cache its result
+func (s *webScraperServer) leadOrFollow(ctx context.Context, asin string, marketplace string, notBefore time.Time) (AmazonProduct, error) {
+	//without a locker this is the only replica, and the single flight is enough
+	if s.locker == nil {
+		return s.scrapeUnlessKnown(ctx, asin, marketplace, notBefore)
+	}
+	for {
+		token, err := s.locker.AcquireScrapeLock(asin, scrapeLockTTL)
+		if err != nil {
+			return AmazonProduct{}, err
+		}
+		if token != "" {
+			defer s.locker.ReleaseScrapeLock(asin, token)
+			return s.scrapeUnlessKnown(ctx, asin, marketplace, notBefore)
+		}
+
+		product, done, err := s.waitForLeader(ctx, asin, marketplace, notBefore)
+		if err != nil || done {
+			return product, err
+		}
+		//the leader gave up without a result, try to lead
+	}
+}
+
+//scrapeUnlessKnown scrapes the ASIN unless another scrape finished right before,
+//or found it missing or blocked
+func (s *webScraperServer) scrapeUnlessKnown(ctx context.Context, asin string, marketplace string, notBefore time.Time) (AmazonProduct, error) {
+	cachedProduct, err := s.cache.GetProduct(asin)
+	if err == nil && fromMarketplace(&cachedProduct, marketplace) && scrapedSince(&cachedProduct, notBefore) {
+		return cachedProduct, nil
+	}
+	if err = s.checkNegativeCache(asin, notBefore); err != nil {
+		return AmazonProduct{}, err
+	}
+	if s.opts.Queue != nil {
+		return s.queueScrape(ctx, asin, marketplace, notBefore)
+	}
+	return s.scrapeAndStore(ctx, asin, marketplace)
+}
+
+//waitForLeader polls the cache until the leader's product shows up,
+//done is false if the lock was released without a recent enough cached product from marketplace
+func (s *webScraperServer) waitForLeader(ctx context.Context, asin string, marketplace string, notBefore time.Time) (product AmazonProduct, done bool, err error) {
+	ticker := time.NewTicker(scrapeLockPoll)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return product, false, ctx.Err()
+		}
+
+		//check the lock first, a product cached by the leader is there once it lets go
+		locked, err := s.locker.ScrapeLocked(asin)
+		if err != nil {
+			return product, false, err
+		}
+		product, err = s.cache.GetProduct(asin)
+		if err != nil && err != ErrCacheMiss {
+			return product, false, err
+		}
+		if fromMarketplace(&product, marketplace) && scrapedSince(&product, notBefore) {
+			return product, true, nil
+		}
+		if !locked {
+			return AmazonProduct{}, false, nil
+		}
+	}
+}
+
+//scrapedSince tells whether product holds data scraped at or after notBefore
+func scrapedSince(product *AmazonProduct, notBefore time.Time) bool {
+	if product.Name == "" {
+		return false
+	}
+	if notBefore.IsZero() {
+		return true
+	}
+	createdAt, err := time.Parse(time.RFC3339Nano, product.CreatedAt)
+	return err == nil && !createdAt.Before(notBefore)
+}
+
+//fromMarketplace tells whether product was scraped from marketplace,
+//an empty marketplace is the default one
+func fromMarketplace(product *AmazonProduct, marketplace string) bool {
+	if marketplace == "" {
+		marketplace = defaultMarketplace
+	}
+	return productMarketplace(product) == marketplace
+}
+
+//staleProduct returns the stored copy of a product from marketplace if it is within MaxStaleness
+func (s *webScraperServer) staleProduct(asin string, marketplace string) (product AmazonProduct, ok bool) {
+	if s.opts.MaxStaleness <= 0 {
+		return
+	}
+	product, err := s.store.FetchProduct(asin)
+	if err != nil || !fromMarketplace(&product, marketplace) {
+		return
+	}
+	createdAt, err := time.Parse(time.RFC3339Nano, product.CreatedAt)
+	if err != nil {
+		return
+	}
+	return product, time.Since(createdAt) <= s.opts.MaxStaleness
+}
+
+//refreshInBackground re-scrapes a product without holding up the caller,
+//concurrent refreshes of the same ASIN are coalesced like any other scrape
+func (s *webScraperServer) refreshInBackground(asin string, marketplace string) {
+	go func() {
+		_, err := s.coalescedScrape(context.Background(), asin, marketplace, time.Time{})
+		if err != nil {
+			logger.Log.Warn("background refresh failed",
+				zap.String("asin", asin), zap.String("error", err.Error()))
+		}
+	}()
+}
+
+//checkNegativeCache fails with NotFound if the ASIN had no product page at or after notBefore,
+//and with Unavailable if it got too many robot checks in the current window
+func (s *webScraperServer) checkNegativeCache(asin string, notBefore time.Time) error {
+	if s.opts.NotFoundTTL > 0 {
+		since, err := s.cache.NotFoundSince(asin)
+		if err != nil {
+			return err
+		}
+		if !since.IsZero() && !since.Before(notBefore) {
+			return status.Errorf(codes.NotFound, "product %s not found", asin)
+		}
+	}
+	if s.opts.MaxRobotChecks > 0 {
+		count, err := s.cache.RobotCheckCount(asin)
+		if err != nil {
+			return err
+		}
+		if count >= int64(s.opts.MaxRobotChecks) {
+			return status.Errorf(codes.Unavailable, "product %s got %d robot checks, retry later", asin, count)
+		}
+	}
+	return nil
+}
+
+//markNotFound caches that the ASIN has no product page and returns the NotFound error for it
+func (s *webScraperServer) markNotFound(asin string) error {
+	if s.opts.NotFoundTTL > 0 {
+		if err := s.cache.MarkNotFound(asin, s.opts.NotFoundTTL); err != nil {
+			logger.Log.Warn("failed to cache not found product",
+				zap.String("asin", asin), zap.String("error", err.Error()))
+		}
+	}
+	return status.Errorf(codes.NotFound, "product %s not found", asin)
+}
+
+//recordRobotCheck counts a scrape of the ASIN that ended on a robot check page
+func (s *webScraperServer) recordRobotCheck(asin string) {
+	if s.opts.MaxRobotChecks <= 0 {
+		return
+	}
+	count, err := s.cache.RecordRobotCheck(asin, s.opts.RobotCheckWindow)
+	if err != nil {
+		logger.Log.Warn("failed to count robot check",
+			zap.String("asin", asin), zap.String("error", err.Error()))
+		return
+	}
+	if count == int64(s.opts.MaxRobotChecks) {
+		logger.Log.Warn("ASIN blocked after repeated robot checks",
+			zap.String("asin", asin), zap.Int64("robot_checks", count),
+			zap.Duration("window", s.opts.RobotCheckWindow))
+	}
+}
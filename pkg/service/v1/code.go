@@ -0,0 +1,80 @@
+package v1
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+)
+
+const (
+	//CodeTypeUPC is a 12 digit UPC-A barcode
+	CodeTypeUPC = "upc"
+	//CodeTypeEAN is an 8 or 13 digit EAN barcode
+	CodeTypeEAN = "ean"
+	//CodeTypeGTIN is a 14 digit GTIN barcode
+	CodeTypeGTIN = "gtin"
+)
+
+var (
+	//ErrMissingCode returns if a product code is missing
+	ErrMissingCode = errors.New("missing product code in request")
+	//ErrInvalidCode returns if a product code has a bad length, type or check digit
+	ErrInvalidCode = errors.New("invalid product code, expected a UPC, EAN or GTIN with a valid check digit")
+
+	codePattern = regexp.MustCompile(`^[0-9]+$`)
+)
+
+//NormalizeCode validates a UPC, EAN or GTIN and returns its type and digits.
+//codeType is optional, and is detected by length when empty
+func NormalizeCode(codeType string, code string) (normalizedType string, normalizedCode string, err error) {
+	code = strings.Replace(strings.TrimSpace(code), "-", "", -1)
+	code = strings.Replace(code, " ", "", -1)
+	if code == "" {
+		err = ErrMissingCode
+		return
+	}
+	if !codePattern.MatchString(code) || !validCheckDigit(code) {
+		err = ErrInvalidCode
+		return
+	}
+
+	detected := ""
+	switch len(code) {
+	case 8, 13:
+		detected = CodeTypeEAN
+	case 12:
+		detected = CodeTypeUPC
+	case 14:
+		detected = CodeTypeGTIN
+	default:
+		err = ErrInvalidCode
+		return
+	}
+
+	codeType = strings.ToLower(strings.TrimSpace(codeType))
+	switch codeType {
+	case "":
+		codeType = detected
+	case CodeTypeGTIN:
+		//every UPC and EAN is also a valid GTIN
+	case detected:
+	default:
+		err = ErrInvalidCode
+		return
+	}
+	return codeType, code, nil
+}
+
+//validCheckDigit verifies the GS1 modulo 10 check digit shared by UPC, EAN and GTIN
+func validCheckDigit(code string) bool {
+	sum := 0
+	//weights alternate 3, 1 starting from the digit left of the check digit
+	for i := len(code) - 2; i >= 0; i-- {
+		digit := int(code[i] - '0')
+		if (len(code)-2-i)%2 == 0 {
+			digit *= 3
+		}
+		sum += digit
+	}
+	return (10-sum%10)%10 == int(code[len(code)-1]-'0')
+}
@@ -0,0 +1,36 @@
+package v1
+
+import (
+	"context"
+	"net/url"
+	"strings"
+
+	"github.com/gocolly/colly"
+)
+
+//SearchASINByCode scrapes Amazon search results for a UPC, EAN or GTIN
+//and returns the ASIN of the first matching product
+func (s *Scraper) SearchASINByCode(ctx context.Context, marketplace string, code string) (asin string, res *colly.Response, err error) {
+	if marketplace == "" {
+		marketplace = defaultMarketplace
+	}
+	searchURL := "https://" + marketplace + "/s?k=" + url.QueryEscape(code)
+	res, err = s.Collect(ctx, marketplace, searchURL, func(c *colly.Collector) {
+		//drop whatever a previous attempt collected
+		asin = ""
+		//Search result cards carry the product ASIN as data-asin,
+		//sponsored and placeholder cards have it empty
+		c.OnHTML(".s-result-item[data-asin]",
+			func(e *colly.HTMLElement) {
+				candidate := strings.ToUpper(strings.TrimSpace(e.Attr("data-asin")))
+				if !asinPattern.MatchString(candidate) {
+					return
+				}
+				//keep the first result in page order
+				if asin == "" {
+					asin = candidate
+				}
+			})
+	})
+	return
+}
@@ -0,0 +1,190 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"time"
+
+	"github.com/golang/protobuf/ptypes"
+	v1 "github.com/rnidev/go-webscraper/pkg/api/v1"
+	"github.com/rnidev/go-webscraper/pkg/logger"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	//FieldName and the other field names identify product fields in history changes and search highlights
+	FieldName = "name"
+	//FieldCategories is the categories field of a product
+	FieldCategories = "categories"
+	//FieldRanks is the ranks field of a product
+	FieldRanks = "ranks"
+	//FieldDimensions is the dimensions field of a product
+	FieldDimensions = "dimensions"
+	//FieldBrand is the brand field of a product
+	FieldBrand = "brand"
+	//FieldFeatures is the features field of a product, the bullets about the item
+	FieldFeatures = "features"
+	//FieldPrice is the price field of a product
+	FieldPrice = "price"
+	//FieldAvailability is the availability field of a product
+	FieldAvailability = "availability"
+	//FieldMarketplace is the Amazon domain a product was scraped from
+	FieldMarketplace = "marketplace"
+)
+
+var (
+	//ErrNegativeInterval returns if a history interval is below zero
+	ErrNegativeInterval = errors.New("interval can't be negative")
+	//ErrInvalidTimeRange returns if a history range ends before it starts
+	ErrInvalidTimeRange = errors.New("from must not be after to")
+)
+
+//HistoryStore keeps every scrape of a product, a ProductStore may implement it
+type HistoryStore interface {
+	//AddSnapshot records a scraped product and drops its snapshots beyond retention
+	AddSnapshot(product *AmazonProduct, retention HistoryRetention) error
+	//Snapshots returns the snapshots of a product scraped within from and to, oldest first
+	Snapshots(asin string, from time.Time, to time.Time) ([]AmazonProduct, error)
+}
+
+//HistoryRetention limits how much history is kept per product
+type HistoryRetention struct {
+	//MaxAge drops snapshots older than it, 0 keeps them forever
+	MaxAge time.Duration
+	//MaxSnapshots keeps only the newest snapshots, 0 keeps all
+	MaxSnapshots int
+}
+
+//GetProductHistory returns the stored snapshots of a product and what changed between them
+func (s *webScraperServer) GetProductHistory(ctx context.Context, req *v1.GetProductHistoryRequest) (*v1.GetProductHistoryResponse, error) {
+	asin, _, err := NormalizeASIN(req.Asin)
+	if err != nil {
+		return &v1.GetProductHistoryResponse{}, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if req.Interval < 0 {
+		return &v1.GetProductHistoryResponse{}, status.Error(codes.InvalidArgument, ErrNegativeInterval.Error())
+	}
+	from, to := time.Unix(0, 0), time.Now()
+	if req.From != nil {
+		if from, err = ptypes.Timestamp(req.From); err != nil {
+			return &v1.GetProductHistoryResponse{}, status.Error(codes.InvalidArgument, err.Error())
+		}
+	}
+	if req.To != nil {
+		if to, err = ptypes.Timestamp(req.To); err != nil {
+			return &v1.GetProductHistoryResponse{}, status.Error(codes.InvalidArgument, err.Error())
+		}
+	}
+	if from.After(to) {
+		return &v1.GetProductHistoryResponse{}, status.Error(codes.InvalidArgument, ErrInvalidTimeRange.Error())
+	}
+	history, ok := s.store.(HistoryStore)
+	if !ok {
+		return &v1.GetProductHistoryResponse{}, status.Error(codes.Unimplemented, "product history isn't kept by this storage")
+	}
+
+	snapshots, err := history.Snapshots(asin, from, to)
+	if err != nil {
+		return &v1.GetProductHistoryResponse{}, err
+	}
+	snapshots = downsample(snapshots, time.Duration(req.Interval)*time.Second)
+
+	res := &v1.GetProductHistoryResponse{}
+	for index := range snapshots {
+		product, err := mapProduct(&snapshots[index])
+		if err != nil {
+			return &v1.GetProductHistoryResponse{}, err
+		}
+		snapshot := &v1.ProductSnapshot{Product: &product}
+		if index > 0 {
+			snapshot.Changes = diffProducts(&snapshots[index-1], &snapshots[index])
+		}
+		res.Snapshots = append(res.Snapshots, snapshot)
+	}
+	return res, nil
+}
+
+//recordSnapshot adds a scraped product to its history if the store keeps one,
+//a failure is only logged since the product itself was stored
+func (s *webScraperServer) recordSnapshot(product *AmazonProduct) {
+	history, ok := s.store.(HistoryStore)
+	if !ok {
+		return
+	}
+	if err := history.AddSnapshot(product, s.opts.History); err != nil {
+		logger.Log.Warn("failed to record product history",
+			zap.String("asin", product.Asin), zap.String("error", err.Error()))
+	}
+}
+
+//downsample keeps the last snapshot of every interval, counted from the first snapshot
+func downsample(snapshots []AmazonProduct, interval time.Duration) []AmazonProduct {
+	if interval <= 0 || len(snapshots) == 0 {
+		return snapshots
+	}
+	start, err := time.Parse(time.RFC3339Nano, snapshots[0].CreatedAt)
+	if err != nil {
+		return snapshots
+	}
+	var (
+		kept   []AmazonProduct
+		bucket int64 = -1
+	)
+	for _, snapshot := range snapshots {
+		createdAt, err := time.Parse(time.RFC3339Nano, snapshot.CreatedAt)
+		if err != nil {
+			continue
+		}
+		current := int64(createdAt.Sub(start) / interval)
+		if current == bucket {
+			kept[len(kept)-1] = snapshot
+			continue
+		}
+		bucket = current
+		kept = append(kept, snapshot)
+	}
+	return kept
+}
+
+//diffProducts lists the fields that changed from previous to next
+func diffProducts(previous *AmazonProduct, next *AmazonProduct) (changes []*v1.FieldChange) {
+	fields := []struct {
+		name          string
+		before, after []string
+	}{
+		{FieldName, []string{previous.Name}, []string{next.Name}},
+		{FieldBrand, nonEmpty(previous.Brand), nonEmpty(next.Brand)},
+		{FieldFeatures, previous.Features, next.Features},
+		{FieldCategories, previous.Categories, next.Categories},
+		{FieldRanks, previous.Ranks, next.Ranks},
+		{FieldDimensions, previous.Dimensions, next.Dimensions},
+		{FieldPrice, nonEmpty(previous.Price), nonEmpty(next.Price)},
+		{FieldAvailability, nonEmpty(previous.Availability), nonEmpty(next.Availability)},
+		{FieldMarketplace, []string{productMarketplace(previous)}, []string{productMarketplace(next)}},
+	}
+	for _, field := range fields {
+		//nil and empty both mean the field wasn't on the page
+		if len(field.before) == 0 && len(field.after) == 0 {
+			continue
+		}
+		if !reflect.DeepEqual(field.before, field.after) {
+			changes = append(changes, &v1.FieldChange{
+				Field:  field.name,
+				Before: field.before,
+				After:  field.after,
+			})
+		}
+	}
+	return
+}
+
+//nonEmpty lists value, or nothing if the field wasn't on the page
+func nonEmpty(value string) []string {
+	if value == "" {
+		return nil
+	}
+	return []string{value}
+}
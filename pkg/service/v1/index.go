@@ -0,0 +1,93 @@
+package v1
+
+import (
+	"context"
+	"time"
+
+	v1 "github.com/rnidev/go-webscraper/pkg/api/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	//defaultStaleAfter is how long ListStaleProducts waits for a product to be scraped again by default
+	defaultStaleAfter = 7 * 24 * time.Hour
+)
+
+//ProductIndex looks up stored products by category, rank and scrape time
+//without reading every product, a ProductStore may implement it
+type ProductIndex interface {
+	//TopRanked returns up to limit products ranked in category, best ranked first
+	TopRanked(category string, limit int) ([]AmazonProduct, error)
+	//ScrapedBefore returns up to limit products last scraped before t, least recently scraped first
+	ScrapedBefore(t time.Time, limit int) ([]AmazonProduct, error)
+	//ScanCategory is ProductLister.ScanProducts for the products in category
+	ScanCategory(category string, cursor string, count int) (products []AmazonProduct, next string, err error)
+}
+
+//ListTopRankedProducts returns the best ranked stored products in a category
+func (s *webScraperServer) ListTopRankedProducts(ctx context.Context, req *v1.ListTopRankedProductsRequest) (*v1.ListTopRankedProductsResponse, error) {
+	if req.Category == "" {
+		return &v1.ListTopRankedProductsResponse{}, status.Error(codes.InvalidArgument, "missing category")
+	}
+	index, ok := s.store.(ProductIndex)
+	if !ok {
+		return &v1.ListTopRankedProductsResponse{}, status.Error(codes.Unimplemented, "products aren't indexed by this storage")
+	}
+	products, err := index.TopRanked(req.Category, indexLimit(req.Limit))
+	if err != nil {
+		return &v1.ListTopRankedProductsResponse{}, err
+	}
+	res := &v1.ListTopRankedProductsResponse{}
+	if res.Products, err = mapProducts(products); err != nil {
+		return &v1.ListTopRankedProductsResponse{}, err
+	}
+	return res, nil
+}
+
+//ListStaleProducts returns stored products that weren't scraped again for a while
+func (s *webScraperServer) ListStaleProducts(ctx context.Context, req *v1.ListStaleProductsRequest) (*v1.ListStaleProductsResponse, error) {
+	if req.NotRefreshedFor < 0 {
+		return &v1.ListStaleProductsResponse{}, status.Error(codes.InvalidArgument, ErrNegativeInterval.Error())
+	}
+	index, ok := s.store.(ProductIndex)
+	if !ok {
+		return &v1.ListStaleProductsResponse{}, status.Error(codes.Unimplemented, "products aren't indexed by this storage")
+	}
+	staleAfter := defaultStaleAfter
+	if req.NotRefreshedFor > 0 {
+		staleAfter = time.Duration(req.NotRefreshedFor) * time.Second
+	}
+	products, err := index.ScrapedBefore(time.Now().Add(-staleAfter), indexLimit(req.Limit))
+	if err != nil {
+		return &v1.ListStaleProductsResponse{}, err
+	}
+	res := &v1.ListStaleProductsResponse{}
+	if res.Products, err = mapProducts(products); err != nil {
+		return &v1.ListStaleProductsResponse{}, err
+	}
+	return res, nil
+}
+
+//indexLimit applies the page size defaults of ListProducts to an index query
+func indexLimit(limit int32) int {
+	if limit <= 0 {
+		return defaultPageSize
+	}
+	if limit > maxPageSize {
+		return maxPageSize
+	}
+	return int(limit)
+}
+
+//mapProducts maps stored products to responses, keeping their order
+func mapProducts(products []AmazonProduct) (mapped []*v1.Product, err error) {
+	for index := range products {
+		product, err := mapProduct(&products[index])
+		if err != nil {
+			return nil, err
+		}
+		mapped = append(mapped, &product)
+	}
+	return
+}
@@ -0,0 +1,610 @@
+package v1
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis"
+	"github.com/golang/protobuf/ptypes"
+	tspb "github.com/golang/protobuf/ptypes/timestamp"
+	v1 "github.com/rnidev/go-webscraper/pkg/api/v1"
+	"github.com/rnidev/go-webscraper/pkg/logger"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+//Job statuses as they are stored
+const (
+	JobPending  = "pending"
+	JobRunning  = "running"
+	JobDone     = "done"
+	JobCanceled = "canceled"
+)
+
+const (
+	//MaxJobSize is how many products a job may have
+	MaxJobSize = 1000
+	//jobRetention is how long a finished job and its results are kept
+	jobRetention = 7 * 24 * time.Hour
+	//jobPoll is how often an idle runner looks for jobs
+	jobPoll = time.Second
+	//jobsKey orders job IDs by creation in unix microseconds, jobs submitted within a millisecond keep their order
+	jobsKey = "jobs"
+	//jobsDueKey orders unfinished job IDs by when a runner may claim them
+	jobsDueKey = "jobs:due"
+)
+
+var (
+	//jobLease is how long a runner owns a job without making progress,
+	//after it another runner resumes the job where it stopped
+	jobLease = 5 * time.Minute
+
+	//ErrJobNotFound returns if a job doesn't exist or expired
+	ErrJobNotFound = errors.New("job not found")
+	//ErrInvalidJobSize returns if a job has no products or more than MaxJobSize
+	ErrInvalidJobSize = errors.New("a job needs between 1 and 1000 ASINs")
+)
+
+//Job is a batch of products scraped in the background
+type Job struct {
+	ID           string
+	Status       string
+	Total        int
+	Succeeded    int
+	Failed       int
+	ForceRefresh bool
+	CreatedAt    time.Time
+	StartedAt    time.Time
+	FinishedAt   time.Time
+}
+
+//JobResult is the outcome of a product in a job, its product is in the ProductStore
+type JobResult struct {
+	Asin  string `json:"asin"`
+	Error string `json:"error,omitempty"`
+}
+
+//JobStore persists jobs so they survive restarts, a ProductCache may implement it
+type JobStore interface {
+	//CreateJob saves a pending job for the products in asins
+	CreateJob(job Job, asins []string) error
+	//Job returns ErrJobNotFound if the job doesn't exist
+	Job(id string) (Job, error)
+	//Jobs returns up to limit jobs after offset, newest first
+	Jobs(offset int, limit int) ([]Job, error)
+	//ClaimJobs leases up to limit unfinished jobs that no runner owns, and marks them running
+	ClaimJobs(now time.Time, limit int, lease time.Duration) ([]string, error)
+	//RenewJob extends the lease of a claimed job
+	RenewJob(id string, until time.Time) error
+	JobASINs(id string) ([]string, error)
+	//RecordJobResult saves the result of the product at index once and counts it
+	RecordJobResult(id string, index int, result JobResult) error
+	//JobResults returns up to limit results from offset, in the order of the products
+	JobResults(id string, offset int, limit int) ([]JobResult, error)
+	//EndJob sets a pending or running job to status, done or canceled, it returns ErrJobNotFound
+	//if the job doesn't exist and ended false if it was over already
+	EndJob(id string, status string) (ended bool, err error)
+}
+
+//startJobScript marks the job at KEYS[1] running at ARGV[1] unless it started already
+var startJobScript = redis.NewScript(`
+if redis.call("HGET", KEYS[1], "status") == "pending" then
+  redis.call("HMSET", KEYS[1], "status", "running", "started_at", ARGV[1])
+end
+return 0
+`)
+
+//recordJobResultScript saves the result ARGV[2] at index ARGV[1] of the results KEYS[2]
+//and counts it in field ARGV[3] of the job KEYS[1], unless it was saved before
+var recordJobResultScript = redis.NewScript(`
+if redis.call("HSETNX", KEYS[2], ARGV[1], ARGV[2]) == 1 then
+  redis.call("HINCRBY", KEYS[1], ARGV[3], 1)
+end
+return 0
+`)
+
+//endJobScript sets the pending or running job KEYS[1] to status ARGV[2] at ARGV[3],
+//drops its ID ARGV[1] from the due set KEYS[4] and expires the job, its ASINs KEYS[2]
+//and results KEYS[3] in ARGV[4] seconds. It returns -1 for a missing job, 0 for one over already
+var endJobScript = redis.NewScript(`
+local status = redis.call("HGET", KEYS[1], "status")
+if not status then
+  return -1
+end
+if status ~= "pending" and status ~= "running" then
+  return 0
+end
+redis.call("HMSET", KEYS[1], "status", ARGV[2], "finished_at", ARGV[3])
+redis.call("ZREM", KEYS[4], ARGV[1])
+for i = 1, 3 do
+  redis.call("EXPIRE", KEYS[i], ARGV[4])
+end
+return 1
+`)
+
+//CreateJob saves job:{id}, its ASINs in the list job:{id}:asins,
+//and adds it to jobs and to jobs:due so a runner picks it up
+func CreateJob(c *redis.Client, job Job, asins []string) error {
+	if job.ID == "" || len(asins) == 0 {
+		return ErrInvalidJobSize
+	}
+	values := make([]interface{}, len(asins))
+	for index, asin := range asins {
+		values[index] = asin
+	}
+	pipe := c.TxPipeline()
+	pipe.HMSet("job:"+job.ID, map[string]interface{}{
+		"status":        JobPending,
+		"total":         len(asins),
+		"succeeded":     0,
+		"failed":        0,
+		"force_refresh": strconv.FormatBool(job.ForceRefresh),
+		"created_at":    job.CreatedAt.In(time.UTC).Format(time.RFC3339Nano),
+	})
+	pipe.RPush("job:"+job.ID+":asins", values...)
+	pipe.ZAdd(jobsKey, redis.Z{Score: float64(job.CreatedAt.UnixNano() / int64(time.Microsecond)), Member: job.ID})
+	pipe.ZAdd(jobsDueKey, redis.Z{Score: float64(unixMilli(job.CreatedAt)), Member: job.ID})
+	_, err := pipe.Exec()
+	return err
+}
+
+//FetchJob reads job:{id}
+func FetchJob(c *redis.Client, id string) (Job, error) {
+	fields, err := c.HGetAll("job:" + id).Result()
+	if err != nil {
+		return Job{}, err
+	}
+	return decodeJob(id, fields)
+}
+
+//FetchJobs reads up to limit jobs from offset of jobs, newest first,
+//and forgets the IDs of jobs that expired
+func FetchJobs(c *redis.Client, offset int, limit int) (jobs []Job, err error) {
+	ids, err := c.ZRevRange(jobsKey, int64(offset), int64(offset+limit-1)).Result()
+	if err != nil {
+		return
+	}
+	pipe := c.Pipeline()
+	reads := make([]*redis.StringStringMapCmd, len(ids))
+	for index, id := range ids {
+		reads[index] = pipe.HGetAll("job:" + id)
+	}
+	if _, err = pipe.Exec(); err != nil {
+		return
+	}
+	for index, read := range reads {
+		job, err := decodeJob(ids[index], read.Val())
+		if err == ErrJobNotFound {
+			c.ZRem(jobsKey, ids[index])
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, job)
+	}
+	return
+}
+
+//ClaimJobs leases up to limit jobs of jobs:due until now+lease and marks them running
+func ClaimJobs(c *redis.Client, now time.Time, limit int, lease time.Duration) (ids []string, err error) {
+	claimed, err := claimDueScript.Run(c, []string{jobsDueKey},
+		unixMilli(now), limit, unixMilli(now.Add(lease))).Result()
+	if err != nil {
+		return
+	}
+	values, _ := claimed.([]interface{})
+	for _, value := range values {
+		id := value.(string)
+		err = startJobScript.Run(c, []string{"job:" + id}, now.In(time.UTC).Format(time.RFC3339Nano)).Err()
+		if err != nil {
+			return
+		}
+		ids = append(ids, id)
+	}
+	return
+}
+
+//RenewJob moves a claimed job in jobs:due to until, unless it ended
+func RenewJob(c *redis.Client, id string, until time.Time) error {
+	return c.ZAddXX(jobsDueKey, redis.Z{Score: float64(unixMilli(until)), Member: id}).Err()
+}
+
+//FetchJobASINs reads job:{id}:asins
+func FetchJobASINs(c *redis.Client, id string) ([]string, error) {
+	return c.LRange("job:"+id+":asins", 0, -1).Result()
+}
+
+//RecordJobResult saves a result at its index in the hash job:{id}:results
+//and counts it as succeeded or failed in job:{id}, only once per index
+func RecordJobResult(c *redis.Client, id string, index int, result JobResult) error {
+	encoded, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+	counter := "succeeded"
+	if result.Error != "" {
+		counter = "failed"
+	}
+	return recordJobResultScript.Run(c, []string{"job:" + id, "job:" + id + ":results"},
+		index, string(encoded), counter).Err()
+}
+
+//FetchJobResults reads up to limit results of job:{id}:results from offset, stopping at the first missing one
+func FetchJobResults(c *redis.Client, id string, offset int, limit int) (results []JobResult, err error) {
+	fields := make([]string, limit)
+	for index := range fields {
+		fields[index] = strconv.Itoa(offset + index)
+	}
+	values, err := c.HMGet("job:"+id+":results", fields...).Result()
+	if err != nil {
+		return
+	}
+	for _, value := range values {
+		encoded, ok := value.(string)
+		if !ok {
+			break
+		}
+		var result JobResult
+		if err = json.Unmarshal([]byte(encoded), &result); err != nil {
+			return
+		}
+		results = append(results, result)
+	}
+	return
+}
+
+//EndJob sets job:{id} done or canceled, and expires it with its ASINs and results after jobRetention
+func EndJob(c *redis.Client, id string, status string) (ended bool, err error) {
+	keys := []string{"job:" + id, "job:" + id + ":asins", "job:" + id + ":results", jobsDueKey}
+	result, err := endJobScript.Run(c, keys, id, status,
+		time.Now().In(time.UTC).Format(time.RFC3339Nano), int64(jobRetention/time.Second)).Int64()
+	if err != nil {
+		return
+	}
+	if result < 0 {
+		return false, ErrJobNotFound
+	}
+	return result == 1, nil
+}
+
+//decodeJob builds a job from the fields of its job:{id} hash
+func decodeJob(id string, fields map[string]string) (job Job, err error) {
+	if len(fields) == 0 {
+		err = ErrJobNotFound
+		return
+	}
+	job.ID = id
+	job.Status = fields["status"]
+	job.Total, _ = strconv.Atoi(fields["total"])
+	job.Succeeded, _ = strconv.Atoi(fields["succeeded"])
+	job.Failed, _ = strconv.Atoi(fields["failed"])
+	job.ForceRefresh, _ = strconv.ParseBool(fields["force_refresh"])
+	//times that weren't set yet stay zero
+	job.CreatedAt, _ = time.Parse(time.RFC3339Nano, fields["created_at"])
+	job.StartedAt, _ = time.Parse(time.RFC3339Nano, fields["started_at"])
+	job.FinishedAt, _ = time.Parse(time.RFC3339Nano, fields["finished_at"])
+	return
+}
+
+//newJobID returns a random job ID
+func newJobID() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+//JobRunner scrapes the products of jobs, any number of replicas may run one
+type JobRunner struct {
+	Store JobStore
+	//Workers is how many jobs this runner works on at once
+	Workers int
+
+	server v1.WebScraperServer
+}
+
+//NewJobRunner takes the server looking products up, the job store and how many jobs to run at once
+func NewJobRunner(server v1.WebScraperServer, store JobStore, workers int) *JobRunner {
+	return &JobRunner{Store: store, Workers: workers, server: server}
+}
+
+//Run works on jobs until ctx is done, a job interrupted by a restart is resumed once its lease expires
+func (r *JobRunner) Run(ctx context.Context) {
+	done := make(chan struct{})
+	for worker := 0; worker < r.Workers; worker++ {
+		go func() {
+			r.work(ctx)
+			done <- struct{}{}
+		}()
+	}
+	for worker := 0; worker < r.Workers; worker++ {
+		<-done
+	}
+}
+
+//work claims one job at a time and runs it
+func (r *JobRunner) work(ctx context.Context) {
+	for {
+		ids, err := r.Store.ClaimJobs(time.Now(), 1, jobLease)
+		if err != nil {
+			logger.Log.Warn("failed to claim jobs", zap.String("error", err.Error()))
+		}
+		if len(ids) == 0 {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(jobPoll):
+			}
+			continue
+		}
+		if err = r.runJob(ctx, ids[0]); err != nil && ctx.Err() == nil {
+			logger.Log.Warn("failed to run job", zap.String("job", ids[0]), zap.String("error", err.Error()))
+		}
+	}
+}
+
+//runJob looks up the products of a job from the first one without a result,
+//until the job is done or canceled
+func (r *JobRunner) runJob(ctx context.Context, id string) error {
+	asins, err := r.Store.JobASINs(id)
+	if err != nil {
+		return err
+	}
+	for {
+		job, err := r.Store.Job(id)
+		if err != nil {
+			return err
+		}
+		if job.Status != JobRunning {
+			return nil
+		}
+		//products are done in order, so the counts are where to resume
+		index := job.Succeeded + job.Failed
+		if index >= len(asins) {
+			_, err = r.Store.EndJob(id, JobDone)
+			return err
+		}
+		if err = r.Store.RenewJob(id, time.Now().Add(jobLease)); err != nil {
+			return err
+		}
+
+		result := JobResult{Asin: asins[index]}
+		if asin, _, err := NormalizeASIN(asins[index]); err == nil {
+			result.Asin = asin
+		}
+		_, err = r.server.GetProduct(ctx, &v1.GetProductRequest{Asin: asins[index], ForceRefresh: job.ForceRefresh})
+		if ctx.Err() != nil {
+			//shutting down, the product is looked up again once the job is resumed
+			return ctx.Err()
+		}
+		if err != nil {
+			result.Error = status.Convert(err).Message()
+		}
+		if err = r.Store.RecordJobResult(id, index, result); err != nil {
+			return err
+		}
+	}
+}
+
+//SubmitScrapeJob saves a job scraping a list of products and returns it right away
+func (s *webScraperServer) SubmitScrapeJob(ctx context.Context, req *v1.SubmitScrapeJobRequest) (*v1.SubmitScrapeJobResponse, error) {
+	if len(req.Asins) == 0 || len(req.Asins) > MaxJobSize {
+		return &v1.SubmitScrapeJobResponse{}, status.Error(codes.InvalidArgument, ErrInvalidJobSize.Error())
+	}
+	for index, asin := range req.Asins {
+		if _, _, err := NormalizeASIN(asin); err != nil {
+			return &v1.SubmitScrapeJobResponse{}, status.Errorf(codes.InvalidArgument, "asins[%d]: %v", index, err)
+		}
+	}
+	jobs, ok := s.cache.(JobStore)
+	if !ok {
+		return &v1.SubmitScrapeJobResponse{}, status.Error(codes.Unimplemented, "jobs aren't supported by this storage")
+	}
+	id, err := newJobID()
+	if err != nil {
+		return &v1.SubmitScrapeJobResponse{}, err
+	}
+	job := Job{
+		ID:           id,
+		Status:       JobPending,
+		Total:        len(req.Asins),
+		ForceRefresh: req.ForceRefresh,
+		CreatedAt:    time.Now(),
+	}
+	if err = jobs.CreateJob(job, req.Asins); err != nil {
+		return &v1.SubmitScrapeJobResponse{}, err
+	}
+	mapped, err := mapJob(&job)
+	if err != nil {
+		return &v1.SubmitScrapeJobResponse{}, err
+	}
+	return &v1.SubmitScrapeJobResponse{Job: mapped}, nil
+}
+
+//GetJob returns the progress of a job
+func (s *webScraperServer) GetJob(ctx context.Context, req *v1.GetJobRequest) (*v1.GetJobResponse, error) {
+	jobs, ok := s.cache.(JobStore)
+	if !ok {
+		return &v1.GetJobResponse{}, status.Error(codes.Unimplemented, "jobs aren't supported by this storage")
+	}
+	job, err := jobs.Job(req.Id)
+	if err == ErrJobNotFound {
+		return &v1.GetJobResponse{}, status.Error(codes.NotFound, err.Error())
+	}
+	if err != nil {
+		return &v1.GetJobResponse{}, err
+	}
+	mapped, err := mapJob(&job)
+	if err != nil {
+		return &v1.GetJobResponse{}, err
+	}
+	return &v1.GetJobResponse{Job: mapped}, nil
+}
+
+//ListJobs lists jobs a page at a time, newest first
+func (s *webScraperServer) ListJobs(ctx context.Context, req *v1.ListJobsRequest) (*v1.ListJobsResponse, error) {
+	jobs, ok := s.cache.(JobStore)
+	if !ok {
+		return &v1.ListJobsResponse{}, status.Error(codes.Unimplemented, "jobs aren't supported by this storage")
+	}
+	offset, pageSize, err := offsetPage(req.PageToken, req.PageSize)
+	if err != nil {
+		return &v1.ListJobsResponse{}, status.Error(codes.InvalidArgument, err.Error())
+	}
+	//one job past the page tells whether there is a next page
+	page, err := jobs.Jobs(offset, pageSize+1)
+	if err != nil {
+		return &v1.ListJobsResponse{}, err
+	}
+	res := &v1.ListJobsResponse{}
+	if len(page) > pageSize {
+		page = page[:pageSize]
+		res.NextPageToken = strconv.Itoa(offset + pageSize)
+	}
+	for index := range page {
+		mapped, err := mapJob(&page[index])
+		if err != nil {
+			return &v1.ListJobsResponse{}, err
+		}
+		res.Jobs = append(res.Jobs, mapped)
+	}
+	return res, nil
+}
+
+//CancelJob stops a pending or running job
+func (s *webScraperServer) CancelJob(ctx context.Context, req *v1.CancelJobRequest) (*v1.CancelJobResponse, error) {
+	jobs, ok := s.cache.(JobStore)
+	if !ok {
+		return &v1.CancelJobResponse{}, status.Error(codes.Unimplemented, "jobs aren't supported by this storage")
+	}
+	ended, err := jobs.EndJob(req.Id, JobCanceled)
+	if err == ErrJobNotFound {
+		return &v1.CancelJobResponse{}, status.Error(codes.NotFound, err.Error())
+	}
+	if err != nil {
+		return &v1.CancelJobResponse{}, err
+	}
+	job, err := jobs.Job(req.Id)
+	if err != nil {
+		return &v1.CancelJobResponse{}, err
+	}
+	if !ended {
+		return &v1.CancelJobResponse{}, status.Errorf(codes.FailedPrecondition, "job is %s already", job.Status)
+	}
+	mapped, err := mapJob(&job)
+	if err != nil {
+		return &v1.CancelJobResponse{}, err
+	}
+	return &v1.CancelJobResponse{Job: mapped}, nil
+}
+
+//GetJobResults returns the products and errors of a job a page at a time,
+//products are read from the store as they are now
+func (s *webScraperServer) GetJobResults(ctx context.Context, req *v1.GetJobResultsRequest) (*v1.GetJobResultsResponse, error) {
+	jobs, ok := s.cache.(JobStore)
+	if !ok {
+		return &v1.GetJobResultsResponse{}, status.Error(codes.Unimplemented, "jobs aren't supported by this storage")
+	}
+	offset, pageSize, err := offsetPage(req.PageToken, req.PageSize)
+	if err != nil {
+		return &v1.GetJobResultsResponse{}, status.Error(codes.InvalidArgument, err.Error())
+	}
+	job, err := jobs.Job(req.Id)
+	if err == ErrJobNotFound {
+		return &v1.GetJobResultsResponse{}, status.Error(codes.NotFound, err.Error())
+	}
+	if err != nil {
+		return &v1.GetJobResultsResponse{}, err
+	}
+	results, err := jobs.JobResults(job.ID, offset, pageSize)
+	if err != nil {
+		return &v1.GetJobResultsResponse{}, err
+	}
+
+	var asins []string
+	for _, result := range results {
+		if result.Error == "" {
+			asins = append(asins, result.Asin)
+		}
+	}
+	stored, err := s.store.FetchProducts(asins)
+	if err != nil {
+		return &v1.GetJobResultsResponse{}, err
+	}
+	products := make(map[string]*AmazonProduct, len(stored))
+	for index := range stored {
+		products[stored[index].Asin] = &stored[index]
+	}
+
+	res := &v1.GetJobResultsResponse{}
+	for _, result := range results {
+		jobResult := &v1.JobResult{Asin: result.Asin, Error: result.Error}
+		if product, ok := products[result.Asin]; ok && result.Error == "" {
+			mapped, err := mapProduct(product)
+			if err != nil {
+				return &v1.GetJobResultsResponse{}, err
+			}
+			jobResult.Product = &mapped
+		}
+		res.Results = append(res.Results, jobResult)
+	}
+	if offset+len(results) < job.Total && len(results) == pageSize {
+		res.NextPageToken = strconv.Itoa(offset + pageSize)
+	}
+	return res, nil
+}
+
+//offsetPage reads a page token holding an offset, and applies the page size defaults of ListProducts
+func offsetPage(pageToken string, size int32) (offset int, pageSize int, err error) {
+	if pageToken != "" {
+		if offset, err = strconv.Atoi(pageToken); err != nil || offset < 0 {
+			return 0, 0, ErrInvalidPageToken
+		}
+	}
+	return offset, indexLimit(size), nil
+}
+
+//mapJob maps a job to its response
+func mapJob(job *Job) (*v1.Job, error) {
+	mapped := &v1.Job{
+		Id:           job.ID,
+		Status:       v1.Job_Status(v1.Job_Status_value[jobStatusNames[job.Status]]),
+		Total:        int32(job.Total),
+		Succeeded:    int32(job.Succeeded),
+		Failed:       int32(job.Failed),
+		ForceRefresh: job.ForceRefresh,
+	}
+	var err error
+	for _, field := range []struct {
+		t      time.Time
+		mapped **tspb.Timestamp
+	}{
+		{job.CreatedAt, &mapped.CreatedAt},
+		{job.StartedAt, &mapped.StartedAt},
+		{job.FinishedAt, &mapped.FinishedAt},
+	} {
+		if field.t.IsZero() {
+			continue
+		}
+		if *field.mapped, err = ptypes.TimestampProto(field.t); err != nil {
+			return nil, err
+		}
+	}
+	return mapped, nil
+}
+
+//jobStatusNames maps stored job statuses to the names of v1.Job_Status
+var jobStatusNames = map[string]string{
+	JobPending:  "PENDING",
+	JobRunning:  "RUNNING",
+	JobDone:     "DONE",
+	JobCanceled: "CANCELED",
+}
@@ -0,0 +1,220 @@
+package v1
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang/protobuf/ptypes"
+	v1 "github.com/rnidev/go-webscraper/pkg/api/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	defaultPageSize = 50
+	maxPageSize     = 500
+	//maxScanPages bounds how many scans of a page size one request makes while looking
+	//for matches, so selective filters can't scan the whole store at once
+	maxScanPages = 10
+)
+
+var (
+	//ErrInvalidRankRange returns if a rank filter is negative or its range is empty
+	ErrInvalidRankRange = errors.New("min_rank and max_rank must be positive, and min_rank at most max_rank")
+	//ErrInvalidPageToken returns if a page token wasn't returned by ListProducts for the same filter
+	ErrInvalidPageToken = errors.New("invalid page token")
+)
+
+//ProductLister enumerates stored products, a ProductStore may implement it
+type ProductLister interface {
+	//ScanProducts returns stored products from cursor on, about count of them,
+	//and the cursor to continue from, empty once every product was returned.
+	//Like Redis SCAN, a product may be returned more than once
+	ScanProducts(cursor string, count int) (products []AmazonProduct, next string, err error)
+}
+
+//productFilter is what ListProductsRequest filters stored products by
+type productFilter struct {
+	category      string
+	minRank       int64
+	maxRank       int64
+	createdAfter  time.Time
+	createdBefore time.Time
+	marketplace   string
+}
+
+//ListProducts lists stored products a page at a time, filtered by the request
+func (s *webScraperServer) ListProducts(ctx context.Context, req *v1.ListProductsRequest) (*v1.ListProductsResponse, error) {
+	lister, ok := s.store.(ProductLister)
+	if !ok {
+		return &v1.ListProductsResponse{}, status.Error(codes.Unimplemented, "listing products isn't supported by this storage")
+	}
+	filter, err := productFilterFromRequest(req)
+	if err != nil {
+		return &v1.ListProductsResponse{}, status.Error(codes.InvalidArgument, err.Error())
+	}
+	scan := lister.ScanProducts
+	//an indexed category is walked directly instead of filtering every product
+	if index, ok := s.store.(ProductIndex); ok && filter.category != "" {
+		scan = func(cursor string, count int) ([]AmazonProduct, string, error) {
+			return index.ScanCategory(filter.category, cursor, count)
+		}
+	}
+	pageSize := int(req.PageSize)
+	if pageSize <= 0 {
+		pageSize = defaultPageSize
+	}
+	if pageSize > maxPageSize {
+		pageSize = maxPageSize
+	}
+
+	page, err := decodeListPageToken(req.PageToken, filter)
+	if err != nil {
+		return &v1.ListProductsResponse{}, status.Error(codes.InvalidArgument, err.Error())
+	}
+	res := &v1.ListProductsResponse{}
+	cursor, skip := page.Cursor, page.Skip
+	//scans are counted rather than products, SCAN may return empty batches before it is done
+	for scans := 0; scans < maxScanPages; scans++ {
+		products, next, err := scan(cursor, pageSize)
+		if err == ErrInvalidPageToken {
+			return &v1.ListProductsResponse{}, status.Error(codes.InvalidArgument, err.Error())
+		}
+		if err != nil {
+			return &v1.ListProductsResponse{}, err
+		}
+		//the previous page ended within this batch, its first skip products were listed
+		for index := skip; index < len(products); index++ {
+			if len(res.Products) == pageSize {
+				res.NextPageToken = encodeListPageToken(listPageToken{Cursor: cursor, Skip: index}, filter)
+				return res, nil
+			}
+			if !filter.match(&products[index]) {
+				continue
+			}
+			product, err := mapProduct(&products[index])
+			if err != nil {
+				return &v1.ListProductsResponse{}, err
+			}
+			res.Products = append(res.Products, &product)
+		}
+		skip = 0
+		cursor = next
+		if cursor == "" {
+			return res, nil
+		}
+		if len(res.Products) >= pageSize {
+			break
+		}
+		if err = ctx.Err(); err != nil {
+			return &v1.ListProductsResponse{}, err
+		}
+	}
+	res.NextPageToken = encodeListPageToken(listPageToken{Cursor: cursor}, filter)
+	return res, nil
+}
+
+//listPageToken is where the next page of ListProducts starts: the scan cursor of a batch,
+//how many products of that batch were listed already, and the filter the pages are for
+type listPageToken struct {
+	Cursor string `json:"c,omitempty"`
+	Skip   int    `json:"s,omitempty"`
+	Filter string `json:"f"`
+}
+
+//encodeListPageToken encodes page as an opaque next_page_token bound to filter
+func encodeListPageToken(page listPageToken, filter productFilter) string {
+	page.Filter = filter.fingerprint()
+	encoded, _ := json.Marshal(page)
+	return base64.RawURLEncoding.EncodeToString(encoded)
+}
+
+//decodeListPageToken decodes a next_page_token, it returns ErrInvalidPageToken
+//if the token is malformed or was returned for another filter
+func decodeListPageToken(token string, filter productFilter) (page listPageToken, err error) {
+	if token == "" {
+		return
+	}
+	encoded, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil || json.Unmarshal(encoded, &page) != nil || page.Skip < 0 || page.Filter != filter.fingerprint() {
+		return listPageToken{}, ErrInvalidPageToken
+	}
+	return page, nil
+}
+
+func productFilterFromRequest(req *v1.ListProductsRequest) (filter productFilter, err error) {
+	if req.MinRank < 0 || req.MaxRank < 0 || (req.MaxRank > 0 && req.MinRank > req.MaxRank) {
+		err = ErrInvalidRankRange
+		return
+	}
+	filter.category = strings.TrimSpace(req.Category)
+	filter.minRank = req.MinRank
+	filter.maxRank = req.MaxRank
+	if req.CreatedAfter != nil {
+		if filter.createdAfter, err = ptypes.Timestamp(req.CreatedAfter); err != nil {
+			return
+		}
+	}
+	if req.CreatedBefore != nil {
+		if filter.createdBefore, err = ptypes.Timestamp(req.CreatedBefore); err != nil {
+			return
+		}
+	}
+	if req.Marketplace != "" {
+		if filter.marketplace, err = NormalizeMarketplace(req.Marketplace); err != nil {
+			return
+		}
+	}
+	return
+}
+
+//fingerprint identifies the filter in page tokens
+func (f productFilter) fingerprint() string {
+	hash := fnv.New64a()
+	fmt.Fprintf(hash, "%s\x00%d\x00%d\x00%s\x00%s\x00%s", strings.ToLower(f.category), f.minRank, f.maxRank,
+		f.createdAfter.Format(time.RFC3339Nano), f.createdBefore.Format(time.RFC3339Nano), f.marketplace)
+	return strconv.FormatUint(hash.Sum64(), 36)
+}
+
+//match tells whether product passes every filter that is set
+func (f productFilter) match(product *AmazonProduct) bool {
+	if f.marketplace != "" {
+		if productMarketplace(product) != f.marketplace {
+			return false
+		}
+	}
+	if f.category != "" && !hasCategory(product, f.category) {
+		return false
+	}
+	if f.minRank > 0 || f.maxRank > 0 {
+		rank, ok := mainRank(product)
+		if !ok || rank < f.minRank || (f.maxRank > 0 && rank > f.maxRank) {
+			return false
+		}
+	}
+	if !f.createdAfter.IsZero() || !f.createdBefore.IsZero() {
+		createdAt, err := time.Parse(time.RFC3339Nano, product.CreatedAt)
+		if err != nil || createdAt.Before(f.createdAfter) ||
+			(!f.createdBefore.IsZero() && !createdAt.Before(f.createdBefore)) {
+			return false
+		}
+	}
+	return true
+}
+
+//hasCategory tells whether category is one of the product's categories, ignoring case
+func hasCategory(product *AmazonProduct, category string) bool {
+	for _, productCategory := range product.Categories {
+		if strings.EqualFold(productCategory, category) {
+			return true
+		}
+	}
+	return false
+}
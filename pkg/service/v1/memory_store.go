@@ -0,0 +1,482 @@
+package v1
+
+import (
+	"container/list"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+//MemoryStore is a ProductStore and ProductCache kept in process, for running without Redis.
+//Stored products and cache entries are each evicted least recently used first
+//once there are Capacity of them, and everything is lost on restart
+type MemoryStore struct {
+	mu       sync.Mutex
+	products *lru
+	cache    *lru
+	//watchlist isn't evicted, it is as long as the caller makes it
+	watchlist map[string]WatchEntry
+}
+
+//robotChecks is the counter behind RecordRobotCheck
+type robotChecks struct {
+	count int64
+}
+
+//ttlState is what AdaptTTL keeps per product
+type ttlState struct {
+	factor    float64
+	unchanged int
+}
+
+//memorySnapshot is a product in its history
+type memorySnapshot struct {
+	createdAt time.Time
+	product   AmazonProduct
+}
+
+//NewMemoryStore takes how many products and cache entries to keep, 0 keeps everything
+func NewMemoryStore(capacity int) *MemoryStore {
+	return &MemoryStore{
+		products:  newLRU(capacity),
+		cache:     newLRU(capacity),
+		watchlist: make(map[string]WatchEntry),
+	}
+}
+
+//StoreProduct saves a copy of product
+func (m *MemoryStore) StoreProduct(product *AmazonProduct) error {
+	if product.Asin == "" {
+		return ErrMissingASIN
+	}
+	if product.Name == "" {
+		return errMissingProductName
+	}
+	if len(product.Categories) == 0 {
+		return errMissingProductCategory
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.products.set("product:"+product.Asin, cloneProduct(product), 0)
+	return nil
+}
+
+//FetchProduct returns the stored copy of a product
+func (m *MemoryStore) FetchProduct(asin string) (product AmazonProduct, err error) {
+	if asin == "" {
+		err = ErrMissingASIN
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	value, ok := m.products.get("product:" + asin)
+	if !ok {
+		err = ErrProductNotFound
+		return
+	}
+	return *cloneProduct(value.(*AmazonProduct)), nil
+}
+
+//FetchProducts returns the stored copies of products
+func (m *MemoryStore) FetchProducts(asins []string) (products []AmazonProduct, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, asin := range asins {
+		if value, ok := m.products.get("product:" + asin); ok {
+			products = append(products, *cloneProduct(value.(*AmazonProduct)))
+		}
+	}
+	return
+}
+
+//ScanProducts returns stored products ordered by ASIN, after the ASIN in cursor
+func (m *MemoryStore) ScanProducts(cursor string, count int) (products []AmazonProduct, next string, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var asins []string
+	//reading items directly keeps a listing from changing what is evicted next
+	for key := range m.products.items {
+		if strings.HasPrefix(key, "product:") && key[len("product:"):] > cursor {
+			asins = append(asins, key[len("product:"):])
+		}
+	}
+	sort.Strings(asins)
+	if len(asins) > count {
+		asins = asins[:count]
+		next = asins[count-1]
+	}
+	for _, asin := range asins {
+		entry := m.products.items["product:"+asin].Value.(*lruEntry)
+		products = append(products, *cloneProduct(entry.value.(*AmazonProduct)))
+	}
+	return
+}
+
+//DeleteProduct drops a product with its history and everything cached about it
+func (m *MemoryStore) DeleteProduct(asin string) (deleted bool, err error) {
+	if asin == "" {
+		err = ErrMissingASIN
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	_, deleted = m.products.get("product:" + asin)
+	m.products.del("product:" + asin)
+	m.products.del("history:" + asin)
+	for _, key := range []string{"cacheProduct:", "notFound:", "robotCheck:", "cacheTTL:"} {
+		m.cache.del(key + asin)
+	}
+	return
+}
+
+//StoreCodeMapping saves the ASIN a product code resolves to
+func (m *MemoryStore) StoreCodeMapping(codeType string, code string, asin string) error {
+	if asin == "" {
+		return ErrMissingASIN
+	}
+	if code == "" {
+		return ErrMissingCode
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.products.set("code:"+codeType+":"+code, asin, 0)
+	return nil
+}
+
+//FetchASINByCode returns the ASIN a product code resolved to
+func (m *MemoryStore) FetchASINByCode(codeType string, code string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	value, ok := m.products.get("code:" + codeType + ":" + code)
+	if !ok {
+		return "", nil
+	}
+	return value.(string), nil
+}
+
+//GetProduct returns a cached copy of a product
+func (m *MemoryStore) GetProduct(asin string) (product AmazonProduct, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	value, ok := m.cache.get("cacheProduct:" + asin)
+	if !ok {
+		err = ErrCacheMiss
+		return
+	}
+	return *cloneProduct(value.(*AmazonProduct)), nil
+}
+
+//AddProduct caches a copy of product for ttl
+func (m *MemoryStore) AddProduct(product *AmazonProduct, ttl time.Duration) error {
+	if product.Name == "" {
+		return errEmptyProduct
+	}
+	if ttl == 0 {
+		return errMissingTTLDuration
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.cache.set("cacheProduct:"+product.Asin, cloneProduct(product), ttl)
+	return nil
+}
+
+//InvalidateProducts drops the cached copies of asins, it returns how many were cached
+func (m *MemoryStore) InvalidateProducts(asins []string) (invalidated int64, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, asin := range asins {
+		if _, ok := m.cache.get("cacheProduct:" + asin); ok {
+			invalidated++
+		}
+		m.cache.del("cacheProduct:" + asin)
+	}
+	return
+}
+
+//InvalidatePattern drops the cached copies of ASINs matching the glob pattern
+func (m *MemoryStore) InvalidatePattern(pattern string) (invalidated int64, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var keys []string
+	for key := range m.cache.items {
+		if !strings.HasPrefix(key, "cacheProduct:") {
+			continue
+		}
+		matched, err := path.Match(pattern, key[len("cacheProduct:"):])
+		if err != nil {
+			return 0, err
+		}
+		if matched {
+			keys = append(keys, key)
+		}
+	}
+	for _, key := range keys {
+		if _, ok := m.cache.get(key); ok {
+			invalidated++
+		}
+		m.cache.del(key)
+	}
+	return
+}
+
+//MarkNotFound remembers for ttl that an ASIN has no product page
+func (m *MemoryStore) MarkNotFound(asin string, ttl time.Duration) error {
+	if asin == "" {
+		return ErrMissingASIN
+	}
+	if ttl == 0 {
+		return errMissingTTLDuration
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.cache.set("notFound:"+asin, time.Now(), ttl)
+	return nil
+}
+
+//NotFoundSince returns when an ASIN was marked as not found
+func (m *MemoryStore) NotFoundSince(asin string) (since time.Time, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if value, ok := m.cache.get("notFound:" + asin); ok {
+		since = value.(time.Time)
+	}
+	return
+}
+
+//RecordRobotCheck counts a robot check, the count is reset window after the first one
+func (m *MemoryStore) RecordRobotCheck(asin string, window time.Duration) (int64, error) {
+	if asin == "" {
+		return 0, ErrMissingASIN
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if value, ok := m.cache.get("robotCheck:" + asin); ok {
+		checks := value.(*robotChecks)
+		checks.count++
+		return checks.count, nil
+	}
+	m.cache.set("robotCheck:"+asin, &robotChecks{count: 1}, window)
+	return 1, nil
+}
+
+//RobotCheckCount returns the robot checks of an ASIN in its current window
+func (m *MemoryStore) RobotCheckCount(asin string) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if value, ok := m.cache.get("robotCheck:" + asin); ok {
+		return value.(*robotChecks).count, nil
+	}
+	return 0, nil
+}
+
+//ClearNegativeCache forgets that an ASIN was not found or blocked
+func (m *MemoryStore) ClearNegativeCache(asin string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.cache.del("notFound:" + asin)
+	m.cache.del("robotCheck:" + asin)
+	return nil
+}
+
+//TTLState returns the adaptive TTL state of a product
+func (m *MemoryStore) TTLState(asin string) (factor float64, unchanged int, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if value, ok := m.cache.get("cacheTTL:" + asin); ok {
+		state := value.(ttlState)
+		return state.factor, state.unchanged, nil
+	}
+	return
+}
+
+//SetTTLState saves the adaptive TTL state of a product
+func (m *MemoryStore) SetTTLState(asin string, factor float64, unchanged int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.cache.set("cacheTTL:"+asin, ttlState{factor: factor, unchanged: unchanged}, 0)
+	return nil
+}
+
+//AddSnapshot adds a copy of product to its history and drops snapshots beyond retention
+func (m *MemoryStore) AddSnapshot(product *AmazonProduct, retention HistoryRetention) error {
+	if product.Asin == "" {
+		return ErrMissingASIN
+	}
+	createdAt, err := time.Parse(time.RFC3339Nano, product.CreatedAt)
+	if err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var snapshots []memorySnapshot
+	if value, ok := m.products.get("history:" + product.Asin); ok {
+		snapshots = value.([]memorySnapshot)
+	}
+	//keep the history ordered by scrape time
+	index := sort.Search(len(snapshots), func(i int) bool {
+		return snapshots[i].createdAt.After(createdAt)
+	})
+	snapshots = append(snapshots, memorySnapshot{})
+	copy(snapshots[index+1:], snapshots[index:])
+	snapshots[index] = memorySnapshot{createdAt: createdAt, product: *cloneProduct(product)}
+
+	if retention.MaxAge > 0 {
+		cutoff := time.Now().Add(-retention.MaxAge)
+		expired := sort.Search(len(snapshots), func(i int) bool {
+			return !snapshots[i].createdAt.Before(cutoff)
+		})
+		snapshots = snapshots[expired:]
+	}
+	if retention.MaxSnapshots > 0 && len(snapshots) > retention.MaxSnapshots {
+		snapshots = snapshots[len(snapshots)-retention.MaxSnapshots:]
+	}
+	m.products.set("history:"+product.Asin, append([]memorySnapshot(nil), snapshots...), 0)
+	return nil
+}
+
+//Snapshots returns copies of the snapshots of a product scraped within from and to
+func (m *MemoryStore) Snapshots(asin string, from time.Time, to time.Time) (snapshots []AmazonProduct, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	value, ok := m.products.get("history:" + asin)
+	if !ok {
+		return
+	}
+	for _, snapshot := range value.([]memorySnapshot) {
+		if snapshot.createdAt.Before(from) || snapshot.createdAt.After(to) {
+			continue
+		}
+		snapshots = append(snapshots, *cloneProduct(&snapshot.product))
+	}
+	return
+}
+
+//cloneProduct copies product, so callers can't change what is stored
+func cloneProduct(product *AmazonProduct) *AmazonProduct {
+	clone := *product
+	clone.Features = append([]string(nil), product.Features...)
+	clone.Categories = append([]string(nil), product.Categories...)
+	clone.Ranks = append([]string(nil), product.Ranks...)
+	clone.Dimensions = append([]string(nil), product.Dimensions...)
+	return &clone
+}
+
+//Watch saves a watchlist entry
+func (m *MemoryStore) Watch(entry WatchEntry) error {
+	if entry.Asin == "" {
+		return ErrMissingASIN
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.watchlist[entry.Asin] = entry
+	return nil
+}
+
+//Unwatch deletes a watchlist entry
+func (m *MemoryStore) Unwatch(asin string) (removed bool, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	_, removed = m.watchlist[asin]
+	delete(m.watchlist, asin)
+	return
+}
+
+//ClaimDue leases up to limit watched products due at now until now+lease, earliest first
+func (m *MemoryStore) ClaimDue(now time.Time, limit int, lease time.Duration) (entries []WatchEntry, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, entry := range m.watchlist {
+		if !entry.NextRefresh.After(now) {
+			entries = append(entries, entry)
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].NextRefresh.Before(entries[j].NextRefresh)
+	})
+	if len(entries) > limit {
+		entries = entries[:limit]
+	}
+	for index := range entries {
+		entries[index].NextRefresh = now.Add(lease)
+		m.watchlist[entries[index].Asin] = entries[index]
+	}
+	return
+}
+
+//Reschedule moves a watched product to its next refresh
+func (m *MemoryStore) Reschedule(asin string, next time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if entry, ok := m.watchlist[asin]; ok {
+		entry.NextRefresh = next
+		m.watchlist[asin] = entry
+	}
+	return nil
+}
+
+type lruEntry struct {
+	key       string
+	value     interface{}
+	expiresAt time.Time
+}
+
+//lru is a least recently used map whose entries may expire, it isn't safe for concurrent use
+type lru struct {
+	capacity int
+	ll       *list.List
+	items    map[string]*list.Element
+}
+
+func newLRU(capacity int) *lru {
+	return &lru{
+		capacity: capacity,
+		ll:       list.New(),
+		items:    make(map[string]*list.Element),
+	}
+}
+
+func (l *lru) get(key string) (interface{}, bool) {
+	element, ok := l.items[key]
+	if !ok {
+		return nil, false
+	}
+	entry := element.Value.(*lruEntry)
+	if !entry.expiresAt.IsZero() && !time.Now().Before(entry.expiresAt) {
+		l.remove(element)
+		return nil, false
+	}
+	l.ll.MoveToFront(element)
+	return entry.value, true
+}
+
+//set stores value under key, a ttl of 0 never expires
+func (l *lru) set(key string, value interface{}, ttl time.Duration) {
+	var expiresAt time.Time
+	if ttl > 0 {
+		expiresAt = time.Now().Add(ttl)
+	}
+	if element, ok := l.items[key]; ok {
+		entry := element.Value.(*lruEntry)
+		entry.value = value
+		entry.expiresAt = expiresAt
+		l.ll.MoveToFront(element)
+		return
+	}
+	l.items[key] = l.ll.PushFront(&lruEntry{key: key, value: value, expiresAt: expiresAt})
+	if l.capacity > 0 && l.ll.Len() > l.capacity {
+		l.remove(l.ll.Back())
+	}
+}
+
+func (l *lru) del(key string) {
+	if element, ok := l.items[key]; ok {
+		l.remove(element)
+	}
+}
+
+func (l *lru) remove(element *list.Element) {
+	l.ll.Remove(element)
+	delete(l.items, element.Value.(*lruEntry).key)
+}
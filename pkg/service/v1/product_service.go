@@ -1,20 +1,44 @@
 package v1
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
-	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/go-redis/redis"
 )
 
+const (
+	//ProductEncodingVersion is the version of product:{ASIN} hashes StoreProduct writes.
+	//Hashes without a version field were written with ";" joined lists,
+	//version 2 hashes have JSON lists but aren't in the index:* keys yet
+	ProductEncodingVersion = 3
+	//jsonListsVersion is the first version with lists encoded as JSON arrays
+	jsonListsVersion = 2
+
+	//scrapedIndexKey orders ASINs by when they were last scraped, in unix milliseconds
+	scrapedIndexKey = "index:scraped"
+	//watchlistDueKey orders watched ASINs by their next refresh, in unix milliseconds
+	watchlistDueKey = "watchlist:due"
+	//maxTxAttempts bounds how often a transaction on a product:{ASIN} hash is retried
+	//while other replicas keep changing the hash
+	maxTxAttempts = 10
+)
+
 var (
 	errMissingProductName     = errors.New("missing product name")
 	errMissingProductCategory = errors.New("missing product category")
 	errMissingTTLDuration     = errors.New("missing TTL duration")
 	errEmptyProduct           = errors.New("product is empty")
+
+	//ErrProductNotFound returns if a product was never stored
+	ErrProductNotFound = errors.New("product not found")
+	//ErrTxConflict returns if a product changed during every one of maxTxAttempts transactions
+	ErrTxConflict = errors.New("product kept changing during the transaction, try again")
 )
 
 //StoreProduct save AmazonProduct into Redis with key product:{ASIN}
@@ -29,65 +53,508 @@ func StoreProduct(c *redis.Client, scrapedProduct *AmazonProduct) (err error) {
 	if len(scrapedProduct.Categories) == 0 {
 		return errMissingProductCategory
 	}
+	product["version"] = ProductEncodingVersion
 	product["asin"] = scrapedProduct.Asin
 	product["name"] = scrapedProduct.Name
-	product["categories"] = strings.Join(scrapedProduct.Categories, ";")
-	product["ranks"] = strings.Join(scrapedProduct.Ranks, ";")
-	product["dimensions"] = strings.Join(scrapedProduct.Dimensions, ";")
+	product["brand"] = scrapedProduct.Brand
+	//lists are JSON arrays, so values containing ";" survive
+	for field, values := range map[string][]string{
+		"features":   scrapedProduct.Features,
+		"categories": scrapedProduct.Categories,
+		"ranks":      scrapedProduct.Ranks,
+		"dimensions": scrapedProduct.Dimensions,
+	} {
+		if product[field], err = encodeProductList(values); err != nil {
+			return err
+		}
+	}
+	product["price"] = scrapedProduct.Price
+	product["availability"] = scrapedProduct.Availability
+	product["marketplace"] = scrapedProduct.Marketplace
 	product["created_at"] = scrapedProduct.CreatedAt
 
-	err = c.HMSet("product:"+scrapedProduct.Asin, product).Err()
-	if err != nil {
+	//the hash and its indexes change in one transaction,
+	//retried if another replica stores the product meanwhile
+	key := "product:" + scrapedProduct.Asin
+	return watchProduct(c, key, func(tx *redis.Tx) error {
+		fields, err := tx.HGetAll(key).Result()
+		if err != nil {
+			return err
+		}
+		var previous productIndexes
+		if stored, err := decodeProduct(scrapedProduct.Asin, fields); err == nil {
+			previous = indexesOf(&stored)
+		}
+		_, err = tx.Pipelined(func(pipe redis.Pipeliner) error {
+			pipe.HMSet(key, product)
+			writeIndexes(pipe, scrapedProduct.Asin, previous, indexesOf(scrapedProduct))
+			return nil
+		})
 		return err
+	})
+}
+
+//watchProduct runs fn in a transaction watching key, it retries while another replica
+//changes key meanwhile and returns ErrTxConflict after maxTxAttempts
+func watchProduct(c *redis.Client, key string, fn func(tx *redis.Tx) error) error {
+	for attempt := 1; attempt <= maxTxAttempts; attempt++ {
+		if err := c.Watch(fn, key); err != redis.TxFailedErr {
+			return err
+		}
 	}
-	return nil
+	return ErrTxConflict
 }
 
-//FetchProduct get AmazonProduct from Redis
-//Todo: Fetch product will be used in Phase 2 for limited caching
-func FetchProduct(c *redis.Client, asin string) (product AmazonProduct, err error) {
+//productIndexes are the index:* entries of a product
+type productIndexes struct {
+	//categories are the lower cased categories of the product
+	categories map[string]bool
+	//ranks are the ranks of the product by lower cased category it ranks in
+	ranks map[string]int64
+	//scrapedAt is a zero time if the product has no valid created_at
+	scrapedAt time.Time
+}
+
+//indexesOf returns the index:* entries of product
+func indexesOf(product *AmazonProduct) (indexes productIndexes) {
+	indexes.categories = make(map[string]bool)
+	for _, category := range product.Categories {
+		if category = strings.ToLower(strings.TrimSpace(category)); category != "" {
+			indexes.categories[category] = true
+		}
+	}
+	indexes.ranks = make(map[string]int64)
+	for _, rankInfo := range product.Ranks {
+		rank, category, ok := ParseRank(rankInfo)
+		if !ok {
+			continue
+		}
+		category = strings.ToLower(category)
+		//the main rank comes first, a sub rank in the same category doesn't replace it
+		if _, ok := indexes.ranks[category]; !ok {
+			indexes.ranks[category] = rank
+		}
+	}
+	indexes.scrapedAt, _ = time.Parse(time.RFC3339Nano, product.CreatedAt)
+	return
+}
+
+//writeIndexes queues the updates that move a product from its previous index:* entries to its current ones,
+//in the index:category:{category} sets of ASINs, the index:rank:{category} ASINs scored by rank and index:scraped
+func writeIndexes(pipe redis.Pipeliner, asin string, previous productIndexes, current productIndexes) {
+	for category := range previous.categories {
+		if !current.categories[category] {
+			pipe.SRem(categoryIndexKey(category), asin)
+		}
+	}
+	for category := range previous.ranks {
+		if _, ok := current.ranks[category]; !ok {
+			pipe.ZRem(rankIndexKey(category), asin)
+		}
+	}
+	for category := range current.categories {
+		pipe.SAdd(categoryIndexKey(category), asin)
+	}
+	for category, rank := range current.ranks {
+		pipe.ZAdd(rankIndexKey(category), redis.Z{Score: float64(rank), Member: asin})
+	}
+	if !current.scrapedAt.IsZero() {
+		pipe.ZAdd(scrapedIndexKey, redis.Z{Score: float64(unixMilli(current.scrapedAt)), Member: asin})
+	} else if !previous.scrapedAt.IsZero() {
+		pipe.ZRem(scrapedIndexKey, asin)
+	}
+}
+
+//DeleteProduct deletes product:{ASIN} and its index:* entries in one transaction,
+//along with everything cached and kept about the ASIN: cacheProduct:{ASIN}, history:{ASIN},
+//notFound:{ASIN}, robotCheck:{ASIN} and cacheTTL:{ASIN}. deleted is false if the product wasn't stored
+func DeleteProduct(c *redis.Client, asin string) (deleted bool, err error) {
 	if asin == "" {
 		err = ErrMissingASIN
 		return
 	}
-	//Check if product key exists
-	exist, err := c.Exists("product:" + asin).Result()
+	key := "product:" + asin
+	err = watchProduct(c, key, func(tx *redis.Tx) error {
+		fields, err := tx.HGetAll(key).Result()
+		if err != nil {
+			return err
+		}
+		var previous productIndexes
+		if stored, err := decodeProduct(asin, fields); err == nil {
+			previous = indexesOf(&stored)
+		}
+		_, err = tx.Pipelined(func(pipe redis.Pipeliner) error {
+			pipe.Del(key, "cacheProduct:"+asin, "history:"+asin, "notFound:"+asin, "robotCheck:"+asin, "cacheTTL:"+asin)
+			writeIndexes(pipe, asin, previous, productIndexes{})
+			return nil
+		})
+		deleted = len(fields) > 0
+		return err
+	})
+	return
+}
+
+//InvalidateCachedProducts deletes cacheProduct:{ASIN} of asins, it returns how many were cached
+func InvalidateCachedProducts(c *redis.Client, asins []string) (int64, error) {
+	if len(asins) == 0 {
+		return 0, nil
+	}
+	keys := make([]string, len(asins))
+	for index, asin := range asins {
+		keys[index] = "cacheProduct:" + asin
+	}
+	return c.Del(keys...).Result()
+}
+
+//InvalidateCachedPattern deletes the cacheProduct:{ASIN} keys whose ASIN matches the glob pattern,
+//found with SCAN, it returns how many were deleted
+func InvalidateCachedPattern(c *redis.Client, pattern string) (invalidated int64, err error) {
+	var cursor uint64
+	for {
+		var keys []string
+		keys, cursor, err = c.Scan(cursor, "cacheProduct:"+pattern, 100).Result()
+		if err != nil {
+			return
+		}
+		if len(keys) > 0 {
+			deleted, err := c.Del(keys...).Result()
+			if err != nil {
+				return invalidated, err
+			}
+			invalidated += deleted
+		}
+		if cursor == 0 {
+			return
+		}
+	}
+}
+
+//claimDueScript leases up to ARGV[2] ASINs of the sorted set KEYS[1] due at ARGV[1]
+//by moving them to ARGV[3], so no other replica claims them meanwhile. It returns the claimed ASINs
+var claimDueScript = redis.NewScript(`
+local due = redis.call("ZRANGEBYSCORE", KEYS[1], "-inf", ARGV[1], "LIMIT", 0, tonumber(ARGV[2]))
+for _, asin in ipairs(due) do
+  redis.call("ZADD", KEYS[1], ARGV[3], asin)
+end
+return due
+`)
+
+//WatchProduct saves a watchlist entry in watchlist:{ASIN} and schedules it
+//in the sorted set watchlist:due, scored by its next refresh in unix milliseconds
+func WatchProduct(c *redis.Client, entry WatchEntry) error {
+	if entry.Asin == "" {
+		return ErrMissingASIN
+	}
+	pipe := c.TxPipeline()
+	pipe.HMSet("watchlist:"+entry.Asin, map[string]interface{}{
+		"interval":    int64(entry.Interval / time.Second),
+		"marketplace": entry.Marketplace,
+	})
+	pipe.ZAdd(watchlistDueKey, redis.Z{Score: float64(unixMilli(entry.NextRefresh)), Member: entry.Asin})
+	_, err := pipe.Exec()
+	return err
+}
+
+//UnwatchProduct deletes watchlist:{ASIN} and its schedule, removed is false if it wasn't watched
+func UnwatchProduct(c *redis.Client, asin string) (removed bool, err error) {
+	pipe := c.TxPipeline()
+	pipe.Del("watchlist:" + asin)
+	unscheduled := pipe.ZRem(watchlistDueKey, asin)
+	if _, err = pipe.Exec(); err != nil {
+		return
+	}
+	return unscheduled.Val() > 0, nil
+}
+
+//ClaimDueProducts leases up to limit watched products due at now until now+lease,
+//every replica may claim at once, but a product is only claimed once per lease
+func ClaimDueProducts(c *redis.Client, now time.Time, limit int, lease time.Duration) (entries []WatchEntry, err error) {
+	claimed, err := claimDueScript.Run(c, []string{watchlistDueKey},
+		unixMilli(now), limit, unixMilli(now.Add(lease))).Result()
 	if err != nil {
 		return
-	} else if exist == 0 {
-		err = fmt.Errorf("product key: product:%s doesn't exist", asin)
+	}
+	asins, _ := claimed.([]interface{})
+	pipe := c.Pipeline()
+	reads := make([]*redis.StringStringMapCmd, len(asins))
+	for index, asin := range asins {
+		reads[index] = pipe.HGetAll("watchlist:" + asin.(string))
+	}
+	if _, err = pipe.Exec(); err != nil {
 		return
 	}
+	for index, read := range reads {
+		fields := read.Val()
+		seconds, _ := strconv.ParseInt(fields["interval"], 10, 64)
+		if seconds <= 0 {
+			//the entry was removed after its schedule was read
+			c.ZRem(watchlistDueKey, asins[index])
+			continue
+		}
+		entries = append(entries, WatchEntry{
+			Asin:        asins[index].(string),
+			Marketplace: fields["marketplace"],
+			Interval:    time.Duration(seconds) * time.Second,
+			NextRefresh: now.Add(lease),
+		})
+	}
+	return
+}
+
+//RescheduleProduct moves a watched product in watchlist:due to its next refresh,
+//unless it stopped being watched
+func RescheduleProduct(c *redis.Client, asin string, next time.Time) error {
+	return c.ZAddXX(watchlistDueKey, redis.Z{Score: float64(unixMilli(next)), Member: asin}).Err()
+}
+
+func categoryIndexKey(category string) string {
+	return "index:category:" + strings.ToLower(strings.TrimSpace(category))
+}
+
+func rankIndexKey(category string) string {
+	return "index:rank:" + strings.ToLower(strings.TrimSpace(category))
+}
+
+//TopRankedProducts returns up to limit products ranked in category, best ranked first
+func TopRankedProducts(c *redis.Client, category string, limit int) ([]AmazonProduct, error) {
+	asins, err := c.ZRange(rankIndexKey(category), 0, int64(limit)-1).Result()
+	if err != nil {
+		return nil, err
+	}
+	return FetchProducts(c, asins)
+}
 
-	name, err := c.HGet("product:"+asin, "name").Result()
+//ProductsScrapedBefore returns up to limit products last scraped before t,
+//least recently scraped first
+func ProductsScrapedBefore(c *redis.Client, t time.Time, limit int) ([]AmazonProduct, error) {
+	asins, err := c.ZRangeByScore(scrapedIndexKey, redis.ZRangeBy{
+		Min:   "-inf",
+		Max:   "(" + strconv.FormatInt(unixMilli(t), 10),
+		Count: int64(limit),
+	}).Result()
 	if err != nil {
+		return nil, err
+	}
+	return FetchProducts(c, asins)
+}
+
+//ScanCategory walks index:category:{category} with SSCAN from cursor, "" starts over,
+//and returns the products found with the cursor to continue from, "" once done
+func ScanCategory(c *redis.Client, category string, cursor string, count int) (products []AmazonProduct, next string, err error) {
+	var position uint64
+	if cursor != "" {
+		if position, err = strconv.ParseUint(cursor, 10, 64); err != nil {
+			err = ErrInvalidPageToken
+			return
+		}
+	}
+	asins, position, err := c.SScan(categoryIndexKey(category), position, "", int64(count)).Result()
+	if err != nil {
+		return
+	}
+	if products, err = FetchProducts(c, asins); err != nil {
+		return
+	}
+	if position != 0 {
+		next = strconv.FormatUint(position, 10)
+	}
+	return
+}
+
+//FetchProduct get AmazonProduct from Redis in a single read,
+//it serves stale products while their expired cache is refreshed.
+//It returns ErrProductNotFound if the product was never stored
+func FetchProduct(c *redis.Client, asin string) (product AmazonProduct, err error) {
+	if asin == "" {
+		err = ErrMissingASIN
 		return
 	}
-	categories, err := c.HGet("product:"+asin, "categories").Result()
+	fields, err := c.HGetAll("product:" + asin).Result()
 	if err != nil {
 		return
 	}
-	createdAt, err := c.HGet("product:"+asin, "created_at").Result()
+	return decodeProduct(asin, fields)
+}
+
+//FetchProducts gets many products from Redis in one round trip,
+//products that were never stored are left out
+func FetchProducts(c *redis.Client, asins []string) (products []AmazonProduct, err error) {
+	pipe := c.Pipeline()
+	reads := make([]*redis.StringStringMapCmd, len(asins))
+	for index, asin := range asins {
+		reads[index] = pipe.HGetAll("product:" + asin)
+	}
+	if _, err = pipe.Exec(); err != nil {
+		return
+	}
+	for index, read := range reads {
+		product, err := decodeProduct(asins[index], read.Val())
+		if err == ErrProductNotFound {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		products = append(products, product)
+	}
+	return
+}
+
+//ScanProducts walks product:{ASIN} keys with SCAN from cursor, "" starts over,
+//and returns the products found with the cursor to continue from, "" once done
+func ScanProducts(c *redis.Client, cursor string, count int) (products []AmazonProduct, next string, err error) {
+	var position uint64
+	if cursor != "" {
+		if position, err = strconv.ParseUint(cursor, 10, 64); err != nil {
+			err = ErrInvalidPageToken
+			return
+		}
+	}
+	keys, position, err := c.Scan(position, "product:*", int64(count)).Result()
 	if err != nil {
 		return
 	}
+	asins := make([]string, len(keys))
+	for index, key := range keys {
+		asins[index] = strings.TrimPrefix(key, "product:")
+	}
+	if products, err = FetchProducts(c, asins); err != nil {
+		return
+	}
+	if position != 0 {
+		next = strconv.FormatUint(position, 10)
+	}
+	return
+}
+
+//decodeProduct builds a product from the fields of its product:{ASIN} hash
+func decodeProduct(asin string, fields map[string]string) (product AmazonProduct, err error) {
+	if len(fields) == 0 {
+		err = ErrProductNotFound
+		return
+	}
+	//hashes stored before versioning have no version field
+	version, _ := strconv.Atoi(fields["version"])
 
 	product.Asin = asin
-	product.Name = name
-	product.Categories = strings.Split(categories, ";")
-	product.CreatedAt = createdAt
+	product.Name = fields["name"]
+	product.CreatedAt = fields["created_at"]
+	//marketplace is missing for products stored before it was tracked
+	product.Marketplace = fields["marketplace"]
+	//brand, features, price and availability are missing for products stored before they were scraped
+	product.Brand = fields["brand"]
+	product.Price = fields["price"]
+	product.Availability = fields["availability"]
+	if product.Categories, err = decodeProductList(version, fields["categories"]); err != nil {
+		return
+	}
+	//ranks, dimensions and features are not required, and they can be nil
+	if product.Ranks, err = decodeProductList(version, fields["ranks"]); err != nil {
+		return
+	}
+	if product.Dimensions, err = decodeProductList(version, fields["dimensions"]); err != nil {
+		return
+	}
+	product.Features, err = decodeProductList(version, fields["features"])
+	return
+}
 
-	//ranks are not required, and it can be nil
-	ranks, _ := c.HGet("product:"+asin, "ranks").Result()
-	if len(ranks) > 0 {
-		product.Ranks = strings.Split(ranks, ";")
+//MigrateProducts rewrites product:{ASIN} hashes older than ProductEncodingVersion in place
+//and adds them to the index:* keys, it returns how many were rewritten. Hashes changed while they are migrated are retried,
+//up to maxTxAttempts times before it returns ErrTxConflict
+func MigrateProducts(c *redis.Client) (migrated int, err error) {
+	var cursor uint64
+	for {
+		var keys []string
+		keys, cursor, err = c.Scan(cursor, "product:*", 100).Result()
+		if err != nil {
+			return
+		}
+		for _, key := range keys {
+			var rewritten bool
+			for attempt := 1; ; attempt++ {
+				rewritten, err = migrateProduct(c, key)
+				if err != redis.TxFailedErr {
+					break
+				}
+				if attempt == maxTxAttempts {
+					err = ErrTxConflict
+					break
+				}
+			}
+			if err != nil {
+				return
+			}
+			if rewritten {
+				migrated++
+			}
+		}
+		if cursor == 0 {
+			return
+		}
 	}
-	//dimensions are not required, and it can be nil
-	dimensions, _ := c.HGet("product:"+asin, "dimensions").Result()
-	if len(dimensions) > 0 {
-		product.Dimensions = strings.Split(dimensions, ";")
+}
+
+//migrateProduct rewrites a single hash unless it already has the current version,
+//the rewrite fails with redis.TxFailedErr if the hash changed meanwhile
+func migrateProduct(c *redis.Client, key string) (rewritten bool, err error) {
+	err = c.Watch(func(tx *redis.Tx) error {
+		fields, err := tx.HGetAll(key).Result()
+		if err != nil || len(fields) == 0 {
+			return err
+		}
+		version, _ := strconv.Atoi(fields["version"])
+		if version >= ProductEncodingVersion {
+			return nil
+		}
+		update := map[string]interface{}{"version": ProductEncodingVersion}
+		for _, field := range []string{"categories", "ranks", "dimensions"} {
+			values, err := decodeProductList(version, fields[field])
+			if err != nil {
+				return err
+			}
+			if update[field], err = encodeProductList(values); err != nil {
+				return err
+			}
+		}
+		asin := strings.TrimPrefix(key, "product:")
+		product, err := decodeProduct(asin, fields)
+		if err != nil {
+			return err
+		}
+		_, err = tx.Pipelined(func(pipe redis.Pipeliner) error {
+			pipe.HMSet(key, update)
+			writeIndexes(pipe, asin, productIndexes{}, indexesOf(&product))
+			return nil
+		})
+		rewritten = err == nil
+		return err
+	}, key)
+	return
+}
+
+//encodeProductList encodes a product list field as a JSON array
+func encodeProductList(values []string) (string, error) {
+	if len(values) == 0 {
+		return "[]", nil
 	}
+	encoded, err := json.Marshal(values)
+	return string(encoded), err
+}
 
+//decodeProductList decodes a product list field written with version,
+//an empty list is nil like a product that was never stored
+func decodeProductList(version int, value string) (values []string, err error) {
+	if value == "" {
+		return nil, nil
+	}
+	if version < jsonListsVersion {
+		return strings.Split(value, ";"), nil
+	}
+	err = json.Unmarshal([]byte(value), &values)
+	if len(values) == 0 {
+		values = nil
+	}
 	return
 }
 
@@ -123,3 +590,167 @@ func AddProductToCache(c *redis.Client, scrapedProduct *AmazonProduct, duration
 	err = c.Set("cacheProduct:"+scrapedProduct.Asin, string(productJSON), duration).Err()
 	return
 }
+
+//StoreCodeMapping save the ASIN a product code resolves to with key code:{type}:{value}
+func StoreCodeMapping(c *redis.Client, codeType string, code string, asin string) (err error) {
+	if asin == "" {
+		return ErrMissingASIN
+	}
+	if code == "" {
+		return ErrMissingCode
+	}
+	//barcodes don't move between products, so the mapping never expires
+	return c.Set("code:"+codeType+":"+code, asin, 0).Err()
+}
+
+//FetchASINByCode get the ASIN mapped to a product code,
+//returns redis.Nil if the code hasn't been resolved yet
+func FetchASINByCode(c *redis.Client, codeType string, code string) (asin string, err error) {
+	return c.Get("code:" + codeType + ":" + code).Result()
+}
+
+//releaseLockScript deletes a lock only if it still holds the caller's token,
+//so a lock that expired and was taken over isn't released by its old owner
+var releaseLockScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+  return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+//AcquireScrapeLock tries to become the one replica scraping an ASIN, with key lock:scrape:{ASIN}.
+//It returns the lock token, or an empty token if another replica holds the lock
+func AcquireScrapeLock(c *redis.Client, asin string, ttl time.Duration) (token string, err error) {
+	if asin == "" {
+		err = ErrMissingASIN
+		return
+	}
+	buf := make([]byte, 16)
+	if _, err = rand.Read(buf); err != nil {
+		return
+	}
+	ok, err := c.SetNX("lock:scrape:"+asin, hex.EncodeToString(buf), ttl).Result()
+	if err != nil || !ok {
+		return
+	}
+	token = hex.EncodeToString(buf)
+	return
+}
+
+//ReleaseScrapeLock releases lock:scrape:{ASIN} if it is still held with token
+func ReleaseScrapeLock(c *redis.Client, asin string, token string) (err error) {
+	return releaseLockScript.Run(c, []string{"lock:scrape:" + asin}, token).Err()
+}
+
+//ScrapeLocked tells whether any replica holds lock:scrape:{ASIN}
+func ScrapeLocked(c *redis.Client, asin string) (locked bool, err error) {
+	exist, err := c.Exists("lock:scrape:" + asin).Result()
+	return exist > 0, err
+}
+
+//MarkProductNotFound remembers that an ASIN has no product page, with key notFound:{ASIN}.
+//The value is when it was scraped, so callers asking for fresher data can look again
+func MarkProductNotFound(c *redis.Client, asin string, ttl time.Duration) (err error) {
+	if asin == "" {
+		return ErrMissingASIN
+	}
+	if ttl == 0 {
+		return errMissingTTLDuration
+	}
+	return c.Set("notFound:"+asin, time.Now().UnixNano(), ttl).Err()
+}
+
+//ProductNotFoundSince returns when an ASIN was last found to have no product page,
+//a zero time if it isn't marked
+func ProductNotFoundSince(c *redis.Client, asin string) (since time.Time, err error) {
+	nanos, err := c.Get("notFound:" + asin).Int64()
+	if err == redis.Nil {
+		return since, nil
+	}
+	if err != nil {
+		return
+	}
+	return time.Unix(0, nanos), nil
+}
+
+//RecordRobotCheck counts a robot check page served for an ASIN with key robotCheck:{ASIN},
+//the count is reset window after the first robot check
+func RecordRobotCheck(c *redis.Client, asin string, window time.Duration) (count int64, err error) {
+	if asin == "" {
+		err = ErrMissingASIN
+		return
+	}
+	count, err = c.Incr("robotCheck:" + asin).Result()
+	if err != nil {
+		return
+	}
+	if count == 1 {
+		err = c.Expire("robotCheck:"+asin, window).Err()
+	}
+	return
+}
+
+//RobotCheckCount returns how many robot checks an ASIN got in its current window
+func RobotCheckCount(c *redis.Client, asin string) (count int64, err error) {
+	count, err = c.Get("robotCheck:" + asin).Int64()
+	if err == redis.Nil {
+		return 0, nil
+	}
+	return
+}
+
+//ClearNegativeCache forgets that an ASIN was not found or blocked, once it was scraped fine
+func ClearNegativeCache(c *redis.Client, asin string) (err error) {
+	return c.Del("notFound:"+asin, "robotCheck:"+asin).Err()
+}
+
+//AddProductSnapshot adds product to the sorted set history:{ASIN} scored by scrape time in milliseconds,
+//then drops snapshots beyond retention
+func AddProductSnapshot(c *redis.Client, product *AmazonProduct, retention HistoryRetention) (err error) {
+	if product.Asin == "" {
+		return ErrMissingASIN
+	}
+	createdAt, err := time.Parse(time.RFC3339Nano, product.CreatedAt)
+	if err != nil {
+		return
+	}
+	snapshot, err := json.Marshal(product)
+	if err != nil {
+		return
+	}
+	key := "history:" + product.Asin
+	pipe := c.TxPipeline()
+	pipe.ZAdd(key, redis.Z{Score: float64(unixMilli(createdAt)), Member: string(snapshot)})
+	if retention.MaxAge > 0 {
+		cutoff := unixMilli(time.Now().Add(-retention.MaxAge))
+		pipe.ZRemRangeByScore(key, "-inf", "("+strconv.FormatInt(cutoff, 10))
+	}
+	if retention.MaxSnapshots > 0 {
+		pipe.ZRemRangeByRank(key, 0, int64(-retention.MaxSnapshots-1))
+	}
+	_, err = pipe.Exec()
+	return
+}
+
+//FetchProductSnapshots returns the snapshots in history:{ASIN} scraped within from and to, oldest first
+func FetchProductSnapshots(c *redis.Client, asin string, from time.Time, to time.Time) (snapshots []AmazonProduct, err error) {
+	members, err := c.ZRangeByScore("history:"+asin, redis.ZRangeBy{
+		Min: strconv.FormatInt(unixMilli(from), 10),
+		Max: strconv.FormatInt(unixMilli(to), 10),
+	}).Result()
+	if err != nil {
+		return
+	}
+	for _, member := range members {
+		var snapshot AmazonProduct
+		if err = json.Unmarshal([]byte(member), &snapshot); err != nil {
+			return
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+	return
+}
+
+func unixMilli(t time.Time) int64 {
+	return t.UnixNano() / int64(time.Millisecond)
+}
@@ -0,0 +1,493 @@
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis"
+	"github.com/rnidev/go-webscraper/pkg/logger"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	//DefaultMaxDeliveries is how often a scrape is handed to workers before it is dead-lettered
+	DefaultMaxDeliveries = 5
+	//DefaultRetryAfter is how long a failed or abandoned scrape stays pending before another delivery
+	DefaultRetryAfter = 30 * time.Second
+	//scrapeStream is the queue of scrapes, read by the consumer group scrapeGroup
+	scrapeStream = "queue:scrape"
+	//scrapeDeadStream keeps scrapes that failed every delivery, for inspection
+	scrapeDeadStream = "queue:scrape:dead"
+	scrapeGroup      = "scrapers"
+	//queueMaxLen roughly bounds both streams, acknowledged entries aren't needed anymore
+	queueMaxLen = 100000
+	//queueResultTTL is how long the outcome of a scrape is kept for the API replica waiting on it
+	queueResultTTL = 10 * time.Minute
+	//queueBlock is how long a worker waits for new scrapes in one read
+	queueBlock = 5 * time.Second
+)
+
+var (
+	//queueSlack is how long a queued scrape may wait on top of its retries,
+	//for a worker to read it and for the scrape budget
+	queueSlack = 30 * time.Second
+
+	//ErrInvalidScrapeTask returns if a queued entry has no valid ASIN or product code, or isn't for an Amazon marketplace
+	ErrInvalidScrapeTask = errors.New("queued scrape has no valid ASIN, product code or marketplace")
+)
+
+//ScrapeTask is a scrape handed from the API tier to workers
+type ScrapeTask struct {
+	Asin        string
+	Marketplace string
+	//NotBefore is how recent a cached product must be to make the scrape unnecessary
+	NotBefore time.Time
+	//CodeType and Code make the task a lookup of the ASIN a UPC, EAN or GTIN resolves to, instead of a scrape of Asin
+	CodeType string
+	Code     string
+}
+
+//Values encodes the task as stream entry fields
+func (t ScrapeTask) Values() map[string]interface{} {
+	values := map[string]interface{}{
+		"asin":        t.Asin,
+		"marketplace": t.Marketplace,
+		"enqueued_at": time.Now().In(time.UTC).Format(time.RFC3339Nano),
+	}
+	if !t.NotBefore.IsZero() {
+		values["not_before"] = t.NotBefore.In(time.UTC).Format(time.RFC3339Nano)
+	}
+	if t.Code != "" {
+		values["code_type"] = t.CodeType
+		values["code"] = t.Code
+	}
+	return values
+}
+
+//ParseScrapeTask decodes the fields of a stream entry written by ScrapeTask.Values
+func ParseScrapeTask(values map[string]interface{}) (task ScrapeTask, err error) {
+	//the stream may be written by anyone with access to Redis, only scrape Amazon
+	marketplace, _ := values["marketplace"].(string)
+	if task.Marketplace, err = NormalizeMarketplace(marketplace); err != nil {
+		return ScrapeTask{}, ErrInvalidScrapeTask
+	}
+	if code, ok := values["code"].(string); ok {
+		codeType, _ := values["code_type"].(string)
+		if task.CodeType, task.Code, err = NormalizeCode(codeType, code); err != nil {
+			return ScrapeTask{}, ErrInvalidScrapeTask
+		}
+		return task, nil
+	}
+	asin, _ := values["asin"].(string)
+	if task.Asin, _, err = NormalizeASIN(asin); err != nil {
+		return ScrapeTask{}, ErrInvalidScrapeTask
+	}
+	if notBefore, ok := values["not_before"].(string); ok {
+		if task.NotBefore, err = time.Parse(time.RFC3339Nano, notBefore); err != nil {
+			return ScrapeTask{}, err
+		}
+	}
+	return
+}
+
+//TaskResult is how a queued scrape ended, Code is codes.OK once the product is cached,
+//or once the product code is resolved to Asin
+type TaskResult struct {
+	Code    codes.Code `json:"code"`
+	Message string     `json:"message,omitempty"`
+	Asin    string     `json:"asin,omitempty"`
+}
+
+//Err returns the gRPC error of a failed scrape, or nil
+func (r TaskResult) Err() error {
+	if r.Code == codes.OK {
+		return nil
+	}
+	return status.Error(r.Code, r.Message)
+}
+
+//ScrapeQueue hands scrapes to workers instead of scraping in process
+type ScrapeQueue interface {
+	//Enqueue queues a scrape and returns its ID
+	Enqueue(task ScrapeTask) (id string, err error)
+	//Result returns how a scrape ended, ok is false until a worker finished it
+	Result(id string) (result TaskResult, ok bool, err error)
+}
+
+//RedisScrapeQueue is a Redis Streams queue of scrapes, workers share it through a consumer group,
+//a scrape is acknowledged once it succeeded or failed for good, anything else is delivered again
+//after RetryAfter and moved to the dead-letter stream after MaxDeliveries
+type RedisScrapeQueue struct {
+	client        *redis.Client
+	MaxDeliveries int64
+	RetryAfter    time.Duration
+}
+
+//NewRedisScrapeQueue takes the server's redis client and returns a queue with the default retries
+func NewRedisScrapeQueue(client *redis.Client) *RedisScrapeQueue {
+	return &RedisScrapeQueue{client: client, MaxDeliveries: DefaultMaxDeliveries, RetryAfter: DefaultRetryAfter}
+}
+
+//Enqueue adds a scrape to queue:scrape
+func (q *RedisScrapeQueue) Enqueue(task ScrapeTask) (string, error) {
+	return q.client.XAdd(&redis.XAddArgs{
+		Stream:       scrapeStream,
+		MaxLenApprox: queueMaxLen,
+		Values:       task.Values(),
+	}).Result()
+}
+
+//Result reads queue:scrape:result:{id}
+func (q *RedisScrapeQueue) Result(id string) (result TaskResult, ok bool, err error) {
+	encoded, err := q.client.Get("queue:scrape:result:" + id).Bytes()
+	if err == redis.Nil {
+		return result, false, nil
+	}
+	if err != nil {
+		return
+	}
+	err = json.Unmarshal(encoded, &result)
+	return result, err == nil, err
+}
+
+//CreateGroup creates the consumer group, and the stream if it's missing
+func (q *RedisScrapeQueue) CreateGroup() error {
+	err := q.client.XGroupCreateMkStream(scrapeStream, scrapeGroup, "0").Err()
+	if err != nil && strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		return nil
+	}
+	return err
+}
+
+//queueEntry is a delivered stream entry
+type queueEntry struct {
+	redis.XMessage
+	//deliveries counts this one
+	deliveries int64
+}
+
+//read waits up to queueBlock for scrapes no consumer got yet,
+//or up to RetryAfter if that is shorter, so failed scrapes aren't retried late
+func (q *RedisScrapeQueue) read(consumer string, count int64) ([]queueEntry, error) {
+	block := queueBlock
+	if q.RetryAfter >= time.Millisecond && q.RetryAfter < block {
+		block = q.RetryAfter
+	}
+	streams, err := q.client.XReadGroup(&redis.XReadGroupArgs{
+		Group:    scrapeGroup,
+		Consumer: consumer,
+		Streams:  []string{scrapeStream, ">"},
+		Count:    count,
+		Block:    block,
+	}).Result()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var scrapes []queueEntry
+	for _, stream := range streams {
+		for _, message := range stream.Messages {
+			scrapes = append(scrapes, queueEntry{XMessage: message, deliveries: 1})
+		}
+	}
+	return scrapes, nil
+}
+
+//reclaim claims up to count scrapes pending for RetryAfter, because they failed or their worker died.
+//Scrapes that were delivered MaxDeliveries times already are dead-lettered instead.
+//It pages through every pending scrape, idle ones may wait behind any number in flight
+func (q *RedisScrapeQueue) reclaim(consumer string, count int64) ([]queueEntry, error) {
+	var scrapes []queueEntry
+	for start := "-"; int64(len(scrapes)) < count; {
+		pending, err := q.client.XPendingExt(&redis.XPendingExtArgs{
+			Stream: scrapeStream,
+			Group:  scrapeGroup,
+			Start:  start,
+			End:    "+",
+			Count:  10 * count,
+		}).Result()
+		//nothing is pending
+		if err == redis.Nil {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if scrapes, err = q.claimIdle(consumer, pending, scrapes, count); err != nil {
+			return nil, err
+		}
+		if int64(len(pending)) < 10*count {
+			break
+		}
+		//the range is inclusive, the next page starts right after the last ID seen
+		start = nextStreamID(pending[len(pending)-1].Id)
+	}
+	return scrapes, nil
+}
+
+//claimIdle claims the pending scrapes idle for RetryAfter and adds them to scrapes, up to count
+func (q *RedisScrapeQueue) claimIdle(consumer string, pending []redis.XPendingExt, scrapes []queueEntry, count int64) ([]queueEntry, error) {
+	for _, entry := range pending {
+		if int64(len(scrapes)) == count {
+			break
+		}
+		if entry.Idle < q.RetryAfter {
+			continue
+		}
+		claimed, err := q.client.XClaim(&redis.XClaimArgs{
+			Stream:   scrapeStream,
+			Group:    scrapeGroup,
+			Consumer: consumer,
+			MinIdle:  q.RetryAfter,
+			Messages: []string{entry.Id},
+		}).Result()
+		if err != nil {
+			return nil, err
+		}
+		//another worker claimed it first
+		if len(claimed) == 0 {
+			continue
+		}
+		scrape := queueEntry{XMessage: claimed[0], deliveries: entry.RetryCount + 1}
+		if entry.RetryCount >= q.MaxDeliveries {
+			err = q.deadLetter(scrape, status.Error(codes.Unavailable, "worker stopped without finishing the scrape"))
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+		scrapes = append(scrapes, scrape)
+	}
+	return scrapes, nil
+}
+
+//nextStreamID returns the smallest stream entry ID after id
+func nextStreamID(id string) string {
+	separator := strings.IndexByte(id, '-')
+	if separator < 0 {
+		return id
+	}
+	seq, err := strconv.ParseUint(id[separator+1:], 10, 64)
+	if err != nil {
+		return id
+	}
+	return id[:separator+1] + strconv.FormatUint(seq+1, 10)
+}
+
+//finish acknowledges a scrape and saves how it ended, with the ASIN a product code resolved to
+func (q *RedisScrapeQueue) finish(scrape queueEntry, asin string, err error) error {
+	pipe := q.client.TxPipeline()
+	pipe.XAck(scrapeStream, scrapeGroup, scrape.ID)
+	q.saveResult(pipe, scrape.ID, asin, err)
+	_, err = pipe.Exec()
+	return err
+}
+
+//deadLetter moves a scrape to queue:scrape:dead with its last error
+func (q *RedisScrapeQueue) deadLetter(scrape queueEntry, err error) error {
+	values := make(map[string]interface{}, len(scrape.Values)+3)
+	for field, value := range scrape.Values {
+		values[field] = value
+	}
+	values["id"] = scrape.ID
+	values["deliveries"] = scrape.deliveries
+	values["error"] = status.Convert(err).Message()
+
+	pipe := q.client.TxPipeline()
+	pipe.XAdd(&redis.XAddArgs{Stream: scrapeDeadStream, MaxLenApprox: queueMaxLen, Values: values})
+	pipe.XAck(scrapeStream, scrapeGroup, scrape.ID)
+	q.saveResult(pipe, scrape.ID, "", err)
+	_, err = pipe.Exec()
+	return err
+}
+
+//saveResult sets queue:scrape:result:{id} for the API replica waiting on the scrape
+func (q *RedisScrapeQueue) saveResult(pipe redis.Pipeliner, id string, asin string, err error) {
+	result := TaskResult{Asin: asin}
+	if err != nil {
+		st := status.Convert(err)
+		result = TaskResult{Code: st.Code(), Message: st.Message()}
+	}
+	encoded, _ := json.Marshal(result)
+	pipe.Set("queue:scrape:result:"+id, encoded, queueResultTTL)
+}
+
+//retryableScrapeError tells whether a scrape might succeed on another delivery,
+//products that don't exist or bad requests fail for good
+func retryableScrapeError(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.ResourceExhausted, codes.DeadlineExceeded,
+		codes.Aborted, codes.Internal, codes.Unknown:
+		return true
+	}
+	return false
+}
+
+//ScrapeWorker consumes the scrape queue, scrapes products and stores and caches them
+//for the API replicas, any number of workers may share a queue
+type ScrapeWorker struct {
+	Queue *RedisScrapeQueue
+	//Consumer names this worker in the consumer group, it must be unique among workers
+	Consumer string
+	//Concurrency is how many scrapes this worker runs at once
+	Concurrency int
+
+	server *webScraperServer
+}
+
+//NewScrapeWorker takes the queue, a consumer name unique among workers, and the storage,
+//scraper and options products are scraped and stored with
+func NewScrapeWorker(queue *RedisScrapeQueue, consumer string, store ProductStore, cache ProductCache, scraper *Scraper, opts ServerOptions) *ScrapeWorker {
+	//the API replica that queued a scrape holds its scrape lock, so workers don't take it
+	opts.Queue = nil
+	server := NewScraperServerWithStorage(store, cache, nil, scraper, opts).(*webScraperServer)
+	return &ScrapeWorker{Queue: queue, Consumer: consumer, Concurrency: 1, server: server}
+}
+
+//Run consumes scrapes until ctx is done
+func (w *ScrapeWorker) Run(ctx context.Context) error {
+	if err := w.Queue.CreateGroup(); err != nil {
+		return err
+	}
+	done := make(chan struct{})
+	for worker := 0; worker < w.Concurrency; worker++ {
+		go func() {
+			w.consume(ctx)
+			done <- struct{}{}
+		}()
+	}
+	for worker := 0; worker < w.Concurrency; worker++ {
+		<-done
+	}
+	return nil
+}
+
+//consume retries failed scrapes first, then takes new ones, a scrape at a time
+func (w *ScrapeWorker) consume(ctx context.Context) {
+	for ctx.Err() == nil {
+		scrapes, err := w.Queue.reclaim(w.Consumer, 1)
+		if err == nil && len(scrapes) == 0 {
+			scrapes, err = w.Queue.read(w.Consumer, 1)
+		}
+		if err != nil {
+			logger.Log.Warn("failed to read scrape queue", zap.String("error", err.Error()))
+			select {
+			case <-ctx.Done():
+			case <-time.After(queueBlock):
+			}
+			continue
+		}
+		for _, scrape := range scrapes {
+			if err = w.process(ctx, scrape); err != nil {
+				logger.Log.Warn("failed to settle queued scrape",
+					zap.String("id", scrape.ID), zap.String("error", err.Error()))
+			}
+		}
+	}
+}
+
+//process scrapes a queued product, or resolves a queued product code, and acknowledges it,
+//unless it failed in a way another delivery may fix
+func (w *ScrapeWorker) process(ctx context.Context, scrape queueEntry) error {
+	task, err := ParseScrapeTask(scrape.Values)
+	if err != nil {
+		return w.Queue.finish(scrape, "", status.Error(codes.InvalidArgument, err.Error()))
+	}
+	asin := task.Asin
+	if task.Code != "" {
+		asin, err = w.server.resolveCode(ctx, task.CodeType, task.Code, task.Marketplace)
+	} else {
+		_, err = w.server.scrapeUnlessKnown(ctx, task.Asin, task.Marketplace, task.NotBefore)
+	}
+	if ctx.Err() != nil {
+		//shutting down, the scrape is delivered again after RetryAfter
+		return nil
+	}
+	if err == nil || !retryableScrapeError(err) {
+		return w.Queue.finish(scrape, asin, err)
+	}
+	logger.Log.Info("queued scrape failed", zap.String("asin", task.Asin), zap.String("code", task.Code),
+		zap.Int64("deliveries", scrape.deliveries), zap.String("error", err.Error()))
+	if scrape.deliveries >= w.Queue.MaxDeliveries {
+		return w.Queue.deadLetter(scrape, err)
+	}
+	return nil
+}
+
+//queueScrape hands a scrape to the workers and waits until they cached the product,
+//or until queueWait passes
+func (s *webScraperServer) queueScrape(ctx context.Context, asin string, marketplace string, notBefore time.Time) (AmazonProduct, error) {
+	id, err := s.opts.Queue.Enqueue(ScrapeTask{Asin: asin, Marketplace: marketplace, NotBefore: notBefore})
+	if err != nil {
+		return AmazonProduct{}, err
+	}
+	if _, err = s.waitForTask(ctx, id, "product "+asin); err != nil {
+		return AmazonProduct{}, err
+	}
+	product, err := s.cache.GetProduct(asin)
+	if err != nil && err != ErrCacheMiss {
+		return AmazonProduct{}, err
+	}
+	//the cache entry may be gone already, the stored copy is the same scrape
+	if !fromMarketplace(&product, marketplace) || !scrapedSince(&product, notBefore) {
+		if product, err = s.store.FetchProduct(asin); err != nil {
+			return AmazonProduct{}, err
+		}
+	}
+	s.indexForSearch(&product)
+	return product, nil
+}
+
+//queueCodeLookup hands the lookup of a product code to the workers and waits for the ASIN it resolves to,
+//or until queueWait passes
+func (s *webScraperServer) queueCodeLookup(ctx context.Context, codeType string, code string, marketplace string) (string, error) {
+	id, err := s.opts.Queue.Enqueue(ScrapeTask{Marketplace: marketplace, CodeType: codeType, Code: code})
+	if err != nil {
+		return "", err
+	}
+	result, err := s.waitForTask(ctx, id, codeType+" "+code)
+	return result.Asin, err
+}
+
+//queueWait is how long an API replica waits for a worker to finish a queued scrape:
+//a scrape with every retry of the replica's retry policy, which workers are configured with too,
+//plus queueSlack. It is capped at scrapeLockTTL so the lock outlives the wait
+func (s *webScraperServer) queueWait() time.Duration {
+	wait := s.scraper.Retry.MaxDuration() + queueSlack
+	if wait > scrapeLockTTL {
+		wait = scrapeLockTTL
+	}
+	return wait
+}
+
+//waitForTask polls the result of a queued task until a worker finished it, what names the task in the timeout error
+func (s *webScraperServer) waitForTask(ctx context.Context, id string, what string) (TaskResult, error) {
+	ticker := time.NewTicker(scrapeLockPoll)
+	defer ticker.Stop()
+	timeout := time.After(s.queueWait())
+	for {
+		select {
+		case <-ticker.C:
+		case <-timeout:
+			return TaskResult{}, status.Errorf(codes.Unavailable, "%s is queued for scraping, retry later", what)
+		case <-ctx.Done():
+			return TaskResult{}, ctx.Err()
+		}
+
+		result, ok, err := s.opts.Queue.Result(id)
+		if err != nil {
+			return TaskResult{}, err
+		}
+		if ok {
+			return result, result.Err()
+		}
+	}
+}
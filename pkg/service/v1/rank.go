@@ -0,0 +1,36 @@
+package v1
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+//rankPattern matches scraped ranks like "#2,680 in Clothing, Shoes & Jewelry"
+var rankPattern = regexp.MustCompile(`^#?\s*([\d,.]+)\s+in\s+(.+)$`)
+
+//ParseRank splits a scraped rank into its number and the category it ranks in,
+//the last node of a "Clothing > Women > Dresses" ladder. ok is false if rankInfo doesn't look like a rank
+func ParseRank(rankInfo string) (rank int64, category string, ok bool) {
+	match := rankPattern.FindStringSubmatch(strings.TrimSpace(rankInfo))
+	if match == nil {
+		return 0, "", false
+	}
+	//thousands are separated by "," on .com and by "." on some other marketplaces
+	digits := strings.NewReplacer(",", "", ".", "").Replace(match[1])
+	rank, err := strconv.ParseInt(digits, 10, 64)
+	if err != nil {
+		return 0, "", false
+	}
+	ladder := strings.Split(match[2], ">")
+	return rank, strings.TrimSpace(ladder[len(ladder)-1]), true
+}
+
+//mainRank is the rank of a product in its main category, the first one scraped
+func mainRank(product *AmazonProduct) (rank int64, ok bool) {
+	if len(product.Ranks) == 0 {
+		return 0, false
+	}
+	rank, _, ok = ParseRank(product.Ranks[0])
+	return
+}
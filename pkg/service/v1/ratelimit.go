@@ -0,0 +1,87 @@
+package v1
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-redis/redis"
+	"github.com/rnidev/go-webscraper/pkg/logger"
+	"go.uber.org/zap"
+)
+
+//tokenBucketScript takes a token from the bucket at KEYS[1],
+//ARGV is rate per second, burst and now in milliseconds.
+//It returns 0 when a token was taken, or milliseconds until one is available
+var tokenBucketScript = redis.NewScript(`
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+local state = redis.call("HMGET", KEYS[1], "tokens", "ts")
+local tokens = tonumber(state[1]) or burst
+local ts = tonumber(state[2]) or now
+if now > ts then
+  tokens = math.min(burst, tokens + (now - ts) * rate / 1000)
+else
+  now = ts
+end
+local wait = 0
+if tokens >= 1 then
+  tokens = tokens - 1
+else
+  wait = math.ceil((1 - tokens) * 1000 / rate)
+end
+redis.call("HMSET", KEYS[1], "tokens", tostring(tokens), "ts", tostring(now))
+redis.call("PEXPIRE", KEYS[1], math.ceil(burst * 1000 / rate) + 1000)
+return wait
+`)
+
+//RateLimiter is consulted before every visit to a domain
+type RateLimiter interface {
+	//Wait blocks until a request to domain is allowed
+	Wait(ctx context.Context, domain string) error
+}
+
+//RedisRateLimiter is a token bucket per domain kept in Redis with key ratelimit:{domain},
+//so every replica sharing the Redis server shares the same request budget
+type RedisRateLimiter struct {
+	client *redis.Client
+	//Rate is how many requests per second all replicas may send to a domain
+	Rate float64
+	//Burst is how many requests may be sent at once after an idle period
+	Burst int
+}
+
+//NewRedisRateLimiter takes the server's redis client, requests per second and burst size
+func NewRedisRateLimiter(client *redis.Client, rate float64, burst int) *RedisRateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &RedisRateLimiter{client: client, Rate: rate, Burst: burst}
+}
+
+//Wait takes a token for domain, sleeping until one is available.
+//If Redis can't be reached it lets the request through,
+//the local ScrapeScheduler still limits this replica
+func (l *RedisRateLimiter) Wait(ctx context.Context, domain string) error {
+	if l.Rate <= 0 {
+		return nil
+	}
+	for {
+		now := time.Now().UnixNano() / int64(time.Millisecond)
+		wait, err := tokenBucketScript.Run(l.client, []string{"ratelimit:" + domain},
+			l.Rate, l.Burst, now).Int64()
+		if err != nil {
+			logger.Log.Warn("distributed rate limit unavailable",
+				zap.String("domain", domain), zap.String("error", err.Error()))
+			return nil
+		}
+		if wait <= 0 {
+			return nil
+		}
+		select {
+		case <-time.After(time.Duration(wait) * time.Millisecond):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package v1
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	//ErrScrapeQueueFull returns if a domain already has as many scrapes waiting as it can queue
+	ErrScrapeQueueFull = status.Error(codes.ResourceExhausted, "too many scrapes waiting, try again later")
+
+	//DefaultScrapeScheduler allows two scrapes at a time and one request every two seconds per domain,
+	//the same budget a single request's LimitRule used to have
+	DefaultScrapeScheduler = NewScrapeScheduler(2, 2*time.Second, 50)
+)
+
+//ScrapeScheduler is the process-wide budget every scrape shares,
+//it limits concurrency and request rate per target domain
+type ScrapeScheduler struct {
+	//Concurrency is how many requests may be in flight per domain
+	Concurrency int
+	//Interval is the minimum time between two request starts per domain
+	Interval time.Duration
+	//MaxQueue is how many scrapes may wait for a slot per domain before ErrScrapeQueueFull
+	MaxQueue int
+
+	mu      sync.Mutex
+	domains map[string]*domainSchedule
+}
+
+type domainSchedule struct {
+	slots     chan struct{}
+	pending   int
+	nextStart time.Time
+}
+
+//NewScrapeScheduler takes per domain concurrency, request interval and queue size
+func NewScrapeScheduler(concurrency int, interval time.Duration, maxQueue int) *ScrapeScheduler {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	return &ScrapeScheduler{
+		Concurrency: concurrency,
+		Interval:    interval,
+		MaxQueue:    maxQueue,
+		domains:     make(map[string]*domainSchedule),
+	}
+}
+
+//Acquire waits for a request slot on domain, and its turn under the request rate.
+//It fails fast with ErrScrapeQueueFull when the queue is full,
+//call release once the request is done
+func (s *ScrapeScheduler) Acquire(ctx context.Context, domain string) (release func(), err error) {
+	s.mu.Lock()
+	d, ok := s.domains[domain]
+	if !ok {
+		d = &domainSchedule{slots: make(chan struct{}, s.Concurrency)}
+		s.domains[domain] = d
+	}
+	if d.pending >= s.Concurrency+s.MaxQueue {
+		s.mu.Unlock()
+		return nil, ErrScrapeQueueFull
+	}
+	d.pending++
+	s.mu.Unlock()
+
+	done := func() {
+		s.mu.Lock()
+		d.pending--
+		s.mu.Unlock()
+	}
+
+	select {
+	case d.slots <- struct{}{}:
+	case <-ctx.Done():
+		done()
+		return nil, ctx.Err()
+	}
+	release = func() {
+		<-d.slots
+		done()
+	}
+
+	//reserve the next start time, so queued requests keep their spacing
+	s.mu.Lock()
+	now := time.Now()
+	start := d.nextStart
+	if start.Before(now) {
+		start = now
+	}
+	d.nextStart = start.Add(s.Interval)
+	s.mu.Unlock()
+
+	timer := time.NewTimer(start.Sub(now))
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return release, nil
+	case <-ctx.Done():
+		release()
+		return nil, ctx.Err()
+	}
+}
+
+//Pending returns how many scrapes are running or waiting on domain
+func (s *ScrapeScheduler) Pending(domain string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if d, ok := s.domains[domain]; ok {
+		return d.pending
+	}
+	return 0
+}
@@ -0,0 +1,321 @@
+package v1
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gocolly/colly"
+	"github.com/gocolly/colly/extensions"
+	"github.com/rnidev/go-webscraper/pkg/logger"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	//scrapeRequestTimeout bounds every request of a scrape
+	scrapeRequestTimeout = 10 * time.Second
+)
+
+var (
+	//ErrRobotCheck returns if Amazon answers with its captcha page instead of the product
+	ErrRobotCheck = errors.New("robot check page returned")
+
+	//DefaultRetryPolicy retries throttling and server errors three times
+	DefaultRetryPolicy = RetryPolicy{
+		MaxAttempts: 3,
+		BaseBackoff: 2 * time.Second,
+		MaxBackoff:  30 * time.Second,
+		Jitter:      0.5,
+		RetryableStatusCodes: []int{
+			http.StatusTooManyRequests,
+			http.StatusInternalServerError,
+			http.StatusBadGateway,
+			http.StatusServiceUnavailable,
+			http.StatusGatewayTimeout,
+		},
+		RetryRobotCheck: true,
+	}
+
+	//markers of Amazon's captcha page, it is usually served with 200 OK
+	robotCheckMarkers = [][]byte{
+		[]byte("/errors/validateCaptcha"),
+		[]byte("<title dir=\"ltr\">Robot Check</title>"),
+	}
+)
+
+//RetryPolicy decides how many times and how fast a failed scrape is retried
+type RetryPolicy struct {
+	//MaxAttempts includes the first attempt, values below 1 mean a single attempt
+	MaxAttempts int
+	//BaseBackoff is the wait before the second attempt, doubled on every attempt after
+	BaseBackoff time.Duration
+	//MaxBackoff caps the exponential wait
+	MaxBackoff time.Duration
+	//Jitter is the fraction (0 to 1) of each wait that is randomized
+	Jitter float64
+	//RetryableStatusCodes are HTTP status codes worth another attempt
+	RetryableStatusCodes []int
+	//RetryRobotCheck retries when Amazon serves its robot check page
+	RetryRobotCheck bool
+}
+
+//ScrapeError is returned once a scrape gives up, it keeps the attempt count for diagnostics
+type ScrapeError struct {
+	URL        string
+	Attempts   int
+	StatusCode int
+	Err        error
+}
+
+func (e *ScrapeError) Error() string {
+	return fmt.Sprintf("scrape %s failed after %d attempt(s), status code %d: %v",
+		e.URL, e.Attempts, e.StatusCode, e.Err)
+}
+
+//GRPCStatus reports exhausted scrapes as Unavailable, so clients know to retry later
+func (e *ScrapeError) GRPCStatus() *status.Status {
+	return status.New(codes.Unavailable, e.Error())
+}
+
+//Scraper visits Amazon pages on behalf of the scraper server
+type Scraper struct {
+	Retry RetryPolicy
+	//Proxies rotates requests over a proxy pool, nil uses a direct connection
+	Proxies *ProxyPool
+	//Scheduler is the request budget shared by every scrape, nil means unlimited
+	Scheduler *ScrapeScheduler
+	//Limiter is the request budget shared with other replicas, nil means unlimited
+	Limiter RateLimiter
+	//Transport sends the requests of every scrape, nil uses colly's default,
+	//Proxies replace it with a transport of their own
+	Transport http.RoundTripper
+
+	mu         sync.Mutex
+	collectors map[string]*colly.Collector
+}
+
+//DefaultScraper is used by GetProductInfoByASIN
+var DefaultScraper = NewScraper(DefaultRetryPolicy, DefaultScrapeScheduler)
+
+//NewScraper takes a retry policy and the shared scrape scheduler, and returns a scraper
+func NewScraper(retry RetryPolicy, scheduler *ScrapeScheduler) *Scraper {
+	return &Scraper{
+		Retry:      retry,
+		Scheduler:  scheduler,
+		collectors: make(map[string]*colly.Collector),
+	}
+}
+
+//collector clones the long-lived collector of domain for a single attempt
+func (s *Scraper) collector(domain string) *colly.Collector {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.collectors == nil {
+		s.collectors = make(map[string]*colly.Collector)
+	}
+	base, ok := s.collectors[domain]
+	if !ok {
+		base = newCollector(domain)
+		if s.Transport != nil {
+			base.WithTransport(s.Transport)
+		}
+		if s.Proxies != nil {
+			base.SetProxyFunc(s.Proxies.GetProxy)
+		}
+		s.collectors[domain] = base
+	}
+
+	c := base.Clone()
+	//Randomize useragent to avoid bot detection, callbacks are not cloned
+	extensions.RandomUserAgent(c)
+	return c
+}
+
+//Collect visits targetURL on domain until it succeeds or the retry policy gives up.
+//Every attempt waits for the shared scheduler and gets a fresh clone of the domain's collector,
+//so handlers registered by setup must reset whatever they collect, and the user agent is randomized again
+func (s *Scraper) Collect(ctx context.Context, domain string, targetURL string, setup func(c *colly.Collector)) (res *colly.Response, err error) {
+	maxAttempts := s.Retry.MaxAttempts
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		//wait for the shared budget, a full queue fails right away
+		release := func() {}
+		if s.Scheduler != nil {
+			release, err = s.Scheduler.Acquire(ctx, domain)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if s.Limiter != nil {
+			if err = s.Limiter.Wait(ctx, domain); err != nil {
+				release()
+				return nil, err
+			}
+		}
+		res, err = s.visit(domain, targetURL, setup)
+		release()
+		if err == nil {
+			if attempt > 1 {
+				logger.Log.Info("scrape succeeded after retry",
+					zap.String("url", targetURL), zap.Int("attempt", attempt))
+			}
+			return
+		}
+
+		statusCode := 0
+		if res != nil {
+			statusCode = res.StatusCode
+		}
+		retry := attempt < maxAttempts && s.Retry.retryable(res, err)
+		logger.Log.Warn("scrape attempt failed",
+			zap.String("url", targetURL),
+			zap.Int("attempt", attempt),
+			zap.Int("max_attempts", maxAttempts),
+			zap.Int("status_code", statusCode),
+			zap.Bool("retry", retry),
+			zap.String("error", err.Error()))
+		if !retry {
+			err = &ScrapeError{URL: targetURL, Attempts: attempt, StatusCode: statusCode, Err: err}
+			return
+		}
+		select {
+		case <-time.After(s.Retry.Backoff(attempt)):
+		case <-ctx.Done():
+			return res, ctx.Err()
+		}
+	}
+	return
+}
+
+//visit runs a single attempt on a fresh clone of the domain's collector
+func (s *Scraper) visit(domain string, targetURL string, setup func(c *colly.Collector)) (res *colly.Response, err error) {
+	c := s.collector(domain)
+
+	var proxyURL string
+	// Error Handling
+	c.OnError(func(r *colly.Response, rerr error) {
+		res = r
+		err = rerr
+		proxyURL = r.Request.ProxyURL
+		return
+	})
+	//Amazon serves its captcha page with 200 OK, so OnError never sees it
+	c.OnResponse(func(r *colly.Response) {
+		proxyURL = r.Request.ProxyURL
+		if isRobotCheck(r.Body) {
+			res = r
+			err = ErrRobotCheck
+		}
+	})
+
+	setup(c)
+
+	if verr := c.Visit(targetURL); verr != nil {
+		err = verr
+		return
+	}
+	//Wait for collector to finish
+	c.Wait()
+
+	//pages Amazon doesn't have say nothing about the proxy that fetched them
+	if s.Proxies != nil && proxyURL != "" && (err == nil || proxyFailed(res, err)) {
+		s.Proxies.Report(proxyURL, err == nil, blocked(res, err))
+	}
+	return
+}
+
+//Backoff returns the wait after the given failed attempt, exponential with jitter
+func (p RetryPolicy) Backoff(attempt int) time.Duration {
+	backoff := p.maxBackoff(attempt)
+	jitter := math.Min(math.Max(p.Jitter, 0), 1)
+	//keep (1 - jitter) of the wait and randomize the rest
+	backoff = backoff*(1-jitter) + rand.Float64()*backoff*jitter
+	return time.Duration(backoff)
+}
+
+//MaxDuration is the longest a scrape with the policy takes, besides waiting for the scrape budget:
+//every attempt runs into the request timeout and every backoff is at its longest
+func (p RetryPolicy) MaxDuration() time.Duration {
+	maxAttempts := p.MaxAttempts
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+	total := float64(maxAttempts) * float64(scrapeRequestTimeout)
+	for attempt := 1; attempt < maxAttempts; attempt++ {
+		total += p.maxBackoff(attempt)
+	}
+	if total > math.MaxInt64 {
+		return time.Duration(math.MaxInt64)
+	}
+	return time.Duration(total)
+}
+
+//maxBackoff is the wait after the given failed attempt before jitter
+func (p RetryPolicy) maxBackoff(attempt int) float64 {
+	if p.BaseBackoff <= 0 {
+		return 0
+	}
+	backoff := float64(p.BaseBackoff) * math.Pow(2, float64(attempt-1))
+	if p.MaxBackoff > 0 && backoff > float64(p.MaxBackoff) {
+		backoff = float64(p.MaxBackoff)
+	}
+	return backoff
+}
+
+func (p RetryPolicy) retryable(res *colly.Response, err error) bool {
+	//no response means the collector couldn't be set up, which won't get better
+	if res == nil {
+		return false
+	}
+	if err == ErrRobotCheck {
+		return p.RetryRobotCheck
+	}
+	//no status code means the request never got an answer, e.g. a timeout
+	if res.StatusCode == 0 {
+		return true
+	}
+	for _, code := range p.RetryableStatusCodes {
+		if code == res.StatusCode {
+			return true
+		}
+	}
+	return false
+}
+
+//blocked tells whether Amazon pushed back on the request itself,
+//rather than the page or the network failing
+func blocked(res *colly.Response, err error) bool {
+	if err == ErrRobotCheck {
+		return true
+	}
+	return res != nil && (res.StatusCode == http.StatusTooManyRequests ||
+		res.StatusCode == http.StatusServiceUnavailable)
+}
+
+//proxyFailed tells whether a failed request counts against its proxy,
+//i.e. it never got an answer, the proxy refused it or Amazon blocked it
+func proxyFailed(res *colly.Response, err error) bool {
+	if blocked(res, err) {
+		return true
+	}
+	return res == nil || res.StatusCode == 0 || res.StatusCode == http.StatusProxyAuthRequired
+}
+
+func isRobotCheck(body []byte) bool {
+	for _, marker := range robotCheckMarkers {
+		if bytes.Contains(body, marker) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,397 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/go-redis/redis"
+	v1 "github.com/rnidev/go-webscraper/pkg/api/v1"
+	"github.com/rnidev/go-webscraper/pkg/logger"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	defaultSearchLimit = 20
+	maxSearchLimit     = 100
+	//maxFacets is how many categories a search counts its matches in
+	maxFacets = 10
+
+	//searchIndexName is the RediSearch index over the search:product:{ASIN} hashes
+	searchIndexName = "idx:products"
+	searchKeyPrefix = "search:product:"
+	//searchListSeparator joins the features and categories of a product in its search hash
+	searchListSeparator = "|"
+)
+
+var (
+	//ErrMissingKeywords returns if a search query has no word to look for
+	ErrMissingKeywords = errors.New("missing search keywords")
+
+	//searchSchema weighs a keyword in the name most, then in the brand, then anywhere else.
+	//category holds the lower cased categories, for filtering by category
+	searchSchema = []interface{}{
+		"name", "TEXT", "WEIGHT", "2.0",
+		"brand", "TEXT", "WEIGHT", "1.5",
+		"features", "TEXT",
+		"categories", "TEXT",
+		"category", "TAG", "SEPARATOR", searchListSeparator,
+	}
+	//searchHighlighted are the fields whose matches SearchHit.Highlights holds
+	searchHighlighted = []string{FieldName, FieldBrand, FieldFeatures, FieldCategories}
+)
+
+//ProductSearch is a full-text index of stored products, searching
+//their names, brands, features and categories by keyword
+type ProductSearch interface {
+	//Index adds product to the index, replacing what was indexed for its ASIN
+	Index(product *AmazonProduct) error
+	//Remove drops a product from the index
+	Remove(asin string) error
+	Search(query SearchQuery) (SearchResult, error)
+}
+
+//SearchQuery is what ProductSearch.Search looks for
+type SearchQuery struct {
+	//Query holds keywords that must all match
+	Query string
+	//Category only keeps products with this category at any level
+	Category string
+	Limit    int
+	Offset   int
+}
+
+//SearchResult is a page of hits, best match first, and the categories of every match
+type SearchResult struct {
+	Hits   []SearchHit
+	Total  int
+	Facets []CategoryFacet
+}
+
+//SearchHit is the ASIN of a product matching a search, with its matches highlighted by field
+type SearchHit struct {
+	Asin       string
+	Score      float64
+	Highlights map[string][]string
+}
+
+//CategoryFacet counts the matches of a search in a category
+type CategoryFacet struct {
+	Category string
+	Count    int
+}
+
+//RediSearchIndex is a ProductSearch in RediSearch, shared by every replica on the same Redis.
+//A product is a search:product:{ASIN} hash of its searchable fields, indexed by RediSearch as it is written,
+//so the index lives in Redis instead of in each process
+type RediSearchIndex struct {
+	client *redis.Client
+}
+
+//NewRediSearchIndex returns a search index on client, which needs the RediSearch module
+func NewRediSearchIndex(client *redis.Client) *RediSearchIndex {
+	return &RediSearchIndex{client: client}
+}
+
+//CreateIndex creates the RediSearch index, created is false if it existed already
+func (r *RediSearchIndex) CreateIndex() (created bool, err error) {
+	args := append([]interface{}{"FT.CREATE", searchIndexName, "ON", "HASH", "PREFIX", 1, searchKeyPrefix, "SCHEMA"},
+		searchSchema...)
+	err = r.client.Do(args...).Err()
+	if err != nil && strings.Contains(err.Error(), "Index already exists") {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+//Index writes the search hash of product
+func (r *RediSearchIndex) Index(product *AmazonProduct) error {
+	if product.Asin == "" {
+		return ErrMissingASIN
+	}
+	return r.client.HMSet(searchKeyPrefix+product.Asin, searchFields(product)).Err()
+}
+
+//Remove deletes the search hash of a product
+func (r *RediSearchIndex) Remove(asin string) error {
+	if asin == "" {
+		return ErrMissingASIN
+	}
+	return r.client.Del(searchKeyPrefix + asin).Err()
+}
+
+//Rebuild indexes every product of lister again, it returns how many were indexed
+func (r *RediSearchIndex) Rebuild(lister ProductLister) (indexed int, err error) {
+	cursor := ""
+	for {
+		products, next, err := lister.ScanProducts(cursor, maxPageSize)
+		if err != nil {
+			return indexed, err
+		}
+		pipe := r.client.Pipeline()
+		for index := range products {
+			pipe.HMSet(searchKeyPrefix+products[index].Asin, searchFields(&products[index]))
+		}
+		if len(products) > 0 {
+			if _, err = pipe.Exec(); err != nil {
+				return indexed, err
+			}
+		}
+		indexed += len(products)
+		if next == "" {
+			return indexed, nil
+		}
+		cursor = next
+	}
+}
+
+//RebuildEvery rebuilds the index from lister every interval until ctx is done,
+//for products stored without going through a server, like by another service writing to SQL
+func (r *RediSearchIndex) RebuildEvery(ctx context.Context, lister ProductLister, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := r.Rebuild(lister); err != nil {
+				logger.Log.Warn("failed to rebuild search index", zap.String("error", err.Error()))
+			}
+		}
+	}
+}
+
+//Search runs query with FT.SEARCH, ranked by RediSearch's TF-IDF with the field weights of searchSchema,
+//and counts the categories of every match with FT.AGGREGATE
+func (r *RediSearchIndex) Search(query SearchQuery) (result SearchResult, err error) {
+	keywords := searchKeywords(query.Query)
+	if len(keywords) == 0 {
+		err = ErrMissingKeywords
+		return
+	}
+	match := strings.Join(keywords, " ")
+	filtered := match
+	if category := strings.ToLower(strings.TrimSpace(query.Category)); category != "" {
+		filtered += " @category:{" + escapeSearchTag(category) + "}"
+	}
+	limit := query.Limit
+	if limit <= 0 {
+		limit = defaultSearchLimit
+	}
+
+	args := []interface{}{"FT.SEARCH", searchIndexName, filtered, "WITHSCORES",
+		"HIGHLIGHT", "FIELDS", len(searchHighlighted)}
+	for _, field := range searchHighlighted {
+		args = append(args, field)
+	}
+	args = append(args, "TAGS", "<em>", "</em>", "RETURN", len(searchHighlighted))
+	for _, field := range searchHighlighted {
+		args = append(args, field)
+	}
+	args = append(args, "LIMIT", query.Offset, limit)
+	reply, err := r.client.Do(args...).Result()
+	if err != nil {
+		return
+	}
+	if result.Total, result.Hits, err = parseSearchReply(reply); err != nil {
+		return
+	}
+
+	//facets count every match, the category filter only narrows the hits
+	reply, err = r.client.Do("FT.AGGREGATE", searchIndexName, match,
+		"LOAD", 1, "@categories",
+		"APPLY", `split(@categories, "`+searchListSeparator+`", " ")`, "AS", "facet",
+		"GROUPBY", 1, "@facet", "REDUCE", "COUNT", 0, "AS", "count",
+		"SORTBY", 4, "@count", "DESC", "@facet", "ASC", "MAX", maxFacets).Result()
+	if err != nil {
+		return
+	}
+	result.Facets, err = parseFacetReply(reply)
+	return
+}
+
+//searchFields are the fields of the search hash of product
+func searchFields(product *AmazonProduct) map[string]interface{} {
+	lowered := make([]string, len(product.Categories))
+	for index, category := range product.Categories {
+		lowered[index] = strings.ToLower(category)
+	}
+	return map[string]interface{}{
+		FieldName:       product.Name,
+		FieldBrand:      product.Brand,
+		FieldFeatures:   strings.Join(product.Features, searchListSeparator),
+		FieldCategories: strings.Join(product.Categories, searchListSeparator),
+		"category":      strings.Join(lowered, searchListSeparator),
+	}
+}
+
+//parseSearchReply reads the total and the hits of a FT.SEARCH WITHSCORES reply:
+//the total, then the key, score and fields of every hit
+func parseSearchReply(reply interface{}) (total int, hits []SearchHit, err error) {
+	values, ok := reply.([]interface{})
+	if !ok || len(values) == 0 {
+		return 0, nil, fmt.Errorf("unexpected FT.SEARCH reply %v", reply)
+	}
+	count, ok := values[0].(int64)
+	if !ok {
+		return 0, nil, fmt.Errorf("unexpected FT.SEARCH total %v", values[0])
+	}
+	for index := 1; index+2 < len(values); index += 3 {
+		key, _ := values[index].(string)
+		score, _ := values[index+1].(string)
+		hit := SearchHit{Asin: strings.TrimPrefix(key, searchKeyPrefix), Highlights: make(map[string][]string)}
+		if hit.Score, err = strconv.ParseFloat(score, 64); err != nil {
+			return 0, nil, fmt.Errorf("unexpected FT.SEARCH score %q", score)
+		}
+		fields, _ := values[index+2].([]interface{})
+		for field := 0; field+1 < len(fields); field += 2 {
+			name, _ := fields[field].(string)
+			value, _ := fields[field+1].(string)
+			fragments := []string{value}
+			if name == FieldFeatures || name == FieldCategories {
+				fragments = strings.Split(value, searchListSeparator)
+			}
+			for _, fragment := range fragments {
+				if strings.Contains(fragment, "<em>") {
+					hit.Highlights[name] = append(hit.Highlights[name], fragment)
+				}
+			}
+		}
+		hits = append(hits, hit)
+	}
+	return int(count), hits, nil
+}
+
+//parseFacetReply reads the rows of a FT.AGGREGATE reply grouped by facet
+func parseFacetReply(reply interface{}) (facets []CategoryFacet, err error) {
+	values, ok := reply.([]interface{})
+	if !ok || len(values) == 0 {
+		return nil, fmt.Errorf("unexpected FT.AGGREGATE reply %v", reply)
+	}
+	for _, value := range values[1:] {
+		row, _ := value.([]interface{})
+		var facet CategoryFacet
+		for field := 0; field+1 < len(row); field += 2 {
+			name, _ := row[field].(string)
+			value, _ := row[field+1].(string)
+			switch name {
+			case "facet":
+				facet.Category = value
+			case "count":
+				if facet.Count, err = strconv.Atoi(value); err != nil {
+					return nil, fmt.Errorf("unexpected FT.AGGREGATE count %q", value)
+				}
+			}
+		}
+		if facet.Category != "" {
+			facets = append(facets, facet)
+		}
+	}
+	return
+}
+
+//searchKeywords splits text into lower cased words, dropping the punctuation
+//that is query syntax in RediSearch
+func searchKeywords(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+//escapeSearchTag escapes a value for a RediSearch tag filter, where punctuation and spaces are syntax
+func escapeSearchTag(tag string) string {
+	var b strings.Builder
+	for _, r := range tag {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' {
+			b.WriteRune('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+//SearchStoredProducts searches stored products by keywords in their name, brand, features and categories
+func (s *webScraperServer) SearchStoredProducts(ctx context.Context, req *v1.SearchStoredProductsRequest) (*v1.SearchStoredProductsResponse, error) {
+	if s.opts.Search == nil {
+		return &v1.SearchStoredProductsResponse{}, status.Error(codes.Unimplemented, "search isn't enabled")
+	}
+	if len(searchKeywords(req.Query)) == 0 {
+		return &v1.SearchStoredProductsResponse{}, status.Error(codes.InvalidArgument, ErrMissingKeywords.Error())
+	}
+	if req.Limit < 0 || req.Offset < 0 {
+		return &v1.SearchStoredProductsResponse{}, status.Error(codes.InvalidArgument, "limit and offset can't be negative")
+	}
+	limit := int(req.Limit)
+	if limit == 0 {
+		limit = defaultSearchLimit
+	}
+	if limit > maxSearchLimit {
+		limit = maxSearchLimit
+	}
+	result, err := s.opts.Search.Search(SearchQuery{
+		Query:    req.Query,
+		Category: strings.TrimSpace(req.Category),
+		Limit:    limit,
+		Offset:   int(req.Offset),
+	})
+	if err != nil {
+		return &v1.SearchStoredProductsResponse{}, err
+	}
+
+	//the index only holds what is searched, products are read from the store
+	asins := make([]string, len(result.Hits))
+	for index, hit := range result.Hits {
+		asins[index] = hit.Asin
+	}
+	products, err := s.store.FetchProducts(asins)
+	if err != nil {
+		return &v1.SearchStoredProductsResponse{}, err
+	}
+	stored := make(map[string]*AmazonProduct, len(products))
+	for index := range products {
+		stored[products[index].Asin] = &products[index]
+	}
+
+	res := &v1.SearchStoredProductsResponse{Total: int32(result.Total)}
+	for index := range result.Hits {
+		hit := &result.Hits[index]
+		//deleted since it was indexed
+		storedProduct, ok := stored[hit.Asin]
+		if !ok {
+			continue
+		}
+		product, err := mapProduct(storedProduct)
+		if err != nil {
+			return &v1.SearchStoredProductsResponse{}, err
+		}
+		searchHit := &v1.SearchHit{Product: &product, Score: hit.Score}
+		for _, field := range searchHighlighted {
+			if fragments, ok := hit.Highlights[field]; ok {
+				searchHit.Highlights = append(searchHit.Highlights, &v1.FieldHighlight{Field: field, Fragments: fragments})
+			}
+		}
+		res.Hits = append(res.Hits, searchHit)
+	}
+	for _, facet := range result.Facets {
+		res.Facets = append(res.Facets, &v1.CategoryFacet{Category: facet.Category, Count: int32(facet.Count)})
+	}
+	return res, nil
+}
+
+//indexForSearch makes a stored product searchable if search is enabled,
+//a product that fails to index is still stored, so it only logs
+func (s *webScraperServer) indexForSearch(product *AmazonProduct) {
+	if s.opts.Search == nil {
+		return
+	}
+	if err := s.opts.Search.Index(product); err != nil {
+		logger.Log.Warn("failed to index product for search", zap.String("asin", product.Asin), zap.String("error", err.Error()))
+	}
+}
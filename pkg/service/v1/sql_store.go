@@ -0,0 +1,537 @@
+package v1
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//sqlMigrations create the product schema, they run in order and each runs once.
+//{id} is the dialect's auto increment primary key and {timestamp} its timestamp type.
+//Never change a released migration, append a new one instead
+var sqlMigrations = [][]string{
+	{
+		`CREATE TABLE products (
+			id {id},
+			asin VARCHAR(10) NOT NULL,
+			name TEXT NOT NULL,
+			brand TEXT NOT NULL DEFAULT '',
+			price TEXT NOT NULL DEFAULT '',
+			availability TEXT NOT NULL DEFAULT '',
+			marketplace VARCHAR(64) NOT NULL DEFAULT '',
+			created_at {timestamp} NOT NULL
+		)`,
+		`CREATE INDEX products_asin_created_at ON products (asin, created_at)`,
+		`CREATE TABLE product_categories (
+			product_id BIGINT NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+			level INTEGER NOT NULL,
+			name TEXT NOT NULL,
+			PRIMARY KEY (product_id, level)
+		)`,
+		`CREATE TABLE product_ranks (
+			product_id BIGINT NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+			level INTEGER NOT NULL,
+			rank_info TEXT NOT NULL,
+			PRIMARY KEY (product_id, level)
+		)`,
+		`CREATE TABLE product_dimensions (
+			product_id BIGINT NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+			position INTEGER NOT NULL,
+			dimension TEXT NOT NULL,
+			PRIMARY KEY (product_id, position)
+		)`,
+		`CREATE TABLE product_features (
+			product_id BIGINT NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+			position INTEGER NOT NULL,
+			feature TEXT NOT NULL,
+			PRIMARY KEY (product_id, position)
+		)`,
+		`CREATE TABLE product_codes (
+			code_type VARCHAR(8) NOT NULL,
+			code VARCHAR(14) NOT NULL,
+			asin VARCHAR(10) NOT NULL,
+			PRIMARY KEY (code_type, code)
+		)`,
+	},
+}
+
+//sqlDialect is what differs between the supported databases
+type sqlDialect struct {
+	autoIncrement string
+	timestamp     string
+	//numbered placeholders are $1, $2, … instead of ?
+	numbered bool
+	//returning reads inserted ids with RETURNING instead of LastInsertId
+	returning bool
+}
+
+//sqlBatchSize is how many products one query reads at most, below SQLite's 999 variables
+const sqlBatchSize = 500
+
+var sqlDialects = map[string]sqlDialect{
+	"postgres": {autoIncrement: "BIGSERIAL PRIMARY KEY", timestamp: "TIMESTAMPTZ", numbered: true, returning: true},
+	"sqlite3":  {autoIncrement: "INTEGER PRIMARY KEY AUTOINCREMENT", timestamp: "TIMESTAMP"},
+}
+
+//SQLStore is a ProductStore in Postgres or SQLite. Every scrape is a row in products,
+//with its categories, ranks, dimensions and features in child tables, so product data can be joined.
+//It keeps no cache, run it alongside a ProductCache such as RedisStore
+type SQLStore struct {
+	db      *sql.DB
+	dialect sqlDialect
+}
+
+//NewSQLStore opens the database with a registered driver, postgres or sqlite3,
+//and migrates it to the latest schema
+func NewSQLStore(driver string, dsn string) (*SQLStore, error) {
+	dialect, ok := sqlDialects[driver]
+	if !ok {
+		return nil, fmt.Errorf("unsupported SQL driver %q, expected postgres or sqlite3", driver)
+	}
+	if driver == "sqlite3" {
+		//foreign keys are enforced per connection, the DSN turns them on for every one the pool opens
+		dsn = sqliteForeignKeys(dsn)
+	}
+	db, err := sql.Open(driver, dsn)
+	if err != nil {
+		return nil, err
+	}
+	if driver == "sqlite3" {
+		//SQLite allows a single writer
+		db.SetMaxOpenConns(1)
+	}
+	store := &SQLStore{db: db, dialect: dialect}
+	if err = store.Migrate(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return store, nil
+}
+
+//sqliteForeignKeys adds _foreign_keys=1 to a go-sqlite3 DSN, so ON DELETE CASCADE applies
+func sqliteForeignKeys(dsn string) string {
+	if strings.Contains(dsn, "_foreign_keys=") || strings.Contains(dsn, "_fk=") {
+		return dsn
+	}
+	if strings.Contains(dsn, "?") {
+		return dsn + "&_foreign_keys=1"
+	}
+	return dsn + "?_foreign_keys=1"
+}
+
+//Close closes the database
+func (s *SQLStore) Close() error {
+	return s.db.Close()
+}
+
+//Migrate runs the migrations the database hasn't seen yet, recorded in schema_migrations
+func (s *SQLStore) Migrate() error {
+	_, err := s.db.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (version INTEGER PRIMARY KEY)`)
+	if err != nil {
+		return err
+	}
+	var current int
+	err = s.db.QueryRow(`SELECT COALESCE(MAX(version), 0) FROM schema_migrations`).Scan(&current)
+	if err != nil {
+		return err
+	}
+	types := strings.NewReplacer("{id}", s.dialect.autoIncrement, "{timestamp}", s.dialect.timestamp)
+	for index, statements := range sqlMigrations {
+		version := index + 1
+		if version <= current {
+			continue
+		}
+		err = s.inTx(func(tx *sql.Tx) error {
+			for _, statement := range statements {
+				if _, err := tx.Exec(types.Replace(statement)); err != nil {
+					return fmt.Errorf("migration %d: %v", version, err)
+				}
+			}
+			_, err := tx.Exec(s.rebind(`INSERT INTO schema_migrations (version) VALUES (?)`), version)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//StoreProduct inserts a scrape of product with its child rows
+func (s *SQLStore) StoreProduct(product *AmazonProduct) error {
+	if product.Asin == "" {
+		return ErrMissingASIN
+	}
+	if product.Name == "" {
+		return errMissingProductName
+	}
+	if len(product.Categories) == 0 {
+		return errMissingProductCategory
+	}
+	createdAt := time.Now().In(time.UTC)
+	if product.CreatedAt != "" {
+		var err error
+		if createdAt, err = time.Parse(time.RFC3339Nano, product.CreatedAt); err != nil {
+			return err
+		}
+	}
+
+	return s.inTx(func(tx *sql.Tx) error {
+		var id int64
+		insert := `INSERT INTO products (asin, name, brand, price, availability, marketplace, created_at) VALUES (?, ?, ?, ?, ?, ?, ?)`
+		if s.dialect.returning {
+			err := tx.QueryRow(s.rebind(insert+` RETURNING id`),
+				product.Asin, product.Name, product.Brand, product.Price, product.Availability, product.Marketplace, createdAt).Scan(&id)
+			if err != nil {
+				return err
+			}
+		} else {
+			result, err := tx.Exec(s.rebind(insert), product.Asin, product.Name, product.Brand, product.Price, product.Availability, product.Marketplace, createdAt)
+			if err != nil {
+				return err
+			}
+			if id, err = result.LastInsertId(); err != nil {
+				return err
+			}
+		}
+
+		children := []struct {
+			query  string
+			values []string
+		}{
+			{`INSERT INTO product_categories (product_id, level, name) VALUES (?, ?, ?)`, product.Categories},
+			{`INSERT INTO product_ranks (product_id, level, rank_info) VALUES (?, ?, ?)`, product.Ranks},
+			{`INSERT INTO product_dimensions (product_id, position, dimension) VALUES (?, ?, ?)`, product.Dimensions},
+			{`INSERT INTO product_features (product_id, position, feature) VALUES (?, ?, ?)`, product.Features},
+		}
+		for _, child := range children {
+			for index, value := range child.values {
+				if _, err := tx.Exec(s.rebind(child.query), id, index+1, value); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
+
+//FetchProduct returns the latest scrape of a product
+func (s *SQLStore) FetchProduct(asin string) (product AmazonProduct, err error) {
+	if asin == "" {
+		err = ErrMissingASIN
+		return
+	}
+	var (
+		id        int64
+		createdAt time.Time
+	)
+	err = s.db.QueryRow(s.rebind(`SELECT id, name, brand, price, availability, marketplace, created_at FROM products
+		WHERE asin = ? ORDER BY created_at DESC, id DESC LIMIT 1`), asin).
+		Scan(&id, &product.Name, &product.Brand, &product.Price, &product.Availability, &product.Marketplace, &createdAt)
+	if err == sql.ErrNoRows {
+		err = ErrProductNotFound
+		return
+	}
+	if err != nil {
+		return
+	}
+	product.Asin = asin
+	product.CreatedAt = createdAt.In(time.UTC).Format(time.RFC3339Nano)
+	err = s.fetchChildren([]int64{id}, []*AmazonProduct{&product})
+	return
+}
+
+//FetchProducts returns the latest scrape of each product, in the order of asins.
+//Products are read sqlBatchSize at a time, with one query per table for each batch
+func (s *SQLStore) FetchProducts(asins []string) (products []AmazonProduct, err error) {
+	for start := 0; start < len(asins); start += sqlBatchSize {
+		end := start + sqlBatchSize
+		if end > len(asins) {
+			end = len(asins)
+		}
+		batch, err := s.fetchLatest(asins[start:end])
+		if err != nil {
+			return nil, err
+		}
+		products = append(products, batch...)
+	}
+	return
+}
+
+//fetchLatest returns the latest scrape of each of a batch of products, in the order of asins
+func (s *SQLStore) fetchLatest(asins []string) (products []AmazonProduct, err error) {
+	args := make([]interface{}, len(asins))
+	for index, asin := range asins {
+		args[index] = asin
+	}
+	rows, err := s.db.Query(s.rebind(`SELECT p.id, p.asin, p.name, p.brand, p.price, p.availability, p.marketplace, p.created_at FROM products p
+		WHERE p.asin IN (`+sqlPlaceholders(len(asins))+`) AND NOT EXISTS (
+			SELECT 1 FROM products q WHERE q.asin = p.asin
+			AND (q.created_at > p.created_at OR (q.created_at = p.created_at AND q.id > p.id)))`), args...)
+	if err != nil {
+		return
+	}
+	latest := make(map[string]AmazonProduct, len(asins))
+	ids := make(map[string]int64, len(asins))
+	for rows.Next() {
+		var (
+			id        int64
+			product   AmazonProduct
+			createdAt time.Time
+		)
+		if err = rows.Scan(&id, &product.Asin, &product.Name, &product.Brand, &product.Price, &product.Availability, &product.Marketplace, &createdAt); err != nil {
+			rows.Close()
+			return
+		}
+		product.CreatedAt = createdAt.In(time.UTC).Format(time.RFC3339Nano)
+		latest[product.Asin] = product
+		ids[product.Asin] = id
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return
+	}
+	var (
+		found   []int64
+		targets []*AmazonProduct
+	)
+	products = make([]AmazonProduct, 0, len(latest))
+	for _, asin := range asins {
+		if product, ok := latest[asin]; ok {
+			products = append(products, product)
+			found = append(found, ids[asin])
+		}
+	}
+	for index := range products {
+		targets = append(targets, &products[index])
+	}
+	err = s.fetchChildren(found, targets)
+	return
+}
+
+//ScanProducts returns the latest scrape of products ordered by ASIN, after the ASIN in cursor
+func (s *SQLStore) ScanProducts(cursor string, count int) (products []AmazonProduct, next string, err error) {
+	rows, err := s.db.Query(s.rebind(`SELECT DISTINCT asin FROM products
+		WHERE asin > ? ORDER BY asin LIMIT ?`), cursor, count+1)
+	if err != nil {
+		return
+	}
+	var asins []string
+	for rows.Next() {
+		var asin string
+		if err = rows.Scan(&asin); err != nil {
+			rows.Close()
+			return
+		}
+		asins = append(asins, asin)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return
+	}
+	if len(asins) > count {
+		asins = asins[:count]
+		next = asins[count-1]
+	}
+	products, err = s.FetchProducts(asins)
+	return
+}
+
+//DeleteProduct deletes every scrape of a product, its categories, ranks, dimensions and features go with it
+func (s *SQLStore) DeleteProduct(asin string) (deleted bool, err error) {
+	if asin == "" {
+		err = ErrMissingASIN
+		return
+	}
+	result, err := s.db.Exec(s.rebind(`DELETE FROM products WHERE asin = ?`), asin)
+	if err != nil {
+		return
+	}
+	rows, err := result.RowsAffected()
+	return rows > 0, err
+}
+
+//StoreCodeMapping saves the ASIN a product code resolves to
+func (s *SQLStore) StoreCodeMapping(codeType string, code string, asin string) error {
+	if asin == "" {
+		return ErrMissingASIN
+	}
+	if code == "" {
+		return ErrMissingCode
+	}
+	_, err := s.db.Exec(s.rebind(`INSERT INTO product_codes (code_type, code, asin) VALUES (?, ?, ?)
+		ON CONFLICT (code_type, code) DO UPDATE SET asin = excluded.asin`), codeType, code, asin)
+	return err
+}
+
+//FetchASINByCode returns the ASIN a product code resolved to
+func (s *SQLStore) FetchASINByCode(codeType string, code string) (asin string, err error) {
+	err = s.db.QueryRow(s.rebind(`SELECT asin FROM product_codes WHERE code_type = ? AND code = ?`),
+		codeType, code).Scan(&asin)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	return
+}
+
+//AddSnapshot applies retention to the history of product,
+//StoreProduct already inserted the scrape as a row of its own
+func (s *SQLStore) AddSnapshot(product *AmazonProduct, retention HistoryRetention) (err error) {
+	if product.Asin == "" {
+		return ErrMissingASIN
+	}
+	if retention.MaxAge > 0 {
+		_, err = s.db.Exec(s.rebind(`DELETE FROM products WHERE asin = ? AND created_at < ?`),
+			product.Asin, time.Now().Add(-retention.MaxAge).In(time.UTC))
+		if err != nil {
+			return
+		}
+	}
+	if retention.MaxSnapshots > 0 {
+		_, err = s.db.Exec(s.rebind(`DELETE FROM products WHERE asin = ? AND id NOT IN (
+			SELECT id FROM products WHERE asin = ? ORDER BY created_at DESC, id DESC LIMIT ?)`),
+			product.Asin, product.Asin, retention.MaxSnapshots)
+	}
+	return
+}
+
+//Snapshots returns the scrapes of a product within from and to
+func (s *SQLStore) Snapshots(asin string, from time.Time, to time.Time) (snapshots []AmazonProduct, err error) {
+	rows, err := s.db.Query(s.rebind(`SELECT id, name, brand, price, availability, marketplace, created_at FROM products
+		WHERE asin = ? AND created_at >= ? AND created_at <= ? ORDER BY created_at, id`),
+		asin, from.In(time.UTC), to.In(time.UTC))
+	if err != nil {
+		return
+	}
+	var ids []int64
+	for rows.Next() {
+		var (
+			id        int64
+			snapshot  = AmazonProduct{Asin: asin}
+			createdAt time.Time
+		)
+		if err = rows.Scan(&id, &snapshot.Name, &snapshot.Brand, &snapshot.Price, &snapshot.Availability, &snapshot.Marketplace, &createdAt); err != nil {
+			rows.Close()
+			return
+		}
+		snapshot.CreatedAt = createdAt.In(time.UTC).Format(time.RFC3339Nano)
+		ids = append(ids, id)
+		snapshots = append(snapshots, snapshot)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return
+	}
+	//child rows are read once the product rows are closed, SQLite has a single connection
+	for start := 0; start < len(ids); start += sqlBatchSize {
+		end := start + sqlBatchSize
+		if end > len(ids) {
+			end = len(ids)
+		}
+		var targets []*AmazonProduct
+		for index := start; index < end; index++ {
+			targets = append(targets, &snapshots[index])
+		}
+		if err = s.fetchChildren(ids[start:end], targets); err != nil {
+			return
+		}
+	}
+	return
+}
+
+//fetchChildren reads categories, ranks, dimensions and features of the product rows ids into products,
+//one query per table for all of them
+func (s *SQLStore) fetchChildren(ids []int64, products []*AmazonProduct) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	byID := make(map[int64]*AmazonProduct, len(ids))
+	args := make([]interface{}, len(ids))
+	for index, id := range ids {
+		byID[id] = products[index]
+		args[index] = id
+	}
+	in := sqlPlaceholders(len(ids))
+	children := []struct {
+		query  string
+		values func(product *AmazonProduct) *[]string
+	}{
+		{`SELECT product_id, name FROM product_categories WHERE product_id IN (` + in + `) ORDER BY product_id, level`,
+			func(product *AmazonProduct) *[]string { return &product.Categories }},
+		{`SELECT product_id, rank_info FROM product_ranks WHERE product_id IN (` + in + `) ORDER BY product_id, level`,
+			func(product *AmazonProduct) *[]string { return &product.Ranks }},
+		{`SELECT product_id, dimension FROM product_dimensions WHERE product_id IN (` + in + `) ORDER BY product_id, position`,
+			func(product *AmazonProduct) *[]string { return &product.Dimensions }},
+		{`SELECT product_id, feature FROM product_features WHERE product_id IN (` + in + `) ORDER BY product_id, position`,
+			func(product *AmazonProduct) *[]string { return &product.Features }},
+	}
+	for _, child := range children {
+		if err := s.childValues(child.query, args, func(id int64, value string) {
+			values := child.values(byID[id])
+			*values = append(*values, value)
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//childValues reads the product id and one text column of child rows, passing each row to add
+func (s *SQLStore) childValues(query string, args []interface{}, add func(id int64, value string)) error {
+	rows, err := s.db.Query(s.rebind(query), args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			id    int64
+			value string
+		)
+		if err = rows.Scan(&id, &value); err != nil {
+			return err
+		}
+		add(id, value)
+	}
+	return rows.Err()
+}
+
+//sqlPlaceholders returns n comma separated ? placeholders, for IN lists
+func sqlPlaceholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
+
+//inTx runs fn in a transaction, committed if fn succeeds
+func (s *SQLStore) inTx(fn func(tx *sql.Tx) error) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	if err = fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+//rebind turns ? placeholders into the dialect's
+func (s *SQLStore) rebind(query string) string {
+	if !s.dialect.numbered {
+		return query
+	}
+	var (
+		builder strings.Builder
+		n       int
+	)
+	for _, r := range query {
+		if r == '?' {
+			n++
+			builder.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		builder.WriteRune(r)
+	}
+	return builder.String()
+}
@@ -0,0 +1,279 @@
+package v1
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis"
+)
+
+var (
+	//ErrCacheMiss returns if a product isn't cached or its cache expired
+	ErrCacheMiss = errors.New("product not cached")
+)
+
+//ProductStore persists scraped products and the ASINs product codes resolve to
+type ProductStore interface {
+	StoreProduct(product *AmazonProduct) error
+	//FetchProduct returns the last stored copy of a product, however old it is,
+	//or ErrProductNotFound if it was never stored
+	FetchProduct(asin string) (AmazonProduct, error)
+	//FetchProducts returns the stored products of asins in one read, leaving out missing ones
+	FetchProducts(asins []string) ([]AmazonProduct, error)
+	StoreCodeMapping(codeType string, code string, asin string) error
+	//FetchASINByCode returns an empty ASIN if the code hasn't been resolved yet
+	FetchASINByCode(codeType string, code string) (string, error)
+}
+
+//ProductCache keeps recently scraped products and what is known about failing ASINs,
+//everything in it expires
+type ProductCache interface {
+	//GetProduct returns ErrCacheMiss if the product isn't cached
+	GetProduct(asin string) (AmazonProduct, error)
+	AddProduct(product *AmazonProduct, ttl time.Duration) error
+
+	MarkNotFound(asin string, ttl time.Duration) error
+	//NotFoundSince returns a zero time if the ASIN isn't marked as not found
+	NotFoundSince(asin string) (time.Time, error)
+	RecordRobotCheck(asin string, window time.Duration) (int64, error)
+	RobotCheckCount(asin string) (int64, error)
+	ClearNegativeCache(asin string) error
+
+	//TTLState returns the adaptive TTL factor and unchanged refreshes of a product,
+	//a factor of 0 means nothing was recorded yet
+	TTLState(asin string) (factor float64, unchanged int, err error)
+	SetTTLState(asin string, factor float64, unchanged int) error
+}
+
+//ScrapeLocker elects a single replica to scrape an ASIN
+type ScrapeLocker interface {
+	//AcquireScrapeLock returns an empty token if another replica holds the lock
+	AcquireScrapeLock(asin string, ttl time.Duration) (token string, err error)
+	ReleaseScrapeLock(asin string, token string) error
+	ScrapeLocked(asin string) (bool, error)
+}
+
+//RedisStore is the default ProductStore, ProductCache and ScrapeLocker,
+//shared by every replica connected to the same Redis server
+type RedisStore struct {
+	client *redis.Client
+}
+
+//NewRedisStore takes a redis client and returns a store on top of it
+func NewRedisStore(client *redis.Client) *RedisStore {
+	return &RedisStore{client: client}
+}
+
+//StoreProduct saves product with key product:{ASIN}
+func (r *RedisStore) StoreProduct(product *AmazonProduct) error {
+	return StoreProduct(r.client, product)
+}
+
+//FetchProduct reads product:{ASIN}
+func (r *RedisStore) FetchProduct(asin string) (AmazonProduct, error) {
+	return FetchProduct(r.client, asin)
+}
+
+//FetchProducts pipelines reads of product:{ASIN}
+func (r *RedisStore) FetchProducts(asins []string) ([]AmazonProduct, error) {
+	return FetchProducts(r.client, asins)
+}
+
+//ScanProducts walks product:{ASIN} keys with SCAN
+func (r *RedisStore) ScanProducts(cursor string, count int) ([]AmazonProduct, string, error) {
+	return ScanProducts(r.client, cursor, count)
+}
+
+//TopRanked reads index:rank:{category}
+func (r *RedisStore) TopRanked(category string, limit int) ([]AmazonProduct, error) {
+	return TopRankedProducts(r.client, category, limit)
+}
+
+//ScrapedBefore reads index:scraped
+func (r *RedisStore) ScrapedBefore(t time.Time, limit int) ([]AmazonProduct, error) {
+	return ProductsScrapedBefore(r.client, t, limit)
+}
+
+//ScanCategory walks index:category:{category} with SSCAN
+func (r *RedisStore) ScanCategory(category string, cursor string, count int) ([]AmazonProduct, string, error) {
+	return ScanCategory(r.client, category, cursor, count)
+}
+
+//DeleteProduct deletes product:{ASIN}, its indexes and everything cached about it
+func (r *RedisStore) DeleteProduct(asin string) (bool, error) {
+	return DeleteProduct(r.client, asin)
+}
+
+//StoreCodeMapping saves a resolved product code with key code:{type}:{value}
+func (r *RedisStore) StoreCodeMapping(codeType string, code string, asin string) error {
+	return StoreCodeMapping(r.client, codeType, code, asin)
+}
+
+//FetchASINByCode reads code:{type}:{value}
+func (r *RedisStore) FetchASINByCode(codeType string, code string) (string, error) {
+	asin, err := FetchASINByCode(r.client, codeType, code)
+	if err == redis.Nil {
+		return "", nil
+	}
+	return asin, err
+}
+
+//GetProduct reads cacheProduct:{ASIN}
+func (r *RedisStore) GetProduct(asin string) (AmazonProduct, error) {
+	product, err := GetProductFromCache(r.client, asin)
+	if err == redis.Nil {
+		err = ErrCacheMiss
+	}
+	return product, err
+}
+
+//AddProduct caches product with key cacheProduct:{ASIN}
+func (r *RedisStore) AddProduct(product *AmazonProduct, ttl time.Duration) error {
+	return AddProductToCache(r.client, product, ttl)
+}
+
+//InvalidateProducts deletes cacheProduct:{ASIN} of asins
+func (r *RedisStore) InvalidateProducts(asins []string) (int64, error) {
+	return InvalidateCachedProducts(r.client, asins)
+}
+
+//InvalidatePattern deletes cacheProduct:{ASIN} keys matching pattern
+func (r *RedisStore) InvalidatePattern(pattern string) (int64, error) {
+	return InvalidateCachedPattern(r.client, pattern)
+}
+
+//MarkNotFound sets notFound:{ASIN}
+func (r *RedisStore) MarkNotFound(asin string, ttl time.Duration) error {
+	return MarkProductNotFound(r.client, asin, ttl)
+}
+
+//NotFoundSince reads notFound:{ASIN}
+func (r *RedisStore) NotFoundSince(asin string) (time.Time, error) {
+	return ProductNotFoundSince(r.client, asin)
+}
+
+//RecordRobotCheck increments robotCheck:{ASIN}
+func (r *RedisStore) RecordRobotCheck(asin string, window time.Duration) (int64, error) {
+	return RecordRobotCheck(r.client, asin, window)
+}
+
+//RobotCheckCount reads robotCheck:{ASIN}
+func (r *RedisStore) RobotCheckCount(asin string) (int64, error) {
+	return RobotCheckCount(r.client, asin)
+}
+
+//ClearNegativeCache deletes notFound:{ASIN} and robotCheck:{ASIN}
+func (r *RedisStore) ClearNegativeCache(asin string) error {
+	return ClearNegativeCache(r.client, asin)
+}
+
+//TTLState reads the hash cacheTTL:{ASIN}
+func (r *RedisStore) TTLState(asin string) (factor float64, unchanged int, err error) {
+	state, err := r.client.HGetAll("cacheTTL:" + asin).Result()
+	if err != nil {
+		return
+	}
+	//fields that are missing or unreadable start over
+	factor, _ = strconv.ParseFloat(state["factor"], 64)
+	unchanged, _ = strconv.Atoi(state["unchanged"])
+	return factor, unchanged, nil
+}
+
+//SetTTLState writes the hash cacheTTL:{ASIN}
+func (r *RedisStore) SetTTLState(asin string, factor float64, unchanged int) error {
+	return r.client.HMSet("cacheTTL:"+asin, map[string]interface{}{
+		"factor":    strconv.FormatFloat(factor, 'f', -1, 64),
+		"unchanged": unchanged,
+	}).Err()
+}
+
+//Watch saves watchlist:{ASIN} and schedules it in watchlist:due
+func (r *RedisStore) Watch(entry WatchEntry) error {
+	return WatchProduct(r.client, entry)
+}
+
+//Unwatch deletes watchlist:{ASIN} and its schedule
+func (r *RedisStore) Unwatch(asin string) (bool, error) {
+	return UnwatchProduct(r.client, asin)
+}
+
+//ClaimDue leases due products of watchlist:due
+func (r *RedisStore) ClaimDue(now time.Time, limit int, lease time.Duration) ([]WatchEntry, error) {
+	return ClaimDueProducts(r.client, now, limit, lease)
+}
+
+//Reschedule moves a product in watchlist:due
+func (r *RedisStore) Reschedule(asin string, next time.Time) error {
+	return RescheduleProduct(r.client, asin, next)
+}
+
+//AcquireScrapeLock sets lock:scrape:{ASIN}
+func (r *RedisStore) AcquireScrapeLock(asin string, ttl time.Duration) (string, error) {
+	return AcquireScrapeLock(r.client, asin, ttl)
+}
+
+//ReleaseScrapeLock deletes lock:scrape:{ASIN} if it holds token
+func (r *RedisStore) ReleaseScrapeLock(asin string, token string) error {
+	return ReleaseScrapeLock(r.client, asin, token)
+}
+
+//ScrapeLocked checks lock:scrape:{ASIN}
+func (r *RedisStore) ScrapeLocked(asin string) (bool, error) {
+	return ScrapeLocked(r.client, asin)
+}
+
+//AddSnapshot adds product to history:{ASIN}
+func (r *RedisStore) AddSnapshot(product *AmazonProduct, retention HistoryRetention) error {
+	return AddProductSnapshot(r.client, product, retention)
+}
+
+//Snapshots reads history:{ASIN}
+func (r *RedisStore) Snapshots(asin string, from time.Time, to time.Time) ([]AmazonProduct, error) {
+	return FetchProductSnapshots(r.client, asin, from, to)
+}
+
+//CreateJob saves job:{id} and queues it in jobs:due
+func (r *RedisStore) CreateJob(job Job, asins []string) error {
+	return CreateJob(r.client, job, asins)
+}
+
+//Job reads job:{id}
+func (r *RedisStore) Job(id string) (Job, error) {
+	return FetchJob(r.client, id)
+}
+
+//Jobs reads a page of jobs, newest first
+func (r *RedisStore) Jobs(offset int, limit int) ([]Job, error) {
+	return FetchJobs(r.client, offset, limit)
+}
+
+//ClaimJobs leases jobs of jobs:due
+func (r *RedisStore) ClaimJobs(now time.Time, limit int, lease time.Duration) ([]string, error) {
+	return ClaimJobs(r.client, now, limit, lease)
+}
+
+//RenewJob moves a job in jobs:due
+func (r *RedisStore) RenewJob(id string, until time.Time) error {
+	return RenewJob(r.client, id, until)
+}
+
+//JobASINs reads job:{id}:asins
+func (r *RedisStore) JobASINs(id string) ([]string, error) {
+	return FetchJobASINs(r.client, id)
+}
+
+//RecordJobResult saves a result in job:{id}:results
+func (r *RedisStore) RecordJobResult(id string, index int, result JobResult) error {
+	return RecordJobResult(r.client, id, index, result)
+}
+
+//JobResults reads a page of job:{id}:results
+func (r *RedisStore) JobResults(id string, offset int, limit int) ([]JobResult, error) {
+	return FetchJobResults(r.client, id, offset, limit)
+}
+
+//EndJob sets job:{id} done or canceled
+func (r *RedisStore) EndJob(id string, status string) (bool, error) {
+	return EndJob(r.client, id, status)
+}
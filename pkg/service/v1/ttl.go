@@ -0,0 +1,87 @@
+package v1
+
+import (
+	"reflect"
+	"time"
+)
+
+var (
+	//DefaultTTLPolicy caches every product for 20 minutes
+	DefaultTTLPolicy = TTLPolicy{
+		Default:    20 * time.Minute,
+		AdaptAfter: 3,
+		MinTTL:     5 * time.Minute,
+		MaxTTL:     24 * time.Hour,
+	}
+)
+
+//TTLPolicy decides how long a scraped product stays in cacheProduct:{ASIN}
+type TTLPolicy struct {
+	//Default is the TTL of a product before it is adapted
+	Default time.Duration
+
+	//Adaptive lengthens the TTL of products that didn't change for AdaptAfter refreshes,
+	//and shortens it for products that changed, within MinTTL and MaxTTL
+	Adaptive   bool
+	AdaptAfter int
+	MinTTL     time.Duration
+	MaxTTL     time.Duration
+}
+
+//BaseTTL returns Default, or the default policy's if it isn't set
+func (p TTLPolicy) BaseTTL() time.Duration {
+	if p.Default <= 0 {
+		return DefaultTTLPolicy.Default
+	}
+	return p.Default
+}
+
+//AdaptTTL scales base by how often the product changed on its last refreshes,
+//the state is kept in cache
+func (p TTLPolicy) AdaptTTL(cache ProductCache, asin string, base time.Duration, changed bool) (ttl time.Duration, err error) {
+	if !p.Adaptive {
+		return base, nil
+	}
+	factor, unchanged, err := cache.TTLState(asin)
+	if err != nil {
+		return base, err
+	}
+	if factor <= 0 {
+		factor = 1
+	}
+
+	if changed {
+		//volatile product, refresh it more often
+		unchanged = 0
+		factor /= 2
+	} else if unchanged++; p.AdaptAfter > 0 && unchanged >= p.AdaptAfter {
+		//stable for AdaptAfter refreshes, refresh it less often
+		unchanged = 0
+		factor *= 2
+	}
+
+	ttl = time.Duration(float64(base) * factor)
+	if p.MinTTL > 0 && ttl < p.MinTTL {
+		ttl = p.MinTTL
+		factor = float64(p.MinTTL) / float64(base)
+	}
+	if p.MaxTTL > 0 && ttl > p.MaxTTL {
+		ttl = p.MaxTTL
+		factor = float64(p.MaxTTL) / float64(base)
+	}
+
+	err = cache.SetTTLState(asin, factor, unchanged)
+	return
+}
+
+//productChanged compares the scraped fields of two products, ignoring when they were scraped
+func productChanged(previous *AmazonProduct, next *AmazonProduct) bool {
+	return previous.Name != next.Name ||
+		previous.Brand != next.Brand ||
+		!reflect.DeepEqual(previous.Features, next.Features) ||
+		!reflect.DeepEqual(previous.Categories, next.Categories) ||
+		!reflect.DeepEqual(previous.Ranks, next.Ranks) ||
+		!reflect.DeepEqual(previous.Dimensions, next.Dimensions) ||
+		previous.Price != next.Price ||
+		previous.Availability != next.Availability
+}
@@ -0,0 +1,193 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/golang/protobuf/ptypes"
+	v1 "github.com/rnidev/go-webscraper/pkg/api/v1"
+	"github.com/rnidev/go-webscraper/pkg/logger"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	//DefaultWatchInterval is how often a watched product is refreshed unless told otherwise
+	DefaultWatchInterval = 24 * time.Hour
+	//MinWatchInterval keeps a watchlist from scraping a product more often than its cache expires
+	MinWatchInterval = time.Minute
+	//DefaultWatchlistConcurrency is how many refreshes a scheduler runs at once
+	DefaultWatchlistConcurrency = 4
+	//watchlistRateKey is the budget RateLimiter shares between the schedulers of every replica
+	watchlistRateKey = "watchlist"
+)
+
+var (
+	//watchLease is how long a claimed product isn't claimed again,
+	//it has to outlast a refresh with all its retries
+	watchLease = 5 * time.Minute
+
+	//ErrWatchIntervalTooShort returns if a watchlist interval is below MinWatchInterval
+	ErrWatchIntervalTooShort = errors.New("interval must be at least a minute")
+)
+
+//WatchEntry is a product refreshed in the background every Interval
+type WatchEntry struct {
+	Asin        string
+	Marketplace string
+	Interval    time.Duration
+	NextRefresh time.Time
+}
+
+//WatchlistStore keeps the watchlist and hands out due products, a ProductCache may implement it
+type WatchlistStore interface {
+	//Watch adds or replaces the entry of a product
+	Watch(entry WatchEntry) error
+	//Unwatch removes a product, removed is false if it wasn't watched
+	Unwatch(asin string) (removed bool, err error)
+	//ClaimDue returns up to limit products due at now and postpones them by lease,
+	//a product is never claimed twice within a lease, not even by other replicas
+	ClaimDue(now time.Time, limit int, lease time.Duration) ([]WatchEntry, error)
+	//Reschedule sets the next refresh of a watched product, it ignores products that aren't watched
+	Reschedule(asin string, next time.Time) error
+}
+
+//WatchlistScheduler refreshes watched products once they are due
+type WatchlistScheduler struct {
+	Store WatchlistStore
+	//Rate is how many refreshes per second this scheduler starts at most
+	Rate float64
+	//Limiter shares the refresh budget with other replicas, nil only limits this one
+	Limiter RateLimiter
+	//Concurrency is how many refreshes run at once, a tick while all of them
+	//are still running claims nothing, so slow scrapes don't pile up
+	Concurrency int
+
+	server v1.WebScraperServer
+}
+
+//NewWatchlistScheduler takes the server refreshing products, the watchlist and refreshes per second,
+//a rate of 0 or less refreshes nothing
+func NewWatchlistScheduler(server v1.WebScraperServer, store WatchlistStore, rate float64) *WatchlistScheduler {
+	if rate < 0 {
+		rate = 0
+	}
+	return &WatchlistScheduler{Store: store, Rate: rate, Concurrency: DefaultWatchlistConcurrency, server: server}
+}
+
+//Run claims and refreshes a due product at every tick of Rate until ctx is done,
+//then waits for the refreshes it started. It returns right away if Rate is 0 or less
+func (w *WatchlistScheduler) Run(ctx context.Context) {
+	if w.Rate <= 0 {
+		return
+	}
+	concurrency := w.Concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	slots := make(chan struct{}, concurrency)
+	defer func() {
+		for slot := 0; slot < concurrency; slot++ {
+			slots <- struct{}{}
+		}
+	}()
+	ticker := time.NewTicker(time.Duration(float64(time.Second) / w.Rate))
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		select {
+		case slots <- struct{}{}:
+		default:
+			continue
+		}
+		entries, err := w.Store.ClaimDue(time.Now(), 1, watchLease)
+		if err != nil {
+			logger.Log.Warn("failed to claim watched products", zap.String("error", err.Error()))
+		}
+		if len(entries) == 0 {
+			<-slots
+			continue
+		}
+		if w.Limiter != nil {
+			if err := w.Limiter.Wait(ctx, watchlistRateKey); err != nil {
+				<-slots
+				return
+			}
+		}
+		go func(entry WatchEntry) {
+			defer func() { <-slots }()
+			w.refresh(ctx, entry)
+		}(entries[0])
+	}
+}
+
+//refresh scrapes a watched product and schedules its next refresh,
+//a failed refresh waits for the next interval too so a broken ASIN doesn't hog the budget
+func (w *WatchlistScheduler) refresh(ctx context.Context, entry WatchEntry) {
+	_, err := w.server.RefreshProduct(ctx, &v1.RefreshProductRequest{Asin: entry.Asin, Marketplace: entry.Marketplace})
+	if err != nil {
+		logger.Log.Warn("failed to refresh watched product",
+			zap.String("asin", entry.Asin), zap.String("error", err.Error()))
+	}
+	if err = w.Store.Reschedule(entry.Asin, time.Now().Add(entry.Interval)); err != nil {
+		logger.Log.Warn("failed to reschedule watched product",
+			zap.String("asin", entry.Asin), zap.String("error", err.Error()))
+	}
+}
+
+//AddToWatchlist refreshes a product in the background every interval, starting now
+func (s *webScraperServer) AddToWatchlist(ctx context.Context, req *v1.AddToWatchlistRequest) (*v1.AddToWatchlistResponse, error) {
+	asin, marketplace, err := NormalizeASIN(req.Asin)
+	if err != nil {
+		return &v1.AddToWatchlistResponse{}, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if req.Marketplace != "" {
+		if marketplace, err = NormalizeMarketplace(req.Marketplace); err != nil {
+			return &v1.AddToWatchlistResponse{}, status.Error(codes.InvalidArgument, err.Error())
+		}
+	}
+	interval := DefaultWatchInterval
+	if req.Interval != 0 {
+		interval = time.Duration(req.Interval) * time.Second
+	}
+	if interval < MinWatchInterval {
+		return &v1.AddToWatchlistResponse{}, status.Error(codes.InvalidArgument, ErrWatchIntervalTooShort.Error())
+	}
+	watchlist, ok := s.cache.(WatchlistStore)
+	if !ok {
+		return &v1.AddToWatchlistResponse{}, status.Error(codes.Unimplemented, "the watchlist isn't supported by this storage")
+	}
+
+	entry := WatchEntry{Asin: asin, Marketplace: marketplace, Interval: interval, NextRefresh: time.Now()}
+	if err = watchlist.Watch(entry); err != nil {
+		return &v1.AddToWatchlistResponse{}, err
+	}
+	nextRefresh, err := ptypes.TimestampProto(entry.NextRefresh)
+	if err != nil {
+		return &v1.AddToWatchlistResponse{}, err
+	}
+	return &v1.AddToWatchlistResponse{Asin: asin, NextRefresh: nextRefresh}, nil
+}
+
+//RemoveFromWatchlist stops refreshing a product in the background
+func (s *webScraperServer) RemoveFromWatchlist(ctx context.Context, req *v1.RemoveFromWatchlistRequest) (*v1.RemoveFromWatchlistResponse, error) {
+	asin, _, err := NormalizeASIN(req.Asin)
+	if err != nil {
+		return &v1.RemoveFromWatchlistResponse{}, status.Error(codes.InvalidArgument, err.Error())
+	}
+	watchlist, ok := s.cache.(WatchlistStore)
+	if !ok {
+		return &v1.RemoveFromWatchlistResponse{}, status.Error(codes.Unimplemented, "the watchlist isn't supported by this storage")
+	}
+	removed, err := watchlist.Unwatch(asin)
+	if err != nil {
+		return &v1.RemoveFromWatchlistResponse{}, err
+	}
+	return &v1.RemoveFromWatchlistResponse{Removed: removed}, nil
+}
@@ -3,6 +3,7 @@ package v1
 import (
 	"context"
 	"errors"
+	"net/http"
 	"time"
 
 	"github.com/go-redis/redis"
@@ -10,88 +11,309 @@ import (
 	v1 "github.com/rnidev/go-webscraper/pkg/api/v1"
 	"github.com/rnidev/go-webscraper/pkg/logger"
 	"go.uber.org/zap"
+	"golang.org/x/sync/singleflight"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 type webScraperServer struct {
-	redisdb *redis.Client
+	store   ProductStore
+	cache   ProductCache
+	locker  ScrapeLocker
+	scraper *Scraper
+	opts    ServerOptions
+	//inflight coalesces concurrent scrapes of the same ASIN in this process
+	inflight singleflight.Group
 }
 
 var (
 	//ErrMissingASIN is shared error message, returns if asin is missing
 	ErrMissingASIN = errors.New("missing ASIN in request")
-	//defaultTTL is the default time duration for cached product
-	defaultTTL = time.Duration(int64(20)) * time.Minute
 )
 
-//NewScraperServer takes a new redis client for scraper server
-func NewScraperServer(client *redis.Client) v1.WebScraperServer {
-	return &webScraperServer{redisdb: client}
+//ServerOptions tunes how the scraper server uses its cache and storage
+type ServerOptions struct {
+	//MaxStaleness is how old a stored product may be to be served while it is refreshed
+	//in the background once its cache expired, 0 always waits for a fresh scrape
+	MaxStaleness time.Duration
+	//NotFoundTTL is how long an ASIN without a product page answers NotFound
+	//without being scraped again, 0 disables negative caching
+	NotFoundTTL time.Duration
+	//MaxRobotChecks is how many robot check pages an ASIN may get within RobotCheckWindow
+	//before it isn't scraped for the rest of the window, 0 disables the limit
+	MaxRobotChecks   int
+	RobotCheckWindow time.Duration
+	//History limits the product history kept by stores that keep one
+	History HistoryRetention
+	//TTL decides how long scraped products are cached, a zero Default uses DefaultTTLPolicy
+	TTL TTLPolicy
+	//Search indexes stored products for SearchStoredProducts, nil disables search
+	Search ProductSearch
+	//AdminTokens are bearer tokens allowed to call admin RPCs, empty disables them
+	AdminTokens []string
+	//Queue hands scrapes to workers instead of scraping in process, nil scrapes in process
+	Queue ScrapeQueue
+}
+
+//NewScraperServer takes a new redis client, a scraper and options for scraper server,
+//a nil scraper falls back to DefaultScraper
+func NewScraperServer(client *redis.Client, scraper *Scraper, opts ServerOptions) v1.WebScraperServer {
+	redisStore := NewRedisStore(client)
+	return NewScraperServerWithStorage(redisStore, redisStore, redisStore, scraper, opts)
+}
+
+//NewScraperServerWithStorage is NewScraperServer on any storage backend,
+//a nil locker means this is the only replica scraping
+func NewScraperServerWithStorage(store ProductStore, cache ProductCache, locker ScrapeLocker, scraper *Scraper, opts ServerOptions) v1.WebScraperServer {
+	if scraper == nil {
+		scraper = DefaultScraper
+	}
+	if opts.TTL.Default <= 0 {
+		opts.TTL.Default = DefaultTTLPolicy.Default
+	}
+	if opts.RobotCheckWindow <= 0 {
+		opts.RobotCheckWindow = time.Hour
+	}
+	return &webScraperServer{store: store, cache: cache, locker: locker, scraper: scraper, opts: opts}
 }
 
 //GetProduct returns GetProductResponse and error
 func (s *webScraperServer) GetProduct(ctx context.Context, req *v1.GetProductRequest) (*v1.GetProductResponse, error) {
-	//validation
-	if req.Asin == "" {
-		return &v1.GetProductResponse{}, ErrMissingASIN
+	//validation, normalize ASIN before it becomes part of a URL or Redis key
+	asin, marketplace, err := NormalizeASIN(req.Asin)
+	if err != nil {
+		return &v1.GetProductResponse{}, status.Error(codes.InvalidArgument, err.Error())
+	}
+	opts, err := cacheOptionsFromRequest(ctx, req)
+	if err != nil {
+		return &v1.GetProductResponse{}, status.Error(codes.InvalidArgument, err.Error())
 	}
-	//Get a new redis client with context
-	var product v1.Product
-	cachedProduct, err := GetProductFromCache(s.redisdb, req.Asin)
 
-	// ignore redis.Nil for error return.
-	// It means there is not existing key for cachedProduct,
-	if err != nil && err != redis.Nil {
+	product, stale, err := s.lookupProduct(ctx, asin, marketplace, opts)
+	if err != nil {
 		return &v1.GetProductResponse{}, err
 	}
-	// Found cached product
-	if cachedProduct.Name != "" {
-		product, err = mapProduct(&cachedProduct)
+	return &v1.GetProductResponse{
+		Product: product,
+		Stale:   stale,
+	}, nil
+}
+
+//GetProductByCode resolves a UPC, EAN or GTIN to an ASIN, then returns the product like GetProduct
+func (s *webScraperServer) GetProductByCode(ctx context.Context, req *v1.GetProductByCodeRequest) (*v1.GetProductByCodeResponse, error) {
+	codeType, code, err := NormalizeCode(req.CodeType, req.Code)
+	if err != nil {
+		return &v1.GetProductByCodeResponse{}, status.Error(codes.InvalidArgument, err.Error())
+	}
+	marketplace, err := NormalizeMarketplace(req.Marketplace)
+	if err != nil {
+		return &v1.GetProductByCodeResponse{}, status.Error(codes.InvalidArgument, err.Error())
+	}
 
+	// an empty ASIN means the code hasn't been resolved before
+	asin, err := s.store.FetchASINByCode(codeType, code)
+	if err != nil {
+		return &v1.GetProductByCodeResponse{}, err
+	}
+	if asin == "" {
+		if s.opts.Queue != nil {
+			asin, err = s.queueCodeLookup(ctx, codeType, code, marketplace)
+		} else {
+			asin, err = s.resolveCode(ctx, codeType, code, marketplace)
+		}
 		if err != nil {
-			return &v1.GetProductResponse{}, err
+			return &v1.GetProductByCodeResponse{}, err
 		}
-
-		return &v1.GetProductResponse{
-			Product: &product,
-		}, nil
 	}
 
-	//No cached product found, start product scraping
-	var scrapedProduct AmazonProduct
-	scrapedProduct.Asin = req.Asin
-	res, err := scrapedProduct.GetProductInfoByASIN()
+	product, stale, err := s.lookupProduct(ctx, asin, marketplace, cacheOptions{})
+	if err != nil {
+		return &v1.GetProductByCodeResponse{}, err
+	}
+	return &v1.GetProductByCodeResponse{
+		Product: product,
+		Stale:   stale,
+	}, nil
+}
 
+//resolveCode scrapes the ASIN a product code resolves to and saves the mapping
+func (s *webScraperServer) resolveCode(ctx context.Context, codeType string, code string, marketplace string) (string, error) {
+	asin, res, err := s.scraper.SearchASINByCode(ctx, marketplace, code)
 	if err != nil {
 		//if something wrong with scraper service, we want to see the response
-		logger.Log.Info("", zap.String("response:", string(res.Body)))
-		return &v1.GetProductResponse{}, err
+		if res != nil {
+			logger.Log.Info("", zap.String("response:", string(res.Body)))
+		}
+		return "", err
 	}
+	if asin == "" {
+		return "", status.Errorf(codes.NotFound, "no product found for %s %s", codeType, code)
+	}
+	return asin, s.store.StoreCodeMapping(codeType, code, asin)
+}
 
-	// Successfuly scraped product
-	if len(scrapedProduct.Name) > 0 {
-		scrapedProduct.CreatedAt = time.Now().In(time.UTC).Format(time.RFC3339Nano)
-		//Save product to Redis as in-memory database
-		err = StoreProduct(s.redisdb, &scrapedProduct)
-		if err != nil {
-			return &v1.GetProductResponse{}, err
+//RefreshProduct scrapes a product regardless of its cache, stores and caches it,
+//and returns what changed from the stored copy
+func (s *webScraperServer) RefreshProduct(ctx context.Context, req *v1.RefreshProductRequest) (*v1.RefreshProductResponse, error) {
+	asin, marketplace, err := NormalizeASIN(req.Asin)
+	if err != nil {
+		return &v1.RefreshProductResponse{}, status.Error(codes.InvalidArgument, err.Error())
+	}
+	previousProduct, err := s.store.FetchProduct(asin)
+	if err != nil && err != ErrProductNotFound {
+		return &v1.RefreshProductResponse{}, err
+	}
+	stored := err == nil
+	if req.Marketplace != "" {
+		if marketplace, err = NormalizeMarketplace(req.Marketplace); err != nil {
+			return &v1.RefreshProductResponse{}, status.Error(codes.InvalidArgument, err.Error())
 		}
-		//Add product to cache for default time to live
-		err = AddProductToCache(s.redisdb, &scrapedProduct, defaultTTL)
+	}
+	//a copy from another marketplace is a different product, there is nothing to compare
+	stored = stored && fromMarketplace(&previousProduct, marketplace)
+
+	//only a scrape started after now counts, even if it was another caller's
+	scrapedProduct, err := s.coalescedScrape(ctx, asin, marketplace, time.Now())
+	if err != nil {
+		return &v1.RefreshProductResponse{}, err
+	}
+	product, err := mapProduct(&scrapedProduct)
+	if err != nil {
+		return &v1.RefreshProductResponse{}, err
+	}
+	res := &v1.RefreshProductResponse{Product: &product}
+	if stored {
+		previous, err := mapProduct(&previousProduct)
 		if err != nil {
-			return &v1.GetProductResponse{}, err
+			return &v1.RefreshProductResponse{}, err
+		}
+		res.Previous = &previous
+		res.Changes = diffProducts(&previousProduct, &scrapedProduct)
+	}
+	return res, nil
+}
+
+//lookupProduct returns a normalized ASIN's product from cache, or scrapes and stores it.
+//stale is true if the cache expired and the stored copy is served while it is refreshed
+func (s *webScraperServer) lookupProduct(ctx context.Context, asin string, marketplace string, opts cacheOptions) (_ *v1.Product, stale bool, err error) {
+	var product v1.Product
+	notBefore := opts.notBefore()
+	if !opts.forceRefresh {
+		cachedProduct, err := s.cache.GetProduct(asin)
+
+		// ignore ErrCacheMiss for error return.
+		// It means there is no cached product,
+		if err != nil && err != ErrCacheMiss {
+			return nil, false, err
+		}
+		// Found cached product, a copy from another marketplace is a different product
+		if fromMarketplace(&cachedProduct, marketplace) && scrapedSince(&cachedProduct, notBefore) {
+			product, err = mapProduct(&cachedProduct)
+
+			if err != nil {
+				return nil, false, err
+			}
+
+			return &product, false, nil
 		}
+
+		//Cache expired, serve the last stored copy if it is recent enough
+		if storedProduct, ok := s.staleProduct(asin, marketplace); ok && scrapedSince(&storedProduct, notBefore) {
+			//cache only callers never cause a scrape, not even in the background
+			if !opts.cacheOnly {
+				s.refreshInBackground(asin, marketplace)
+			}
+			product, err = mapProduct(&storedProduct)
+			return &product, true, err
+		}
+	}
+	if opts.cacheOnly {
+		return nil, false, status.Errorf(codes.NotFound, "product %s is not cached", asin)
+	}
+
+	//Don't spend requests on ASINs known to be missing or blocked
+	if err = s.checkNegativeCache(asin, notBefore); err != nil {
+		return nil, false, err
 	}
 
+	//No cached product found, scrape it once for every concurrent caller
+	scrapedProduct, err := s.coalescedScrape(ctx, asin, marketplace, notBefore)
+	if err != nil {
+		return nil, false, err
+	}
 	product, err = mapProduct(&scrapedProduct)
 
-	return &v1.GetProductResponse{
-		Product: &product,
-	}, err
+	return &product, false, err
+}
+
+//scrapeAndStore scrapes a product, then saves and caches it if it was found
+func (s *webScraperServer) scrapeAndStore(ctx context.Context, asin string, marketplace string) (scrapedProduct AmazonProduct, err error) {
+	scrapedProduct.Asin = asin
+	scrapedProduct.Marketplace = marketplace
+	res, err := s.scraper.ScrapeProduct(ctx, &scrapedProduct)
+
+	if err != nil {
+		//if something wrong with scraper service, we want to see the response
+		if res != nil {
+			logger.Log.Info("", zap.String("response:", string(res.Body)))
+		}
+		if scrapeErr, ok := err.(*ScrapeError); ok {
+			if scrapeErr.StatusCode == http.StatusNotFound {
+				err = s.markNotFound(asin)
+			} else if scrapeErr.Err == ErrRobotCheck {
+				s.recordRobotCheck(asin)
+			}
+		}
+		return
+	}
+	//The page has no product, remember it for a while
+	if len(scrapedProduct.Name) == 0 {
+		err = s.markNotFound(asin)
+		return
+	}
+
+	// Successfuly scraped product
+	scrapedProduct.CreatedAt = time.Now().In(time.UTC).Format(time.RFC3339Nano)
+	//Compare with the stored copy before it is overwritten, a product scraped for the first time counts as changed
+	changed := true
+	if previousProduct, err := s.store.FetchProduct(scrapedProduct.Asin); err == nil {
+		changed = productChanged(&previousProduct, &scrapedProduct)
+	}
+	//Save product to the product store
+	err = s.store.StoreProduct(&scrapedProduct)
+	if err != nil {
+		return
+	}
+	s.recordSnapshot(&scrapedProduct)
+	s.indexForSearch(&scrapedProduct)
+	//Add product to cache for the time to live of its policy
+	err = s.cache.AddProduct(&scrapedProduct, s.cacheTTL(&scrapedProduct, changed))
+	if err != nil {
+		return
+	}
+	//The ASIN works again, forget earlier not found or robot check results
+	err = s.cache.ClearNegativeCache(asin)
+	return
+}
+
+//cacheTTL is how long a freshly scraped product is cached,
+//if adaptive TTLs can't be tracked the policy's base TTL is used
+func (s *webScraperServer) cacheTTL(product *AmazonProduct, changed bool) time.Duration {
+	base := s.opts.TTL.BaseTTL()
+	ttl, err := s.opts.TTL.AdaptTTL(s.cache, product.Asin, base, changed)
+	if err != nil {
+		logger.Log.Warn("adaptive cache TTL unavailable",
+			zap.String("asin", product.Asin), zap.String("error", err.Error()))
+		return base
+	}
+	return ttl
 }
 
 func mapProduct(scrapedProduct *AmazonProduct) (product v1.Product, err error) {
 	product.Asin = scrapedProduct.Asin
 	product.Name = scrapedProduct.Name
+	product.Brand = scrapedProduct.Brand
+	product.Features = scrapedProduct.Features
 
 	for index, category := range scrapedProduct.Categories {
 		product.Categories = append(product.Categories, &v1.ProductCategory{
@@ -108,6 +330,9 @@ func mapProduct(scrapedProduct *AmazonProduct) (product v1.Product, err error) {
 	}
 
 	product.Dimensions = scrapedProduct.Dimensions
+	product.Price = scrapedProduct.Price
+	product.Availability = scrapedProduct.Availability
+	product.Marketplace = scrapedProduct.Marketplace
 
 	if scrapedProduct.CreatedAt != "" {
 		var t time.Time
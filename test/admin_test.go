@@ -0,0 +1,107 @@
+package v1
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	api "github.com/rnidev/go-webscraper/pkg/api/v1"
+	v1 "github.com/rnidev/go-webscraper/pkg/service/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func adminContext(authorization string) context.Context {
+	return metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", authorization))
+}
+
+func TestAdminAuthorization(t *testing.T) {
+	c := newTestRedis()
+	server := newTestServer(c, v1.ServerOptions{AdminTokens: []string{"secret"}})
+	tests := []struct {
+		name string
+		ctx  context.Context
+		want codes.Code
+	}{
+		{"missing", context.Background(), codes.Unauthenticated},
+		{"wrong", adminContext("Bearer guess"), codes.PermissionDenied},
+		{"not bearer", adminContext("Basic secret"), codes.PermissionDenied},
+		{"valid", adminContext("bearer secret"), codes.OK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := server.DeleteProduct(tt.ctx, &api.DeleteProductRequest{Asin: "B07FSH5L52"})
+			if status.Code(err) != tt.want {
+				t.Errorf("DeleteProduct() error = %v, expect %v", err, tt.want)
+			}
+		})
+	}
+	disabled := newTestServer(c, v1.ServerOptions{})
+	if _, err := disabled.InvalidateCache(adminContext("Bearer secret"), &api.InvalidateCacheRequest{Pattern: "*"}); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("InvalidateCache() without admin tokens error = %v, expect PermissionDenied", err)
+	}
+}
+
+func TestDeleteProduct(t *testing.T) {
+	c := newTestRedis()
+	c.FlushDB()
+	product := listedProducts()[0]
+	if err := v1.StoreProduct(c, &product); err != nil {
+		t.Fatalf("v1.StoreProduct() error = %v", err)
+	}
+	v1.AddProductToCache(c, &product, time.Hour)
+	v1.AddProductSnapshot(c, &product, v1.HistoryRetention{})
+	search := v1.NewRediSearchIndex(c)
+	search.Index(&product)
+
+	server := newTestServer(c, v1.ServerOptions{AdminTokens: []string{"secret"}, Search: search})
+	res, err := server.DeleteProduct(adminContext("Bearer secret"), &api.DeleteProductRequest{Asin: product.Asin})
+	if err != nil || !res.Deleted {
+		t.Fatalf("DeleteProduct() = %v, %v, expect deleted", res, err)
+	}
+	//the search hash goes too
+	if keys, _ := c.Keys("*").Result(); len(keys) != 0 {
+		t.Errorf("keys after DeleteProduct() = %v, expect none", keys)
+	}
+	if res, err = server.DeleteProduct(adminContext("Bearer secret"), &api.DeleteProductRequest{Asin: product.Asin}); err != nil || res.Deleted {
+		t.Errorf("DeleteProduct() again = %v, %v, expect not deleted", res, err)
+	}
+}
+
+func TestInvalidateCache(t *testing.T) {
+	c := newTestRedis()
+	c.FlushDB()
+	memory := v1.NewMemoryStore(0)
+	scraper := v1.NewScraper(v1.RetryPolicy{MaxAttempts: 1}, nil)
+	opts := v1.ServerOptions{AdminTokens: []string{"secret"}}
+	servers := map[string]api.WebScraperServer{
+		"redis":  newTestServer(c, opts),
+		"memory": v1.NewScraperServerWithStorage(memory, memory, nil, scraper, opts),
+	}
+	for _, asin := range []string{"B07FSH5L52", "B07FSH5L53", "B004QWYCVG", "B01644OCVS"} {
+		product := v1.AmazonProduct{Asin: asin, Name: "Dress", Categories: []string{"Clothing"}}
+		v1.AddProductToCache(c, &product, time.Hour)
+		memory.AddProduct(&product, time.Hour)
+	}
+
+	for name, server := range servers {
+		ctx := adminContext("Bearer secret")
+		res, err := server.InvalidateCache(ctx, &api.InvalidateCacheRequest{Pattern: "b07*"})
+		if err != nil || res.Invalidated != 2 {
+			t.Errorf("%s InvalidateCache(b07*) = %v, %v, expect 2 invalidated", name, res, err)
+		}
+		res, err = server.InvalidateCache(ctx, &api.InvalidateCacheRequest{Asins: []string{"B004QWYCVG", "B07FSH5L52"}})
+		if err != nil || res.Invalidated != 1 {
+			t.Errorf("%s InvalidateCache(asins) = %v, %v, expect 1 invalidated", name, res, err)
+		}
+		for _, req := range []api.InvalidateCacheRequest{{}, {Pattern: "B0[7]*"}, {Asins: []string{"not an asin"}}} {
+			if _, err := server.InvalidateCache(ctx, &req); status.Code(err) != codes.InvalidArgument {
+				t.Errorf("%s InvalidateCache(%v) error = %v, expect InvalidArgument", name, req, err)
+			}
+		}
+	}
+	if _, err := memory.GetProduct("B01644OCVS"); err != nil {
+		t.Errorf("GetProduct() of a product that wasn't invalidated error = %v", err)
+	}
+}
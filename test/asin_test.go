@@ -0,0 +1,117 @@
+package v1
+
+import (
+	"testing"
+
+	v1 "github.com/rnidev/go-webscraper/pkg/service/v1"
+)
+
+func TestNormalizeASIN(t *testing.T) {
+	tests := []struct {
+		subject     string
+		input       string
+		asin        string
+		marketplace string
+		err         error
+	}{
+		{
+			subject:     "Test ASIN",
+			input:       "B07FSH5L52",
+			asin:        "B07FSH5L52",
+			marketplace: "www.amazon.com",
+		},
+		{
+			subject:     "Test lower case ASIN with spaces",
+			input:       "  b07fsh5l52 ",
+			asin:        "B07FSH5L52",
+			marketplace: "www.amazon.com",
+		},
+		{
+			subject:     "Test ISBN-10",
+			input:       "0-306-40615-2",
+			asin:        "0306406152",
+			marketplace: "www.amazon.com",
+		},
+		{
+			subject:     "Test ISBN-13",
+			input:       "978-0-306-40615-7",
+			asin:        "0306406152",
+			marketplace: "www.amazon.com",
+		},
+		{
+			subject:     "Test ISBN-13 with X check digit",
+			input:       "9780804429573",
+			asin:        "080442957X",
+			marketplace: "www.amazon.com",
+		},
+		{
+			subject:     "Test product URL",
+			input:       "https://www.amazon.co.uk/Longwu-Womens-Casual/dp/B07FSH5L52/ref=sr_1_1?keywords=dress",
+			asin:        "B07FSH5L52",
+			marketplace: "www.amazon.co.uk",
+		},
+		{
+			subject:     "Test gp product URL without scheme",
+			input:       "smile.amazon.de/gp/product/b07fsh5l52",
+			asin:        "B07FSH5L52",
+			marketplace: "www.amazon.de",
+		},
+		{
+			subject: "Test missing ASIN",
+			input:   " ",
+			err:     v1.ErrMissingASIN,
+		},
+		{
+			subject: "Test ASIN with slash",
+			input:   "B07FSH5L5/",
+			err:     v1.ErrInvalidProductURL,
+		},
+		{
+			subject: "Test ASIN with space",
+			input:   "B07F SH5L52",
+			err:     v1.ErrInvalidASIN,
+		},
+		{
+			subject: "Test ISBN-10 bad checksum",
+			input:   "0306406153",
+			err:     v1.ErrInvalidASIN,
+		},
+		{
+			subject: "Test ISBN-13 bad checksum",
+			input:   "9780306406158",
+			err:     v1.ErrInvalidISBN,
+		},
+		{
+			subject: "Test ISBN-13 without ISBN-10",
+			input:   "9791234567896",
+			err:     v1.ErrInvalidISBN,
+		},
+		{
+			subject: "Test non Amazon URL",
+			input:   "https://example.com/dp/B07FSH5L52",
+			err:     v1.ErrInvalidProductURL,
+		},
+		{
+			subject: "Test URL on an unknown Amazon TLD",
+			input:   "https://www.amazon.xyz/dp/B07FSH5L52",
+			err:     v1.ErrInvalidProductURL,
+		},
+		{
+			subject: "Test URL on a non retail Amazon host",
+			input:   "https://aws.amazon.com/dp/B07FSH5L52",
+			err:     v1.ErrInvalidProductURL,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.subject, func(t *testing.T) {
+			asin, marketplace, err := v1.NormalizeASIN(test.input)
+			if err != test.err {
+				t.Errorf("v1.NormalizeASIN() error = %v, expect Err %v", err, test.err)
+				return
+			}
+			if asin != test.asin || marketplace != test.marketplace {
+				t.Errorf("v1.NormalizeASIN() = %s, %s, expect %s, %s", asin, marketplace, test.asin, test.marketplace)
+			}
+		})
+	}
+}
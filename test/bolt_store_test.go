@@ -0,0 +1,106 @@
+package v1
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	v1 "github.com/rnidev/go-webscraper/pkg/service/v1"
+)
+
+func TestBoltStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "webscraper")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "webscraper.db")
+
+	store, err := v1.NewBoltStore(path)
+	if err != nil {
+		t.Fatalf("v1.NewBoltStore() error = %v", err)
+	}
+	product := v1.AmazonProduct{
+		Asin:       "B07FSH5L52",
+		Name:       "Dress; with a semicolon",
+		Categories: []string{"Clothing, Shoes & Jewelry"},
+		Ranks:      []string{"#1 in Dresses"},
+		CreatedAt:  time.Now().In(time.UTC).Format(time.RFC3339Nano),
+	}
+	if err := store.StoreProduct(&product); err != nil {
+		t.Fatalf("StoreProduct() error = %v", err)
+	}
+	if err := store.AddProduct(&product, 50*time.Millisecond); err != nil {
+		t.Fatalf("AddProduct() error = %v", err)
+	}
+	if err := store.StoreCodeMapping(v1.CodeTypeUPC, "042100005264", product.Asin); err != nil {
+		t.Fatalf("StoreCodeMapping() error = %v", err)
+	}
+	if count, _ := store.RecordRobotCheck("B004QWYCVG", time.Minute); count != 1 {
+		t.Errorf("RecordRobotCheck() = %d, expect 1", count)
+	}
+	if count, _ := store.RecordRobotCheck("B004QWYCVG", time.Minute); count != 2 {
+		t.Errorf("RecordRobotCheck() = %d, expect 2", count)
+	}
+	if cached, err := store.GetProduct(product.Asin); err != nil || cached.Name != product.Name {
+		t.Errorf("GetProduct() = %v, %v, expect %s", cached, err, product.Name)
+	}
+	store.Close()
+
+	//products survive a restart, the expired cache doesn't
+	time.Sleep(60 * time.Millisecond)
+	store, err = v1.NewBoltStore(path)
+	if err != nil {
+		t.Fatalf("v1.NewBoltStore() reopen error = %v", err)
+	}
+	defer store.Close()
+	fetched, err := store.FetchProduct(product.Asin)
+	if err != nil || fetched.Name != product.Name || len(fetched.Ranks) != 1 {
+		t.Errorf("FetchProduct() = %v, %v, expect %v", fetched, err, product)
+	}
+	if _, err := store.GetProduct(product.Asin); err != v1.ErrCacheMiss {
+		t.Errorf("GetProduct() expired error = %v, expect %v", err, v1.ErrCacheMiss)
+	}
+	if asin, _ := store.FetchASINByCode(v1.CodeTypeUPC, "042100005264"); asin != product.Asin {
+		t.Errorf("FetchASINByCode() = %q, expect %s", asin, product.Asin)
+	}
+	if count, _ := store.RobotCheckCount("B004QWYCVG"); count != 2 {
+		t.Errorf("RobotCheckCount() = %d, expect 2", count)
+	}
+	for _, snapshot := range snapshotsAt(9, 5, 1) {
+		if err := store.AddSnapshot(&snapshot, v1.HistoryRetention{MaxSnapshots: 2}); err != nil {
+			t.Fatalf("AddSnapshot() error = %v", err)
+		}
+	}
+	snapshots, err := store.Snapshots("B07FSH5L52", time.Unix(0, 0), time.Now())
+	if err != nil || len(snapshots) != 2 || snapshots[0].Ranks[0] != "#5 in Dresses" {
+		t.Errorf("Snapshots() = %v, %v, expect #5 then #1", snapshots, err)
+	}
+	//snapshots scraped in the same nanosecond are both kept, and one at the end of the range is included
+	same := snapshotsAt(3)[0]
+	same.Asin = "B004QWYCVG"
+	scrapedAt, _ := time.Parse(time.RFC3339Nano, same.CreatedAt)
+	for i := 0; i < 2; i++ {
+		if err := store.AddSnapshot(&same, v1.HistoryRetention{}); err != nil {
+			t.Fatalf("AddSnapshot() error = %v", err)
+		}
+	}
+	if snapshots, err := store.Snapshots(same.Asin, scrapedAt, scrapedAt); err != nil || len(snapshots) != 2 {
+		t.Errorf("Snapshots() same time = %d snapshots, %v, expect 2", len(snapshots), err)
+	}
+	if _, err := store.FetchProduct("B002QYW8LW"); err == nil {
+		t.Errorf("FetchProduct() missing product error = nil, expect an error")
+	}
+
+	if deleted, err := store.DeleteProduct(product.Asin); err != nil || !deleted {
+		t.Errorf("DeleteProduct() = %v, %v, expect deleted", deleted, err)
+	}
+	if _, err := store.FetchProduct(product.Asin); err != v1.ErrProductNotFound {
+		t.Errorf("FetchProduct() deleted error = %v, expect %v", err, v1.ErrProductNotFound)
+	}
+	if snapshots, _ := store.Snapshots(product.Asin, time.Unix(0, 0), time.Now()); len(snapshots) != 0 {
+		t.Errorf("Snapshots() deleted = %v, expect none", snapshots)
+	}
+}
@@ -0,0 +1,200 @@
+package v1
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redis"
+	api "github.com/rnidev/go-webscraper/pkg/api/v1"
+	"github.com/rnidev/go-webscraper/pkg/logger"
+	v1 "github.com/rnidev/go-webscraper/pkg/service/v1"
+)
+
+func TestScrapeLock(t *testing.T) {
+	c := newTestRedis()
+	c.FlushDB()
+
+	leader, err := v1.AcquireScrapeLock(c, "B07FSH5L52", time.Minute)
+	if err != nil || leader == "" {
+		t.Fatalf("v1.AcquireScrapeLock() = %q, %v, expect a token", leader, err)
+	}
+	//a second replica can't take the lock while it is held
+	follower, err := v1.AcquireScrapeLock(c, "B07FSH5L52", time.Minute)
+	if err != nil || follower != "" {
+		t.Errorf("v1.AcquireScrapeLock() = %q, %v, expect no token", follower, err)
+	}
+	if locked, _ := v1.ScrapeLocked(c, "B07FSH5L52"); !locked {
+		t.Errorf("v1.ScrapeLocked() = false, expect true")
+	}
+	//releasing with the wrong token keeps the lock
+	if err := v1.ReleaseScrapeLock(c, "B07FSH5L52", "not-the-token"); err != nil {
+		t.Errorf("v1.ReleaseScrapeLock() error = %v", err)
+	}
+	if locked, _ := v1.ScrapeLocked(c, "B07FSH5L52"); !locked {
+		t.Errorf("v1.ScrapeLocked() = false after release with wrong token, expect true")
+	}
+	if err := v1.ReleaseScrapeLock(c, "B07FSH5L52", leader); err != nil {
+		t.Errorf("v1.ReleaseScrapeLock() error = %v", err)
+	}
+	if locked, _ := v1.ScrapeLocked(c, "B07FSH5L52"); locked {
+		t.Errorf("v1.ScrapeLocked() = true after release, expect false")
+	}
+	if _, err := v1.AcquireScrapeLock(c, "", time.Minute); err != v1.ErrMissingASIN {
+		t.Errorf("v1.AcquireScrapeLock() error = %v, expect Err %v", err, v1.ErrMissingASIN)
+	}
+}
+
+//productPage is a minimal Amazon product page
+const productPage = `<html><body>
+<div id="wayfinding-breadcrumbs_feature_div"><ul><li><span class="a-list-item"><a class="a-link-normal">Clothing</a></span></li></ul></div>
+<div id="titleSection"><h1 id="title"><span id="productTitle">Dress</span></h1></div>
+</body></html>`
+
+//redirectTransport sends every request to a test server instead of Amazon
+type redirectTransport struct {
+	target *url.URL
+}
+
+func (t redirectTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	redirected := *req
+	target := *req.URL
+	target.Scheme, target.Host = t.target.Scheme, t.target.Host
+	redirected.URL = &target
+	return http.DefaultTransport.RoundTrip(&redirected)
+}
+
+//newAmazonServer serves productPage slowly enough for concurrent lookups to overlap,
+//and counts the scrapes
+func newAmazonServer() (*httptest.Server, *int32) {
+	var scrapes int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&scrapes, 1)
+		time.Sleep(300 * time.Millisecond)
+		w.Write([]byte(productPage))
+	}))
+	return server, &scrapes
+}
+
+//newScrapingServer returns a scraper server whose scrapes reach amazon instead of Amazon
+func newScrapingServer(c *redis.Client, amazon *httptest.Server, opts v1.ServerOptions) api.WebScraperServer {
+	logger.Init(0)
+	target, _ := url.Parse(amazon.URL)
+	scraper := v1.NewScraper(v1.RetryPolicy{MaxAttempts: 1}, nil)
+	scraper.Transport = redirectTransport{target: target}
+	return v1.NewScraperServer(c, scraper, opts)
+}
+
+//getConcurrently looks up an ASIN on every replica n times at once and returns the product names
+func getConcurrently(t *testing.T, replicas []api.WebScraperServer, n int) []string {
+	var (
+		wg    sync.WaitGroup
+		mu    sync.Mutex
+		names []string
+	)
+	for _, replica := range replicas {
+		for i := 0; i < n; i++ {
+			wg.Add(1)
+			go func(replica api.WebScraperServer) {
+				defer wg.Done()
+				res, err := replica.GetProduct(context.Background(), &api.GetProductRequest{Asin: "B07FSH5L52"})
+				if err != nil {
+					t.Errorf("GetProduct() error = %v", err)
+					return
+				}
+				mu.Lock()
+				names = append(names, res.Product.Name)
+				mu.Unlock()
+			}(replica)
+		}
+	}
+	wg.Wait()
+	return names
+}
+
+func TestCoalescedScrape(t *testing.T) {
+	tests := []struct {
+		subject  string
+		replicas int
+	}{
+		{subject: "Test callers in one replica share a scrape", replicas: 1},
+		{subject: "Test replicas wait for the leader through Redis", replicas: 3},
+	}
+	for _, test := range tests {
+		t.Run(test.subject, func(t *testing.T) {
+			c := newTestRedis()
+			c.FlushDB()
+			amazon, scrapes := newAmazonServer()
+			defer amazon.Close()
+			var replicas []api.WebScraperServer
+			for i := 0; i < test.replicas; i++ {
+				replicas = append(replicas, newScrapingServer(c, amazon, v1.ServerOptions{}))
+			}
+
+			names := getConcurrently(t, replicas, 10)
+			if got := atomic.LoadInt32(scrapes); got != 1 {
+				t.Errorf("%d concurrent GetProduct() scraped %d times, expect once", len(names), got)
+			}
+			if len(names) != 10*test.replicas {
+				t.Fatalf("GetProduct() succeeded %d times, expect %d", len(names), 10*test.replicas)
+			}
+			for _, name := range names {
+				if name != "Dress" {
+					t.Errorf("GetProduct() name = %q, expect the leader's Dress", name)
+				}
+			}
+		})
+	}
+}
+
+func TestStaleWhileRefresh(t *testing.T) {
+	c := newTestRedis()
+	c.FlushDB()
+	amazon, scrapes := newAmazonServer()
+	defer amazon.Close()
+	server := newScrapingServer(c, amazon, v1.ServerOptions{MaxStaleness: 24 * time.Hour})
+
+	//stored an hour ago, and the cache expired
+	stored := v1.AmazonProduct{
+		Asin:       "B07FSH5L52",
+		Name:       "Old Dress",
+		Categories: []string{"Clothing"},
+		CreatedAt:  time.Now().Add(-time.Hour).In(time.UTC).Format(time.RFC3339Nano),
+	}
+	v1.StoreProduct(c, &stored)
+
+	//every caller gets the stored copy right away, without waiting for the slow scrape
+	for i := 0; i < 5; i++ {
+		start := time.Now()
+		res, err := server.GetProduct(context.Background(), &api.GetProductRequest{Asin: "B07FSH5L52"})
+		if err != nil || !res.Stale || res.Product.Name != "Old Dress" {
+			t.Fatalf("GetProduct() = %v, %v, expect the stale Old Dress", res, err)
+		}
+		if elapsed := time.Since(start); elapsed > 100*time.Millisecond {
+			t.Errorf("GetProduct() took %v, expect a stale hit not to wait for the scrape", elapsed)
+		}
+	}
+
+	var product v1.AmazonProduct
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(50 * time.Millisecond) {
+		//the refresh stores the product before it caches it, wait for both
+		if product, _ = v1.GetProductFromCache(c, "B07FSH5L52"); product.Name == "Dress" {
+			break
+		}
+	}
+	if product.Name != "Dress" {
+		t.Fatalf("cached product = %q after the background refresh, expect Dress", product.Name)
+	}
+	if got := atomic.LoadInt32(scrapes); got != 1 {
+		t.Errorf("stale hits refreshed %d times, expect once", got)
+	}
+	res, err := server.GetProduct(context.Background(), &api.GetProductRequest{Asin: "B07FSH5L52"})
+	if err != nil || res.Stale || res.Product.Name != "Dress" {
+		t.Errorf("GetProduct() after the refresh = %v, %v, expect the fresh Dress", res, err)
+	}
+}
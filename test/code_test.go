@@ -0,0 +1,103 @@
+package v1
+
+import (
+	"testing"
+
+	"github.com/go-redis/redis"
+	v1 "github.com/rnidev/go-webscraper/pkg/service/v1"
+)
+
+func TestNormalizeCode(t *testing.T) {
+	tests := []struct {
+		subject  string
+		codeType string
+		code     string
+		expType  string
+		expCode  string
+		err      error
+	}{
+		{
+			subject: "Test UPC",
+			code:    "036000291452",
+			expType: "upc",
+			expCode: "036000291452",
+		},
+		{
+			subject: "Test EAN-13 with dashes",
+			code:    "4-006381-333931",
+			expType: "ean",
+			expCode: "4006381333931",
+		},
+		{
+			subject: "Test EAN-8",
+			code:    "73513537",
+			expType: "ean",
+			expCode: "73513537",
+		},
+		{
+			subject:  "Test UPC as GTIN",
+			codeType: "GTIN",
+			code:     "036000291452",
+			expType:  "gtin",
+			expCode:  "036000291452",
+		},
+		{
+			subject: "Test GTIN-14",
+			code:    "10036000291459",
+			expType: "gtin",
+			expCode: "10036000291459",
+		},
+		{
+			subject: "Test missing code",
+			code:    "",
+			err:     v1.ErrMissingCode,
+		},
+		{
+			subject: "Test bad check digit",
+			code:    "036000291453",
+			err:     v1.ErrInvalidCode,
+		},
+		{
+			subject:  "Test type doesn't match length",
+			codeType: "upc",
+			code:     "4006381333931",
+			err:      v1.ErrInvalidCode,
+		},
+		{
+			subject: "Test letters",
+			code:    "03600029145A",
+			err:     v1.ErrInvalidCode,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.subject, func(t *testing.T) {
+			codeType, code, err := v1.NormalizeCode(test.codeType, test.code)
+			if err != test.err {
+				t.Errorf("v1.NormalizeCode() error = %v, expect Err %v", err, test.err)
+				return
+			}
+			if codeType != test.expType || code != test.expCode {
+				t.Errorf("v1.NormalizeCode() = %s, %s, expect %s, %s", codeType, code, test.expType, test.expCode)
+			}
+		})
+	}
+}
+
+func TestCodeMapping(t *testing.T) {
+	c := newTestRedis()
+	c.FlushDB()
+
+	if _, err := v1.FetchASINByCode(c, "upc", "036000291452"); err != redis.Nil {
+		t.Errorf("v1.FetchASINByCode() error = %v, expect Err %v", err, redis.Nil)
+	}
+	if err := v1.StoreCodeMapping(c, "upc", "036000291452", "B07FSH5L52"); err != nil {
+		t.Errorf("v1.StoreCodeMapping() error = %v", err)
+	}
+	asin, err := v1.FetchASINByCode(c, "upc", "036000291452")
+	if err != nil || asin != "B07FSH5L52" {
+		t.Errorf("v1.FetchASINByCode() = %s, %v, expect %s", asin, err, "B07FSH5L52")
+	}
+	if err := v1.StoreCodeMapping(c, "upc", "036000291452", ""); err != v1.ErrMissingASIN {
+		t.Errorf("v1.StoreCodeMapping() error = %v, expect Err %v", err, v1.ErrMissingASIN)
+	}
+}
@@ -0,0 +1,121 @@
+package v1
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/ptypes"
+	api "github.com/rnidev/go-webscraper/pkg/api/v1"
+	v1 "github.com/rnidev/go-webscraper/pkg/service/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+//snapshotsAt returns snapshots of one product scraped minutes ago, with its rank changing every time
+func snapshotsAt(minutesAgo ...int) (snapshots []v1.AmazonProduct) {
+	for _, minutes := range minutesAgo {
+		snapshots = append(snapshots, v1.AmazonProduct{
+			Asin:       "B07FSH5L52",
+			Name:       "Dress",
+			Categories: []string{"Clothing"},
+			Ranks:      []string{"#" + strconv.Itoa(minutes) + " in Dresses"},
+			CreatedAt:  time.Now().Add(-time.Duration(minutes) * time.Minute).In(time.UTC).Format(time.RFC3339Nano),
+		})
+	}
+	return
+}
+
+func TestGetProductHistory(t *testing.T) {
+	c := newTestRedis()
+	c.FlushDB()
+	server := newTestServer(c, v1.ServerOptions{})
+	for _, snapshot := range snapshotsAt(9, 8, 5, 4, 1) {
+		if err := v1.AddProductSnapshot(c, &snapshot, v1.HistoryRetention{MaxSnapshots: 4}); err != nil {
+			t.Fatalf("v1.AddProductSnapshot() error = %v", err)
+		}
+	}
+	ctx := context.Background()
+
+	//the oldest snapshot is beyond retention
+	res, err := server.GetProductHistory(ctx, &api.GetProductHistoryRequest{Asin: "B07FSH5L52"})
+	if err != nil || len(res.Snapshots) != 4 {
+		t.Fatalf("GetProductHistory() = %v, %v, expect 4 snapshots", res, err)
+	}
+	if len(res.Snapshots[0].Changes) != 0 {
+		t.Errorf("GetProductHistory() first changes = %v, expect none", res.Snapshots[0].Changes)
+	}
+	changes := res.Snapshots[1].Changes
+	if len(changes) != 1 || changes[0].Field != v1.FieldRanks ||
+		changes[0].Before[0] != "#8 in Dresses" || changes[0].After[0] != "#5 in Dresses" {
+		t.Errorf("GetProductHistory() changes = %v, expect rank #8 to #5", changes)
+	}
+
+	//one snapshot per 3 minutes, the last of each interval
+	res, err = server.GetProductHistory(ctx, &api.GetProductHistoryRequest{Asin: "B07FSH5L52", Interval: 180})
+	if err != nil || len(res.Snapshots) != 3 || res.Snapshots[1].Product.Ranks[0].RankInfo != "#4 in Dresses" {
+		t.Errorf("GetProductHistory() downsampled = %v, %v, expect #8, #4 and #1", res, err)
+	}
+
+	from, _ := ptypes.TimestampProto(time.Now().Add(-6 * time.Minute))
+	to, _ := ptypes.TimestampProto(time.Now().Add(-2 * time.Minute))
+	res, err = server.GetProductHistory(ctx, &api.GetProductHistoryRequest{Asin: "B07FSH5L52", From: from, To: to})
+	if err != nil || len(res.Snapshots) != 2 {
+		t.Errorf("GetProductHistory() in range = %v, %v, expect 2 snapshots", res, err)
+	}
+
+	_, err = server.GetProductHistory(ctx, &api.GetProductHistoryRequest{Asin: "B07FSH5L52", From: to, To: from})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("GetProductHistory() error = %v, expect code %v", err, codes.InvalidArgument)
+	}
+}
+
+func TestProductHistoryPriceAvailability(t *testing.T) {
+	c := newTestRedis()
+	c.FlushDB()
+	server := newTestServer(c, v1.ServerOptions{})
+	snapshots := snapshotsAt(3, 2, 1)
+	snapshots[1].Ranks = snapshots[0].Ranks
+	snapshots[2].Ranks = snapshots[0].Ranks
+	snapshots[0].Price, snapshots[0].Availability = "$29.99", "In Stock."
+	snapshots[1].Price, snapshots[1].Availability = "$24.99", "In Stock."
+	snapshots[2].Availability = "Currently unavailable."
+	//snapshots stored before marketplaces were tracked are from the default one
+	snapshots[2].Marketplace = "www.amazon.com"
+	for _, snapshot := range snapshots {
+		if err := v1.AddProductSnapshot(c, &snapshot, v1.HistoryRetention{}); err != nil {
+			t.Fatalf("v1.AddProductSnapshot() error = %v", err)
+		}
+	}
+
+	res, err := server.GetProductHistory(context.Background(), &api.GetProductHistoryRequest{Asin: "B07FSH5L52"})
+	if err != nil || len(res.Snapshots) != 3 || res.Snapshots[0].Product.Price != "$29.99" {
+		t.Fatalf("GetProductHistory() = %v, %v, expect 3 snapshots with prices", res, err)
+	}
+	changes := res.Snapshots[1].Changes
+	if len(changes) != 1 || changes[0].Field != v1.FieldPrice ||
+		changes[0].Before[0] != "$29.99" || changes[0].After[0] != "$24.99" {
+		t.Errorf("GetProductHistory() changes = %v, expect price $29.99 to $24.99", changes)
+	}
+	//the offer went away with the stock
+	changes = res.Snapshots[2].Changes
+	if len(changes) != 2 || changes[0].Field != v1.FieldPrice || len(changes[0].After) != 0 ||
+		changes[1].Field != v1.FieldAvailability || changes[1].After[0] != "Currently unavailable." {
+		t.Errorf("GetProductHistory() changes = %v, expect no price and Currently unavailable.", changes)
+	}
+}
+
+func TestMemoryStoreHistory(t *testing.T) {
+	store := v1.NewMemoryStore(0)
+	//snapshots may arrive out of order
+	for _, snapshot := range snapshotsAt(1, 9, 5) {
+		if err := store.AddSnapshot(&snapshot, v1.HistoryRetention{MaxAge: 8 * time.Minute}); err != nil {
+			t.Fatalf("AddSnapshot() error = %v", err)
+		}
+	}
+	snapshots, err := store.Snapshots("B07FSH5L52", time.Unix(0, 0), time.Now())
+	if err != nil || len(snapshots) != 2 || snapshots[0].Ranks[0] != "#5 in Dresses" {
+		t.Errorf("Snapshots() = %v, %v, expect #5 then #1", snapshots, err)
+	}
+}
@@ -0,0 +1,79 @@
+package v1
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	api "github.com/rnidev/go-webscraper/pkg/api/v1"
+	v1 "github.com/rnidev/go-webscraper/pkg/service/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestProductIndexes(t *testing.T) {
+	c := newTestRedis()
+	c.FlushDB()
+	products := listedProducts()
+	for index := range products {
+		//the first products were scraped long ago, the last ones just now
+		if index >= 3 {
+			products[index].CreatedAt = time.Now().In(time.UTC).Format(time.RFC3339Nano)
+		}
+		if err := v1.StoreProduct(c, &products[index]); err != nil {
+			t.Fatalf("v1.StoreProduct() error = %v", err)
+		}
+	}
+
+	//a product leaving a category leaves its indexes
+	moved := products[0]
+	moved.Categories = []string{"Clothing, Shoes & Jewelry", "Men"}
+	moved.Ranks = []string{"#1,000 in Clothing, Shoes & Jewelry"}
+	if err := v1.StoreProduct(c, &moved); err != nil {
+		t.Fatalf("v1.StoreProduct() error = %v", err)
+	}
+	if members, _ := c.SMembers("index:category:women").Result(); len(members) != 2 {
+		t.Errorf("index:category:women = %v, expect 2 ASINs", members)
+	}
+	if members, _ := c.ZRange("index:rank:dresses", 0, -1).Result(); len(members) != 4 || members[0] != "B000000002" {
+		t.Errorf("index:rank:dresses = %v, expect 4 ASINs with B000000002 first", members)
+	}
+
+	server := newTestServer(c, v1.ServerOptions{})
+	ctx := context.Background()
+	top, err := server.ListTopRankedProducts(ctx, &api.ListTopRankedProductsRequest{Category: "Clothing, Shoes & Jewelry", Limit: 2})
+	if err != nil || len(top.Products) != 2 || top.Products[0].Asin != "B000000001" || top.Products[1].Asin != "B000000002" {
+		t.Errorf("ListTopRankedProducts() = %v, %v, expect B000000001 and B000000002", top, err)
+	}
+	stale, err := server.ListStaleProducts(ctx, &api.ListStaleProductsRequest{})
+	if err != nil || len(stale.Products) != 3 || stale.Products[0].Asin != "B000000001" {
+		t.Errorf("ListStaleProducts() = %v, %v, expect the 3 products scraped in 2019", stale, err)
+	}
+	list, err := server.ListProducts(ctx, &api.ListProductsRequest{Category: "Men"})
+	if err != nil || len(list.Products) != 3 {
+		t.Errorf("ListProducts() men = %v, %v, expect 3 products from the category index", list, err)
+	}
+
+	if _, err := server.ListTopRankedProducts(ctx, &api.ListTopRankedProductsRequest{}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("ListTopRankedProducts() without category error = %v, expect InvalidArgument", err)
+	}
+	if _, err := server.ListStaleProducts(ctx, &api.ListStaleProductsRequest{NotRefreshedFor: -1}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("ListStaleProducts() negative error = %v, expect InvalidArgument", err)
+	}
+
+	//products stored before indexing are indexed by the migration
+	c.HMSet("product:B004QWYCVG", map[string]interface{}{
+		"version":    2,
+		"asin":       "B004QWYCVG",
+		"name":       "Toothbrush",
+		"categories": `["Baby Products"]`,
+		"ranks":      `["#7 in Baby Products"]`,
+		"created_at": "2019-04-22T01:04:16.292932Z",
+	})
+	if migrated, err := v1.MigrateProducts(c); err != nil || migrated != 1 {
+		t.Errorf("v1.MigrateProducts() = %d, %v, expect 1 migrated", migrated, err)
+	}
+	if top, _ := v1.TopRankedProducts(c, "baby products", 10); len(top) != 1 || top[0].Asin != "B004QWYCVG" {
+		t.Errorf("v1.TopRankedProducts() = %v, expect the migrated product", top)
+	}
+}
@@ -0,0 +1,94 @@
+package v1
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	api "github.com/rnidev/go-webscraper/pkg/api/v1"
+	v1 "github.com/rnidev/go-webscraper/pkg/service/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestScrapeJobs(t *testing.T) {
+	c := newTestRedis()
+	c.FlushDB()
+	server := newTestServer(c, v1.ServerOptions{})
+	ctx := context.Background()
+
+	for _, asins := range [][]string{nil, {"B07FSH5L52", "not an asin"}, make([]string, v1.MaxJobSize+1)} {
+		if _, err := server.SubmitScrapeJob(ctx, &api.SubmitScrapeJobRequest{Asins: asins}); status.Code(err) != codes.InvalidArgument {
+			t.Errorf("SubmitScrapeJob(%d ASINs) error = %v, expect InvalidArgument", len(asins), err)
+		}
+	}
+	if _, err := server.GetJob(ctx, &api.GetJobRequest{Id: "missing"}); status.Code(err) != codes.NotFound {
+		t.Errorf("GetJob(missing) error = %v, expect NotFound", err)
+	}
+
+	canceled, err := server.SubmitScrapeJob(ctx, &api.SubmitScrapeJobRequest{Asins: []string{"B004QWYCVG"}})
+	if err != nil || canceled.Job.Status != api.Job_PENDING || canceled.Job.Total != 1 {
+		t.Fatalf("SubmitScrapeJob() = %v, %v, expect a pending job", canceled, err)
+	}
+	res, err := server.CancelJob(ctx, &api.CancelJobRequest{Id: canceled.Job.Id})
+	if err != nil || res.Job.Status != api.Job_CANCELED || res.Job.FinishedAt == nil {
+		t.Errorf("CancelJob() = %v, %v, expect it canceled", res, err)
+	}
+	if _, err = server.CancelJob(ctx, &api.CancelJobRequest{Id: canceled.Job.Id}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("CancelJob() again error = %v, expect FailedPrecondition", err)
+	}
+
+	//the first product is cached, the second fails to scrape offline
+	product := v1.AmazonProduct{
+		Asin:       "B07FSH5L52",
+		Name:       "Dress",
+		Categories: []string{"Clothing"},
+		CreatedAt:  time.Now().In(time.UTC).Format(time.RFC3339Nano),
+	}
+	v1.StoreProduct(c, &product)
+	v1.AddProductToCache(c, &product, time.Hour)
+	submitted, err := server.SubmitScrapeJob(ctx, &api.SubmitScrapeJobRequest{Asins: []string{"B07FSH5L52", "B000000000"}})
+	if err != nil {
+		t.Fatalf("SubmitScrapeJob() error = %v", err)
+	}
+
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go v1.NewJobRunner(server, v1.NewRedisStore(c), 1).Run(runCtx)
+	var job *api.Job
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(50 * time.Millisecond) {
+		got, err := server.GetJob(ctx, &api.GetJobRequest{Id: submitted.Job.Id})
+		if err != nil {
+			t.Fatalf("GetJob() error = %v", err)
+		}
+		if job = got.Job; job.Status == api.Job_DONE {
+			break
+		}
+	}
+	if job.Status != api.Job_DONE || job.Succeeded != 1 || job.Failed != 1 || job.StartedAt == nil || job.FinishedAt == nil {
+		t.Fatalf("GetJob() = %v, expect it done with 1 success and 1 failure", job)
+	}
+
+	results, err := server.GetJobResults(ctx, &api.GetJobResultsRequest{Id: job.Id, PageSize: 1})
+	if err != nil || len(results.Results) != 1 || results.Results[0].Product.GetName() != "Dress" || results.NextPageToken == "" {
+		t.Fatalf("GetJobResults() = %v, %v, expect the cached product and a next page", results, err)
+	}
+	results, err = server.GetJobResults(ctx, &api.GetJobResultsRequest{Id: job.Id, PageSize: 1, PageToken: results.NextPageToken})
+	if err != nil || len(results.Results) != 1 || results.Results[0].Asin != "B000000000" ||
+		results.Results[0].Error == "" || results.Results[0].Product != nil || results.NextPageToken != "" {
+		t.Errorf("GetJobResults() second page = %v, %v, expect the failed product last", results, err)
+	}
+
+	list, err := server.ListJobs(ctx, &api.ListJobsRequest{})
+	if err != nil || len(list.Jobs) != 2 || list.Jobs[0].Id != job.Id || list.Jobs[1].Id != canceled.Job.Id || list.NextPageToken != "" {
+		t.Errorf("ListJobs() = %v, %v, expect both jobs newest first on one page", list, err)
+	}
+	list, err = server.ListJobs(ctx, &api.ListJobsRequest{PageSize: 1})
+	if err != nil || len(list.Jobs) != 1 || list.Jobs[0].Id != job.Id || list.NextPageToken == "" {
+		t.Fatalf("ListJobs() first page = %v, %v, expect the newest job and a next page", list, err)
+	}
+	list, err = server.ListJobs(ctx, &api.ListJobsRequest{PageSize: 1, PageToken: list.NextPageToken})
+	if err != nil || len(list.Jobs) != 1 || list.Jobs[0].Id != canceled.Job.Id || list.NextPageToken != "" {
+		t.Errorf("ListJobs() second page = %v, %v, expect the oldest job last", list, err)
+	}
+}
@@ -0,0 +1,190 @@
+package v1
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/ptypes"
+	api "github.com/rnidev/go-webscraper/pkg/api/v1"
+	v1 "github.com/rnidev/go-webscraper/pkg/service/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+//listedProducts are stored products ranked #1000 to #5000 in their main category,
+//each scraped an hour after the previous one
+func listedProducts() (products []v1.AmazonProduct) {
+	start := time.Date(2019, 5, 1, 0, 0, 0, 0, time.UTC)
+	for i := 1; i <= 5; i++ {
+		product := v1.AmazonProduct{
+			Asin:       "B00000000" + strconv.Itoa(i),
+			Name:       "Product " + strconv.Itoa(i),
+			Categories: []string{"Clothing, Shoes & Jewelry", "Women"},
+			Ranks:      []string{"#" + strconv.Itoa(i) + ",000 in Clothing, Shoes & Jewelry", "#" + strconv.Itoa(i) + " in Dresses"},
+			CreatedAt:  start.Add(time.Duration(i) * time.Hour).Format(time.RFC3339Nano),
+		}
+		if i > 3 {
+			product.Categories[1] = "Men"
+			product.Marketplace = "www.amazon.de"
+		}
+		products = append(products, product)
+	}
+	return
+}
+
+//listAll pages through ListProducts and returns the listed ASINs
+func listAll(t *testing.T, server api.WebScraperServer, req api.ListProductsRequest) map[string]bool {
+	asins := make(map[string]bool)
+	for pages := 0; pages < 20; pages++ {
+		res, err := server.ListProducts(context.Background(), &req)
+		if err != nil {
+			t.Fatalf("ListProducts() error = %v", err)
+		}
+		if req.PageSize > 0 && len(res.Products) > int(req.PageSize) {
+			t.Errorf("ListProducts() listed %d products, expect at most page_size %d", len(res.Products), req.PageSize)
+		}
+		for _, product := range res.Products {
+			if asins[product.Asin] {
+				t.Errorf("ListProducts() listed %s again", product.Asin)
+			}
+			asins[product.Asin] = true
+		}
+		if res.NextPageToken == "" {
+			return asins
+		}
+		req.PageToken = res.NextPageToken
+	}
+	t.Fatalf("ListProducts() didn't stop paging")
+	return nil
+}
+
+func TestListProducts(t *testing.T) {
+	c := newTestRedis()
+	c.FlushDB()
+	memory := v1.NewMemoryStore(0)
+	servers := map[string]api.WebScraperServer{
+		"redis":  newTestServer(c, v1.ServerOptions{}),
+		"memory": v1.NewScraperServerWithStorage(memory, memory, nil, v1.NewScraper(v1.RetryPolicy{MaxAttempts: 1}, nil), v1.ServerOptions{}),
+	}
+	for _, product := range listedProducts() {
+		if err := v1.StoreProduct(c, &product); err != nil {
+			t.Fatalf("v1.StoreProduct() error = %v", err)
+		}
+		if err := memory.StoreProduct(&product); err != nil {
+			t.Fatalf("StoreProduct() error = %v", err)
+		}
+	}
+	after, _ := ptypes.TimestampProto(time.Date(2019, 5, 1, 2, 0, 0, 0, time.UTC))
+	before, _ := ptypes.TimestampProto(time.Date(2019, 5, 1, 4, 0, 0, 0, time.UTC))
+
+	tests := []struct {
+		name string
+		req  api.ListProductsRequest
+		want int
+	}{
+		{"all", api.ListProductsRequest{PageSize: 2}, 5},
+		{"category", api.ListProductsRequest{PageSize: 2, Category: "women"}, 3},
+		{"rank", api.ListProductsRequest{PageSize: 2, MinRank: 2000, MaxRank: 4000}, 3},
+		{"created", api.ListProductsRequest{PageSize: 2, CreatedAfter: after, CreatedBefore: before}, 2},
+		{"marketplace", api.ListProductsRequest{PageSize: 2, Marketplace: "amazon.de"}, 2},
+		{"default marketplace", api.ListProductsRequest{Marketplace: "www.amazon.com"}, 3},
+	}
+	for name, server := range servers {
+		for _, tt := range tests {
+			t.Run(name+" "+tt.name, func(t *testing.T) {
+				if got := listAll(t, server, tt.req); len(got) != tt.want {
+					t.Errorf("ListProducts() = %v, expect %d products", got, tt.want)
+				}
+			})
+		}
+	}
+
+	//a token only continues the listing it was returned for
+	first, err := servers["redis"].ListProducts(context.Background(), &api.ListProductsRequest{PageSize: 1})
+	if err != nil || len(first.Products) != 1 || first.NextPageToken == "" {
+		t.Fatalf("ListProducts() = %v, %v, expect one product and a next page", first, err)
+	}
+	_, err = servers["redis"].ListProducts(context.Background(),
+		&api.ListProductsRequest{PageSize: 1, Category: "women", PageToken: first.NextPageToken})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("ListProducts() with another filter's token error = %v, expect InvalidArgument", err)
+	}
+
+	invalid := []api.ListProductsRequest{
+		{MinRank: 10, MaxRank: 5},
+		{MinRank: -1},
+		{Marketplace: "example.com"},
+		{PageToken: "not a cursor"},
+		{PageToken: "12"},
+	}
+	for _, req := range invalid {
+		_, err := servers["redis"].ListProducts(context.Background(), &req)
+		if status.Code(err) != codes.InvalidArgument {
+			t.Errorf("ListProducts(%v) error = %v, expect InvalidArgument", req, err)
+		}
+	}
+}
+
+//sparseStore is a MemoryStore whose scans return an empty batch before every batch of products,
+//as Redis SCAN may
+type sparseStore struct {
+	*v1.MemoryStore
+	scans int
+}
+
+func (s *sparseStore) ScanProducts(cursor string, count int) ([]v1.AmazonProduct, string, error) {
+	s.scans++
+	if !strings.HasPrefix(cursor, "empty") {
+		return nil, "empty" + cursor, nil
+	}
+	return s.MemoryStore.ScanProducts(strings.TrimPrefix(cursor, "empty"), count)
+}
+
+func TestListProductsEmptyScans(t *testing.T) {
+	store := &sparseStore{MemoryStore: v1.NewMemoryStore(0)}
+	server := v1.NewScraperServerWithStorage(store, store, nil, v1.NewScraper(v1.RetryPolicy{MaxAttempts: 1}, nil), v1.ServerOptions{})
+	products := append(listedProducts(), v1.AmazonProduct{Asin: "B000000006", Name: "Product 6", Categories: []string{"Clothing, Shoes & Jewelry"}})
+	for _, product := range products {
+		if err := store.StoreProduct(&product); err != nil {
+			t.Fatalf("StoreProduct() error = %v", err)
+		}
+	}
+
+	//empty batches don't end a page early
+	res, err := server.ListProducts(context.Background(), &api.ListProductsRequest{PageSize: 5})
+	if err != nil || len(res.Products) != 5 {
+		t.Errorf("ListProducts() = %v, %v, expect 5 products", res, err)
+	}
+
+	//a request stops after 10 scans, empty or not
+	store.scans = 0
+	res, err = server.ListProducts(context.Background(), &api.ListProductsRequest{PageSize: 1, Marketplace: "www.amazon.co.uk"})
+	if err != nil || len(res.Products) != 0 || res.NextPageToken == "" || store.scans != 10 {
+		t.Errorf("ListProducts() = %v, %v after %d scans, expect no products and a next page after 10 scans", res, err, store.scans)
+	}
+}
+
+func TestParseRank(t *testing.T) {
+	tests := []struct {
+		rankInfo string
+		rank     int64
+		category string
+		ok       bool
+	}{
+		{"#2,680 in Clothing, Shoes & Jewelry", 2680, "Clothing, Shoes & Jewelry", true},
+		{"#9 in Women's Novelty Dresses", 9, "Women's Novelty Dresses", true},
+		{"#3 in Clothing > Women > Dresses", 3, "Dresses", true},
+		{"Nr. 12.345 in Bekleidung", 0, "", false},
+		{"", 0, "", false},
+	}
+	for _, tt := range tests {
+		rank, category, ok := v1.ParseRank(tt.rankInfo)
+		if rank != tt.rank || category != tt.category || ok != tt.ok {
+			t.Errorf("v1.ParseRank(%q) = %d, %q, %v, expect %d, %q, %v",
+				tt.rankInfo, rank, category, ok, tt.rank, tt.category, tt.ok)
+		}
+	}
+}
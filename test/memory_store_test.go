@@ -0,0 +1,78 @@
+package v1
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	api "github.com/rnidev/go-webscraper/pkg/api/v1"
+	"github.com/rnidev/go-webscraper/pkg/logger"
+	v1 "github.com/rnidev/go-webscraper/pkg/service/v1"
+)
+
+func TestMemoryStore(t *testing.T) {
+	store := v1.NewMemoryStore(2)
+	products := []v1.AmazonProduct{
+		{Asin: "B07FSH5L52", Name: "Dress", Categories: []string{"Clothing"}},
+		{Asin: "B004QWYCVG", Name: "Toothbrush", Categories: []string{"Baby"}},
+		{Asin: "B002QYW8LW", Name: "Teether", Categories: []string{"Baby"}},
+	}
+	for i := range products {
+		if err := store.StoreProduct(&products[i]); err != nil {
+			t.Fatalf("StoreProduct() error = %v", err)
+		}
+	}
+	//the least recently used product is evicted
+	if _, err := store.FetchProduct("B07FSH5L52"); err == nil {
+		t.Errorf("FetchProduct() evicted product error = nil, expect an error")
+	}
+	product, err := store.FetchProduct("B002QYW8LW")
+	if err != nil || product.Name != "Teether" {
+		t.Errorf("FetchProduct() = %v, %v, expect Teether", product, err)
+	}
+	//changing a fetched copy doesn't change the store
+	product.Categories[0] = "Toys"
+	if product, _ = store.FetchProduct("B002QYW8LW"); product.Categories[0] != "Baby" {
+		t.Errorf("FetchProduct() categories = %v, expect [Baby]", product.Categories)
+	}
+
+	if err := store.AddProduct(&products[1], 50*time.Millisecond); err != nil {
+		t.Fatalf("AddProduct() error = %v", err)
+	}
+	if cached, err := store.GetProduct("B004QWYCVG"); err != nil || cached.Name != "Toothbrush" {
+		t.Errorf("GetProduct() = %v, %v, expect Toothbrush", cached, err)
+	}
+	time.Sleep(60 * time.Millisecond)
+	if _, err := store.GetProduct("B004QWYCVG"); err != v1.ErrCacheMiss {
+		t.Errorf("GetProduct() expired error = %v, expect %v", err, v1.ErrCacheMiss)
+	}
+
+	if asin, err := store.FetchASINByCode(v1.CodeTypeUPC, "042100005264"); err != nil || asin != "" {
+		t.Errorf("FetchASINByCode() = %q, %v, expect no ASIN", asin, err)
+	}
+}
+
+func TestGetProductMemoryStorage(t *testing.T) {
+	logger.Init(0)
+	store := v1.NewMemoryStore(0)
+	scraper := v1.NewScraper(v1.RetryPolicy{MaxAttempts: 1}, nil)
+	scraper.Limiter = offlineLimiter{}
+	server := v1.NewScraperServerWithStorage(store, store, nil, scraper, v1.ServerOptions{})
+
+	product := v1.AmazonProduct{
+		Asin:       "B07FSH5L52",
+		Name:       "Dress",
+		Categories: []string{"Clothing"},
+		CreatedAt:  time.Now().In(time.UTC).Format(time.RFC3339Nano),
+	}
+	store.AddProduct(&product, time.Minute)
+
+	res, err := server.GetProduct(context.Background(), &api.GetProductRequest{Asin: product.Asin})
+	if err != nil || res.Product.Name != product.Name {
+		t.Errorf("GetProduct() = %v, %v, expect %s", res, err, product.Name)
+	}
+	//uncached products are scraped without a lock, the offline scraper fails
+	if _, err := server.GetProduct(context.Background(), &api.GetProductRequest{Asin: "B004QWYCVG"}); err == nil {
+		t.Errorf("GetProduct() uncached error = nil, expect scrape error")
+	}
+}
@@ -2,12 +2,11 @@ package v1
 
 import (
 	"errors"
-	"fmt"
 	"reflect"
 	"testing"
 	"time"
 
-	"github.com/alicebob/miniredis"
+	"github.com/alicebob/miniredis/v2"
 	"github.com/go-redis/redis"
 	v1 "github.com/rnidev/go-webscraper/pkg/service/v1"
 )
@@ -18,10 +17,9 @@ func newTestRedis() *redis.Client {
 	if err != nil {
 		panic(err)
 	}
-	defer s.Close()
 
 	client := redis.NewClient(&redis.Options{
-		Addr: ":6379",
+		Addr: s.Addr(),
 	})
 	return client
 }
@@ -120,7 +118,9 @@ func TestFetchProduct(t *testing.T) {
 			"#2,680 in Clothing, Shoes & Jewelry", "#9 in Women's Novelty Dresses",
 			"#166 in Women's Dresses", "#1573 in Women's Shops",
 		},
-		CreatedAt: "2019-04-22T01:04:16.292932Z",
+		Price:        "$24.99",
+		Availability: "In Stock.",
+		CreatedAt:    "2019-04-22T01:04:16.292932Z",
 	}
 	v1.StoreProduct(c, &product)
 	//Setup test data of AmazonProducts
@@ -141,7 +141,7 @@ func TestFetchProduct(t *testing.T) {
 			subject:   "Test key doesn't exist",
 			asin:      "notexist",
 			expectErr: true,
-			err:       fmt.Errorf("product key: product:%s doesn't exist", "notexist"),
+			err:       v1.ErrProductNotFound,
 		},
 		{
 			subject:   "Test missing asin",
@@ -161,7 +161,9 @@ func TestFetchProduct(t *testing.T) {
 			if err == nil && (response.Asin != test.expect.Asin ||
 				response.Name != test.expect.Name ||
 				!reflect.DeepEqual(response.Categories, test.expect.Categories) ||
-				!reflect.DeepEqual(response.Ranks, test.expect.Ranks)) {
+				!reflect.DeepEqual(response.Ranks, test.expect.Ranks) ||
+				response.Price != test.expect.Price ||
+				response.Availability != test.expect.Availability) {
 				t.Errorf("v1.FetchProduct() = %v, expect %v", response, test.expect)
 				return
 			}
@@ -284,3 +286,69 @@ func TestGetProductFromCache(t *testing.T) {
 		})
 	}
 }
+
+func TestProductEncoding(t *testing.T) {
+	c := newTestRedis()
+	c.FlushDB()
+
+	product := v1.AmazonProduct{
+		Asin:       "B07FSH5L52",
+		Name:       "Dress",
+		Categories: []string{"Clothing, Shoes; Jewelry", "Women"},
+		Ranks:      []string{"#2,680 in Clothing; Shoes"},
+		Dimensions: []string{"10 x 8; 1 inches"},
+		CreatedAt:  "2019-04-22T01:04:16.292932Z",
+	}
+	if err := v1.StoreProduct(c, &product); err != nil {
+		t.Fatalf("v1.StoreProduct() error = %v", err)
+	}
+	response, err := v1.FetchProduct(c, product.Asin)
+	if err != nil || !reflect.DeepEqual(response.Categories, product.Categories) ||
+		!reflect.DeepEqual(response.Ranks, product.Ranks) ||
+		!reflect.DeepEqual(response.Dimensions, product.Dimensions) {
+		t.Errorf("v1.FetchProduct() = %v, %v, expect %v", response, err, product)
+	}
+
+	//a hash written before versioning is read, then migrated in place
+	c.HMSet("product:B004QWYCVG", map[string]interface{}{
+		"asin":       "B004QWYCVG",
+		"name":       "Toothbrush",
+		"categories": "Baby Products;Baby Care",
+		"ranks":      "",
+		"dimensions": "",
+		"created_at": "2019-04-22T01:04:16.292932Z",
+	})
+	legacy, err := v1.FetchProduct(c, "B004QWYCVG")
+	if err != nil || !reflect.DeepEqual(legacy.Categories, []string{"Baby Products", "Baby Care"}) || legacy.Ranks != nil {
+		t.Errorf("v1.FetchProduct() legacy = %v, %v, expect two categories and no ranks", legacy, err)
+	}
+	migrated, err := v1.MigrateProducts(c)
+	if err != nil || migrated != 1 {
+		t.Errorf("v1.MigrateProducts() = %d, %v, expect 1 migrated", migrated, err)
+	}
+	if categories, _ := c.HGet("product:B004QWYCVG", "categories").Result(); categories != `["Baby Products","Baby Care"]` {
+		t.Errorf("migrated categories = %s, expect a JSON array", categories)
+	}
+	if migrated, _ = v1.MigrateProducts(c); migrated != 0 {
+		t.Errorf("v1.MigrateProducts() again = %d, expect 0 migrated", migrated)
+	}
+	if migratedProduct, _ := v1.FetchProduct(c, "B004QWYCVG"); !reflect.DeepEqual(migratedProduct.Categories, legacy.Categories) {
+		t.Errorf("v1.FetchProduct() migrated = %v, expect %v", migratedProduct.Categories, legacy.Categories)
+	}
+}
+
+func TestFetchProducts(t *testing.T) {
+	c := newTestRedis()
+	c.FlushDB()
+
+	for _, asin := range []string{"B07FSH5L52", "B004QWYCVG"} {
+		v1.StoreProduct(c, &v1.AmazonProduct{Asin: asin, Name: "Product " + asin, Categories: []string{"Toys"}})
+	}
+	products, err := v1.FetchProducts(c, []string{"B004QWYCVG", "notexist", "B07FSH5L52"})
+	if err != nil || len(products) != 2 || products[0].Asin != "B004QWYCVG" || products[1].Name != "Product B07FSH5L52" {
+		t.Errorf("v1.FetchProducts() = %v, %v, expect both stored products in order", products, err)
+	}
+	if products, err = v1.FetchProducts(c, nil); err != nil || len(products) != 0 {
+		t.Errorf("v1.FetchProducts() without ASINs = %v, %v, expect none", products, err)
+	}
+}
@@ -0,0 +1,263 @@
+package v1
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis"
+	api "github.com/rnidev/go-webscraper/pkg/api/v1"
+	"github.com/rnidev/go-webscraper/pkg/logger"
+	v1 "github.com/rnidev/go-webscraper/pkg/service/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+//fakeQueue hands queued scrapes to the test, which plays the worker
+type fakeQueue struct {
+	mu      sync.Mutex
+	queued  int
+	tasks   chan queuedTask
+	results map[string]v1.TaskResult
+}
+
+type queuedTask struct {
+	id   string
+	task v1.ScrapeTask
+}
+
+func newFakeQueue() *fakeQueue {
+	return &fakeQueue{tasks: make(chan queuedTask, 10), results: make(map[string]v1.TaskResult)}
+}
+
+func (q *fakeQueue) Enqueue(task v1.ScrapeTask) (string, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.queued++
+	id := strconv.Itoa(q.queued)
+	q.tasks <- queuedTask{id: id, task: task}
+	return id, nil
+}
+
+func (q *fakeQueue) Result(id string) (v1.TaskResult, bool, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	result, ok := q.results[id]
+	return result, ok, nil
+}
+
+func (q *fakeQueue) finish(id string, result v1.TaskResult) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.results[id] = result
+}
+
+func TestQueuedScrape(t *testing.T) {
+	c := newTestRedis()
+	c.FlushDB()
+	queue := newFakeQueue()
+	server := newTestServer(c, v1.ServerOptions{Queue: queue})
+	ctx := context.Background()
+
+	go func() {
+		for queued := range queue.tasks {
+			if queued.task.Asin != "B07FSH5L52" {
+				queue.finish(queued.id, v1.TaskResult{Code: codes.NotFound, Message: "product not found"})
+				continue
+			}
+			product := v1.AmazonProduct{
+				Asin:        queued.task.Asin,
+				Name:        "Dress",
+				Categories:  []string{"Clothing"},
+				Marketplace: queued.task.Marketplace,
+				CreatedAt:   time.Now().In(time.UTC).Format(time.RFC3339Nano),
+			}
+			v1.StoreProduct(c, &product)
+			v1.AddProductToCache(c, &product, time.Hour)
+			queue.finish(queued.id, v1.TaskResult{})
+		}
+	}()
+	defer close(queue.tasks)
+
+	//scrapes in process fail offline, so a product means a worker scraped it
+	res, err := server.GetProduct(ctx, &api.GetProductRequest{Asin: "B07FSH5L52"})
+	if err != nil || res.Product.Name != "Dress" {
+		t.Errorf("GetProduct() = %v, %v, expect the product cached by the worker", res, err)
+	}
+	if _, err = server.GetProduct(ctx, &api.GetProductRequest{Asin: "B004QWYCVG"}); status.Code(err) != codes.NotFound {
+		t.Errorf("GetProduct() error = %v, expect the worker's NotFound", err)
+	}
+}
+
+func TestParseScrapeTask(t *testing.T) {
+	notBefore := time.Date(2019, 3, 1, 12, 0, 0, 0, time.UTC)
+	task := v1.ScrapeTask{Asin: "B07FSH5L52", Marketplace: "www.amazon.de", NotBefore: notBefore}
+	parsed, err := v1.ParseScrapeTask(task.Values())
+	if err != nil || parsed.Asin != task.Asin || parsed.Marketplace != task.Marketplace || !parsed.NotBefore.Equal(notBefore) {
+		t.Errorf("v1.ParseScrapeTask() = %+v, %v, expect %+v", parsed, err, task)
+	}
+	if _, err = v1.ParseScrapeTask(map[string]interface{}{"asin": "not an asin"}); err != v1.ErrInvalidScrapeTask {
+		t.Errorf("v1.ParseScrapeTask() error = %v, expect %v", err, v1.ErrInvalidScrapeTask)
+	}
+	if _, err = v1.ParseScrapeTask(map[string]interface{}{"asin": "B07FSH5L52", "marketplace": "169.254.169.254"}); err != v1.ErrInvalidScrapeTask {
+		t.Errorf("v1.ParseScrapeTask() error = %v, expect %v", err, v1.ErrInvalidScrapeTask)
+	}
+
+	lookup := v1.ScrapeTask{Marketplace: "www.amazon.de", CodeType: "upc", Code: "036000291452"}
+	if parsed, err = v1.ParseScrapeTask(lookup.Values()); err != nil || !reflect.DeepEqual(parsed, lookup) {
+		t.Errorf("v1.ParseScrapeTask() = %+v, %v, expect %+v", parsed, err, lookup)
+	}
+	if _, err = v1.ParseScrapeTask(map[string]interface{}{"code": "036000291453"}); err != v1.ErrInvalidScrapeTask {
+		t.Errorf("v1.ParseScrapeTask() error = %v, expect %v", err, v1.ErrInvalidScrapeTask)
+	}
+}
+
+//newQueueRedis returns a miniredis, whose clock tests may set, and a client of it
+func newQueueRedis() (*miniredis.Miniredis, *redis.Client) {
+	m, err := miniredis.Run()
+	if err != nil {
+		panic(err)
+	}
+	return m, redis.NewClient(&redis.Options{Addr: m.Addr()})
+}
+
+//newQueueAmazon serves productPage for B07FSH5L52, search results for UPC 036000291452,
+//and 503 for anything else
+func newQueueAmazon() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/dp/B07FSH5L52":
+			w.Write([]byte(productPage))
+		case r.URL.Path == "/s" && r.URL.Query().Get("k") == "036000291452":
+			w.Write([]byte(`<div class="s-result-item" data-asin="B07FSH5L52"></div>`))
+		default:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+}
+
+//newQueueWorker returns a worker whose scrapes reach amazon, or fail offline if amazon is nil
+func newQueueWorker(c *redis.Client, queue *v1.RedisScrapeQueue, consumer string, amazon *httptest.Server) *v1.ScrapeWorker {
+	logger.Init(0)
+	scraper := v1.NewScraper(v1.RetryPolicy{MaxAttempts: 1}, nil)
+	if amazon != nil {
+		target, _ := url.Parse(amazon.URL)
+		scraper.Transport = redirectTransport{target: target}
+	} else {
+		scraper.Limiter = offlineLimiter{}
+	}
+	store := v1.NewRedisStore(c)
+	return v1.NewScrapeWorker(queue, consumer, store, store, scraper, v1.ServerOptions{})
+}
+
+func TestRedisScrapeQueue(t *testing.T) {
+	_, c := newQueueRedis()
+	amazon := newQueueAmazon()
+	defer amazon.Close()
+	queue := v1.NewRedisScrapeQueue(c)
+	queue.MaxDeliveries = 2
+	queue.RetryAfter = 50 * time.Millisecond
+	worker := newQueueWorker(c, queue, "worker-1", amazon)
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan error)
+	go func() {
+		stopped <- worker.Run(ctx)
+	}()
+	defer func() {
+		cancel()
+		if err := <-stopped; err != nil {
+			t.Errorf("Run() error = %v", err)
+		}
+	}()
+	//the API replica scrapes nothing itself, it fails offline
+	server := newTestServer(c, v1.ServerOptions{Queue: queue})
+
+	res, err := server.GetProduct(ctx, &api.GetProductRequest{Asin: "B07FSH5L52"})
+	if err != nil || res.Product.Name != "Dress" {
+		t.Fatalf("GetProduct() = %v, %v, expect the product scraped by the worker", res, err)
+	}
+	byCode, err := server.GetProductByCode(ctx, &api.GetProductByCodeRequest{Code: "036000291452"})
+	if err != nil || byCode.Product.Asin != "B07FSH5L52" {
+		t.Errorf("GetProductByCode() = %v, %v, expect the product the worker resolved the code to", byCode, err)
+	}
+	if asin, _ := v1.NewRedisStore(c).FetchASINByCode("upc", "036000291452"); asin != "B07FSH5L52" {
+		t.Errorf("code mapping = %q, expect the worker saved B07FSH5L52", asin)
+	}
+	if _, err = server.GetProductByCode(ctx, &api.GetProductByCodeRequest{Code: "4006381333931"}); status.Code(err) != codes.Unavailable {
+		t.Errorf("GetProductByCode() error = %v, expect the worker's Unavailable", err)
+	}
+
+	//503s are retried after RetryAfter, then dead-lettered
+	if _, err = server.GetProduct(ctx, &api.GetProductRequest{Asin: "B004QWYCVG"}); status.Code(err) != codes.Unavailable {
+		t.Errorf("GetProduct() error = %v, expect the worker's Unavailable", err)
+	}
+	dead, err := c.XRange("queue:scrape:dead", "-", "+").Result()
+	if err != nil || len(dead) != 2 {
+		t.Fatalf("dead-lettered scrapes = %v, %v, expect the failed code lookup and scrape", dead, err)
+	}
+	if values := dead[1].Values; values["asin"] != "B004QWYCVG" || values["deliveries"] != "2" || !strings.Contains(values["error"].(string), "503") {
+		t.Errorf("dead-lettered scrape = %v, expect B004QWYCVG after 2 deliveries with its error", values)
+	}
+	//everything was acknowledged
+	if pending, err := c.XPending("queue:scrape", "scrapers").Result(); err != nil || pending.Count != 0 {
+		t.Errorf("XPENDING = %+v, %v, expect no pending scrapes", pending, err)
+	}
+}
+
+func TestRedisScrapeQueueReclaim(t *testing.T) {
+	m, c := newQueueRedis()
+	now := time.Now()
+	m.SetTime(now)
+	queue := v1.NewRedisScrapeQueue(c)
+	queue.RetryAfter = 30 * time.Second
+	if err := queue.CreateGroup(); err != nil {
+		t.Fatalf("CreateGroup() error = %v", err)
+	}
+	//a crashed worker read every scrape, the first ten went on to a busy worker,
+	//the last two are abandoned behind them
+	var ids []string
+	for i := 0; i < 12; i++ {
+		task := v1.ScrapeTask{Asin: "B0000000" + strconv.Itoa(10+i)}
+		if i >= 10 {
+			task.Asin = "not an asin"
+		}
+		id, err := queue.Enqueue(task)
+		if err != nil {
+			t.Fatalf("Enqueue() error = %v", err)
+		}
+		ids = append(ids, id)
+	}
+	c.XReadGroup(&redis.XReadGroupArgs{Group: "scrapers", Consumer: "crashed", Streams: []string{"queue:scrape", ">"}, Count: 12, Block: -1})
+	m.SetTime(now.Add(time.Minute))
+	c.XClaim(&redis.XClaimArgs{Stream: "queue:scrape", Group: "scrapers", Consumer: "busy", Messages: ids[:10]})
+
+	worker := newQueueWorker(c, queue, "worker-1", nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go worker.Run(ctx)
+	for _, id := range ids[10:] {
+		var result v1.TaskResult
+		for wait := 0; wait < 100; wait++ {
+			var ok bool
+			if result, ok, _ = queue.Result(id); ok {
+				break
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		if result.Code != codes.InvalidArgument {
+			t.Errorf("Result(%s) = %+v, expect the abandoned scrape reclaimed and rejected", id, result)
+		}
+	}
+	pending, err := c.XPendingExt(&redis.XPendingExtArgs{Stream: "queue:scrape", Group: "scrapers", Start: "-", End: "+", Count: 20}).Result()
+	if err != nil || len(pending) != 10 || pending[0].Consumer != "busy" || pending[9].Consumer != "busy" {
+		t.Errorf("XPENDING = %+v, %v, expect the scrapes in flight left to the busy worker", pending, err)
+	}
+}
@@ -0,0 +1,55 @@
+package v1
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	v1 "github.com/rnidev/go-webscraper/pkg/service/v1"
+)
+
+func TestRedisRateLimiter(t *testing.T) {
+	c := newTestRedis()
+	c.FlushDB()
+	//two limiters on one Redis act like two replicas
+	replicaA := v1.NewRedisRateLimiter(c, 20, 2)
+	replicaB := v1.NewRedisRateLimiter(c, 20, 2)
+	ctx := context.Background()
+
+	start := time.Now()
+	//the burst goes through right away
+	if err := replicaA.Wait(ctx, "www.amazon.com"); err != nil {
+		t.Fatalf("Wait() error = %v", err)
+	}
+	if err := replicaB.Wait(ctx, "www.amazon.com"); err != nil {
+		t.Fatalf("Wait() error = %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 40*time.Millisecond {
+		t.Errorf("burst Wait() took %v, expect no wait", elapsed)
+	}
+	//the bucket is empty for both replicas now
+	if err := replicaB.Wait(ctx, "www.amazon.com"); err != nil {
+		t.Fatalf("Wait() error = %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 40*time.Millisecond {
+		t.Errorf("Wait() after burst took %v, expect about 50ms", elapsed)
+	}
+
+	//other domains have their own bucket
+	start = time.Now()
+	if err := replicaA.Wait(ctx, "www.amazon.co.uk"); err != nil {
+		t.Fatalf("Wait() error = %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 40*time.Millisecond {
+		t.Errorf("Wait() on another domain took %v, expect no wait", elapsed)
+	}
+
+	//a cancelled request stops waiting
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	replicaA.Wait(ctx, "www.amazon.com")
+	replicaA.Wait(ctx, "www.amazon.com")
+	if err := replicaA.Wait(cancelled, "www.amazon.com"); err != context.Canceled {
+		t.Errorf("Wait() error = %v, expect Err %v", err, context.Canceled)
+	}
+}
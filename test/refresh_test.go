@@ -0,0 +1,73 @@
+package v1
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	api "github.com/rnidev/go-webscraper/pkg/api/v1"
+	v1 "github.com/rnidev/go-webscraper/pkg/service/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestRefreshProduct(t *testing.T) {
+	c := newTestRedis()
+	c.FlushDB()
+	server := newTestServer(c, v1.ServerOptions{})
+	ctx := context.Background()
+
+	previous := v1.AmazonProduct{
+		Asin:        "B07FSH5L52",
+		Name:        "Dress",
+		Categories:  []string{"Clothing"},
+		Ranks:       []string{"#9 in Dresses"},
+		Marketplace: "www.amazon.de",
+		CreatedAt:   time.Now().Add(-time.Minute).In(time.UTC).Format(time.RFC3339Nano),
+	}
+	if err := v1.StoreProduct(c, &previous); err != nil {
+		t.Fatalf("v1.StoreProduct() error = %v", err)
+	}
+	//a cached copy doesn't stop the refresh, the scrape fails offline
+	v1.AddProductToCache(c, &previous, time.Hour)
+	if _, err := server.RefreshProduct(ctx, &api.RefreshProductRequest{Asin: "B07FSH5L52"}); status.Code(err) != codes.Unknown {
+		t.Errorf("RefreshProduct() error = %v, expect the offline scrape error", err)
+	}
+
+	//another replica holds the scrape lock and caches a newer scrape
+	token, err := v1.AcquireScrapeLock(c, "B07FSH5L52", time.Minute)
+	if err != nil || token == "" {
+		t.Fatalf("v1.AcquireScrapeLock() = %q, %v, expect a token", token, err)
+	}
+	type result struct {
+		res *api.RefreshProductResponse
+		err error
+	}
+	done := make(chan result)
+	go func() {
+		res, err := server.RefreshProduct(ctx, &api.RefreshProductRequest{Asin: "B07FSH5L52", Marketplace: "www.amazon.de"})
+		done <- result{res, err}
+	}()
+	time.Sleep(100 * time.Millisecond)
+	refreshed := previous
+	refreshed.Ranks = []string{"#3 in Dresses"}
+	refreshed.CreatedAt = time.Now().In(time.UTC).Format(time.RFC3339Nano)
+	v1.StoreProduct(c, &refreshed)
+	v1.AddProductToCache(c, &refreshed, time.Hour)
+	v1.ReleaseScrapeLock(c, "B07FSH5L52", token)
+
+	r := <-done
+	if r.err != nil || r.res.Product.Ranks[0].RankInfo != "#3 in Dresses" || r.res.Previous.Ranks[0].RankInfo != "#9 in Dresses" {
+		t.Fatalf("RefreshProduct() = %v, %v, expect rank #9 refreshed to #3", r.res, r.err)
+	}
+	if changes := r.res.Changes; len(changes) != 1 || changes[0].Field != v1.FieldRanks {
+		t.Errorf("RefreshProduct() changes = %v, expect ranks only", changes)
+	}
+
+	if _, err := server.RefreshProduct(ctx, &api.RefreshProductRequest{Asin: "not an asin"}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("RefreshProduct() invalid ASIN error = %v, expect InvalidArgument", err)
+	}
+	if _, err := server.RefreshProduct(ctx, &api.RefreshProductRequest{Asin: "B07FSH5L52", Marketplace: "example.com"}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("RefreshProduct() invalid marketplace error = %v, expect InvalidArgument", err)
+	}
+}
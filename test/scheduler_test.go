@@ -0,0 +1,87 @@
+package v1
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	v1 "github.com/rnidev/go-webscraper/pkg/service/v1"
+)
+
+func TestScrapeSchedulerQueueFull(t *testing.T) {
+	scheduler := v1.NewScrapeScheduler(1, 0, 1)
+	ctx := context.Background()
+
+	release, err := scheduler.Acquire(ctx, "www.amazon.com")
+	if err != nil {
+		t.Fatalf("Acquire() error = %v", err)
+	}
+	//one scrape may queue behind the running one
+	queued := make(chan error)
+	go func() {
+		release, err := scheduler.Acquire(ctx, "www.amazon.com")
+		if err == nil {
+			release()
+		}
+		queued <- err
+	}()
+	for scheduler.Pending("www.amazon.com") < 2 {
+		time.Sleep(time.Millisecond)
+	}
+	//the next one is rejected instead of queueing
+	if _, err := scheduler.Acquire(ctx, "www.amazon.com"); err != v1.ErrScrapeQueueFull {
+		t.Errorf("Acquire() error = %v, expect Err %v", err, v1.ErrScrapeQueueFull)
+	}
+	//other domains have their own budget
+	otherRelease, err := scheduler.Acquire(ctx, "www.amazon.co.uk")
+	if err != nil {
+		t.Errorf("Acquire() on another domain error = %v", err)
+	} else {
+		otherRelease()
+	}
+
+	release()
+	if err := <-queued; err != nil {
+		t.Errorf("queued Acquire() error = %v", err)
+	}
+	if pending := scheduler.Pending("www.amazon.com"); pending != 0 {
+		t.Errorf("Pending() = %d, expect 0", pending)
+	}
+}
+
+func TestScrapeSchedulerInterval(t *testing.T) {
+	interval := 50 * time.Millisecond
+	scheduler := v1.NewScrapeScheduler(3, interval, 10)
+	ctx := context.Background()
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		release, err := scheduler.Acquire(ctx, "www.amazon.com")
+		if err != nil {
+			t.Fatalf("Acquire() error = %v", err)
+		}
+		defer release()
+	}
+	//three starts are spaced by two intervals
+	if elapsed := time.Since(start); elapsed < 2*interval {
+		t.Errorf("three Acquire() took %v, expect at least %v", elapsed, 2*interval)
+	}
+}
+
+func TestScrapeSchedulerContext(t *testing.T) {
+	scheduler := v1.NewScrapeScheduler(1, 0, 5)
+	release, err := scheduler.Acquire(context.Background(), "www.amazon.com")
+	if err != nil {
+		t.Fatalf("Acquire() error = %v", err)
+	}
+	defer release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := scheduler.Acquire(ctx, "www.amazon.com"); err != context.DeadlineExceeded {
+		t.Errorf("Acquire() error = %v, expect Err %v", err, context.DeadlineExceeded)
+	}
+	if pending := scheduler.Pending("www.amazon.com"); pending != 1 {
+		t.Errorf("Pending() = %d, expect 1", pending)
+	}
+}
@@ -0,0 +1,272 @@
+package v1
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gocolly/colly"
+	"github.com/rnidev/go-webscraper/pkg/logger"
+	v1 "github.com/rnidev/go-webscraper/pkg/service/v1"
+)
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	policy := v1.RetryPolicy{
+		BaseBackoff: time.Second,
+		MaxBackoff:  5 * time.Second,
+		Jitter:      0.5,
+	}
+	tests := []struct {
+		subject string
+		attempt int
+		min     time.Duration
+		max     time.Duration
+	}{
+		{
+			subject: "Test first retry",
+			attempt: 1,
+			min:     500 * time.Millisecond,
+			max:     time.Second,
+		},
+		{
+			subject: "Test exponential growth",
+			attempt: 3,
+			min:     2 * time.Second,
+			max:     4 * time.Second,
+		},
+		{
+			subject: "Test capped by max backoff",
+			attempt: 10,
+			min:     2500 * time.Millisecond,
+			max:     5 * time.Second,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.subject, func(t *testing.T) {
+			for i := 0; i < 100; i++ {
+				backoff := policy.Backoff(test.attempt)
+				if backoff < test.min || backoff > test.max {
+					t.Errorf("Backoff(%d) = %v, expect between %v and %v", test.attempt, backoff, test.min, test.max)
+					return
+				}
+			}
+		})
+	}
+}
+
+func TestRetryPolicyMaxDuration(t *testing.T) {
+	//three attempts of up to 10s, with 2s and 4s of backoff between them
+	if got := v1.DefaultRetryPolicy.MaxDuration(); got != 36*time.Second {
+		t.Errorf("MaxDuration() = %v, expect %v", got, 36*time.Second)
+	}
+	capped := v1.RetryPolicy{MaxAttempts: 4, BaseBackoff: 10 * time.Second, MaxBackoff: 15 * time.Second}
+	if got := capped.MaxDuration(); got != 80*time.Second {
+		t.Errorf("MaxDuration() = %v, expect %v", got, 80*time.Second)
+	}
+	if got := (v1.RetryPolicy{}).MaxDuration(); got != 10*time.Second {
+		t.Errorf("MaxDuration() = %v, expect a single attempt of %v", got, 10*time.Second)
+	}
+}
+
+//newPageServer serves the responses of pages in order, repeating the last one, and counts requests
+func newPageServer(pages ...func(w http.ResponseWriter)) (*httptest.Server, *int32) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(atomic.AddInt32(&requests, 1))
+		if n > len(pages) {
+			n = len(pages)
+		}
+		pages[n-1](w)
+	}))
+	return server, &requests
+}
+
+func page(statusCode int, body string) func(w http.ResponseWriter) {
+	return func(w http.ResponseWriter) {
+		w.WriteHeader(statusCode)
+		w.Write([]byte(body))
+	}
+}
+
+func TestScraperCollect(t *testing.T) {
+	logger.Init(0)
+	policy := v1.RetryPolicy{
+		MaxAttempts:          3,
+		BaseBackoff:          time.Millisecond,
+		RetryableStatusCodes: []int{http.StatusServiceUnavailable},
+	}
+	robotCheck := `<html><head><title dir="ltr">Robot Check</title></head></html>`
+	tests := []struct {
+		subject         string
+		pages           []func(w http.ResponseWriter)
+		retryRobotCheck bool
+		requests        int32
+		attempts        int
+		statusCode      int
+		robotCheck      bool
+	}{
+		{
+			subject:  "Test retry after 503",
+			pages:    []func(w http.ResponseWriter){page(http.StatusServiceUnavailable, "busy"), page(http.StatusOK, "<h1>Dress</h1>")},
+			requests: 2,
+		},
+		{
+			subject:    "Test gives up after max attempts",
+			pages:      []func(w http.ResponseWriter){page(http.StatusServiceUnavailable, "busy")},
+			requests:   3,
+			attempts:   3,
+			statusCode: http.StatusServiceUnavailable,
+		},
+		{
+			subject:    "Test no retry of other status codes",
+			pages:      []func(w http.ResponseWriter){page(http.StatusNotFound, "missing")},
+			requests:   1,
+			attempts:   1,
+			statusCode: http.StatusNotFound,
+		},
+		{
+			subject:    "Test robot check without retry",
+			pages:      []func(w http.ResponseWriter){page(http.StatusOK, robotCheck)},
+			requests:   1,
+			attempts:   1,
+			statusCode: http.StatusOK,
+			robotCheck: true,
+		},
+		{
+			subject:         "Test robot check retried",
+			pages:           []func(w http.ResponseWriter){page(http.StatusOK, robotCheck)},
+			retryRobotCheck: true,
+			requests:        3,
+			attempts:        3,
+			statusCode:      http.StatusOK,
+			robotCheck:      true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.subject, func(t *testing.T) {
+			server, requests := newPageServer(test.pages...)
+			defer server.Close()
+			retry := policy
+			retry.RetryRobotCheck = test.retryRobotCheck
+			scraper := v1.NewScraper(retry, nil)
+
+			var name string
+			domain := strings.TrimPrefix(server.URL, "http://")
+			_, err := scraper.Collect(context.Background(), domain, server.URL+"/dp/B07FSH5L52", func(c *colly.Collector) {
+				name = ""
+				c.OnHTML("h1", func(e *colly.HTMLElement) { name = e.Text })
+			})
+			if got := atomic.LoadInt32(requests); got != test.requests {
+				t.Errorf("Collect() sent %d requests, expect %d", got, test.requests)
+			}
+			if test.attempts == 0 {
+				if err != nil || name != "Dress" {
+					t.Errorf("Collect() = %q, %v, expect Dress", name, err)
+				}
+				return
+			}
+			scrapeErr, ok := err.(*v1.ScrapeError)
+			if !ok {
+				t.Fatalf("Collect() error = %v, expect a *v1.ScrapeError", err)
+			}
+			if scrapeErr.Attempts != test.attempts || scrapeErr.StatusCode != test.statusCode {
+				t.Errorf("Collect() error = %v, expect %d attempts with status code %d", err, test.attempts, test.statusCode)
+			}
+			if (scrapeErr.Err == v1.ErrRobotCheck) != test.robotCheck {
+				t.Errorf("Collect() error = %v, expect robot check %v", scrapeErr.Err, test.robotCheck)
+			}
+		})
+	}
+}
+
+func TestScraperProxyReport(t *testing.T) {
+	logger.Init(0)
+	tests := []struct {
+		subject   string
+		page      func(w http.ResponseWriter)
+		successes int
+		failures  int
+		blocks    int
+	}{
+		{
+			subject:   "Test success",
+			page:      page(http.StatusOK, "<h1>Dress</h1>"),
+			successes: 1,
+		},
+		{
+			subject:  "Test blocked",
+			page:     page(http.StatusServiceUnavailable, "busy"),
+			failures: 1,
+			blocks:   1,
+		},
+		{
+			subject: "Test page not found isn't reported",
+			page:    page(http.StatusNotFound, "missing"),
+		},
+		{
+			subject: "Test server error isn't reported",
+			page:    page(http.StatusInternalServerError, "oops"),
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.subject, func(t *testing.T) {
+			//the page server is the proxy, it answers for whatever URL it is asked
+			proxy, _ := newPageServer(test.page)
+			defer proxy.Close()
+			pool, err := v1.NewProxyPool([]string{proxy.URL}, time.Minute, 0)
+			if err != nil {
+				t.Fatalf("v1.NewProxyPool() error = %v", err)
+			}
+			scraper := v1.NewScraper(v1.RetryPolicy{MaxAttempts: 1}, nil)
+			scraper.Proxies = pool
+
+			scraper.Collect(context.Background(), "www.amazon.com", "http://www.amazon.com/dp/B07FSH5L52", func(c *colly.Collector) {})
+			stats := pool.Stats()[0]
+			if stats.Successes != test.successes || stats.Failures != test.failures || stats.Blocks != test.blocks {
+				t.Errorf("Stats() = %d successes, %d failures, %d blocks, expect %d, %d, %d",
+					stats.Successes, stats.Failures, stats.Blocks, test.successes, test.failures, test.blocks)
+			}
+		})
+	}
+}
+
+func TestScrapeProductDetails(t *testing.T) {
+	logger.Init(0)
+	amazon := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<html><body>
+<div id="titleSection"><h1 id="title"><span id="productTitle">Dress</span></h1></div>
+<a id="bylineInfo" href="/stores/Acme">Visit the Acme Store</a>
+<div id="feature-bullets"><ul>
+<li><span class="a-list-item"> Linen </span></li>
+<li class="aok-hidden"><span class="a-list-item">Hidden</span></li>
+<li><span class="a-list-item">Machine   wash</span></li>
+</ul></div>
+<span id="priceblock_dealprice">$24.99</span>
+<span id="priceblock_ourprice">$29.99</span>
+<div id="availability"><span class="a-size-medium">
+  In Stock.
+</span></div>
+</body></html>`))
+	}))
+	defer amazon.Close()
+	target, _ := url.Parse(amazon.URL)
+	scraper := v1.NewScraper(v1.RetryPolicy{MaxAttempts: 1}, nil)
+	scraper.Transport = redirectTransport{target: target}
+
+	product := v1.AmazonProduct{Asin: "B07FSH5L52", Price: "left from a previous scrape", Features: []string{"stale"}}
+	if _, err := scraper.ScrapeProduct(context.Background(), &product); err != nil {
+		t.Fatalf("ScrapeProduct() error = %v", err)
+	}
+	if product.Name != "Dress" || product.Price != "$24.99" || product.Availability != "In Stock." {
+		t.Errorf("ScrapeProduct() = %+v, expect the deal price $24.99 and In Stock.", product)
+	}
+	if product.Brand != "Acme" || !reflect.DeepEqual(product.Features, []string{"Linen", "Machine wash"}) {
+		t.Errorf("ScrapeProduct() brand = %q, features = %q, expect Acme and the visible bullets", product.Brand, product.Features)
+	}
+}
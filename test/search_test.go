@@ -0,0 +1,210 @@
+package v1
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/alicebob/miniredis/v2/server"
+	"github.com/go-redis/redis"
+	api "github.com/rnidev/go-webscraper/pkg/api/v1"
+	v1 "github.com/rnidev/go-webscraper/pkg/service/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func searchedProducts() []v1.AmazonProduct {
+	return []v1.AmazonProduct{
+		{Asin: "B07FSH5L52", Name: "Summer Floral Dress", Brand: "Acme", Features: []string{"Linen", "Summer cut"}, Categories: []string{"Clothing", "Women", "Dresses"}},
+		{Asin: "B004QWYCVG", Name: "Baby Banana Toothbrush", Categories: []string{"Baby Products", "Baby Care"}},
+		{Asin: "B01644OCVS", Name: "Linen Shirt", Categories: []string{"Clothing", "Men", "Summer Shirts"}},
+		{Asin: "B00D89VK3Q", Name: "Party Dress", Categories: []string{"Clothing", "Girls", "Dresses"}},
+	}
+}
+
+//fakeRediSearch stands in for the RediSearch module, which miniredis lacks:
+//it records the FT.* commands it gets and answers FT.SEARCH and FT.AGGREGATE with canned replies
+type fakeRediSearch struct {
+	mu        sync.Mutex
+	created   bool
+	commands  map[string][]string
+	search    []interface{}
+	aggregate []interface{}
+}
+
+//newRediSearchRedis returns a client of a miniredis with the fake FT.* commands of search
+func newRediSearchRedis() (*redis.Client, *fakeRediSearch) {
+	m, err := miniredis.Run()
+	if err != nil {
+		panic(err)
+	}
+	search := &fakeRediSearch{commands: make(map[string][]string)}
+	m.Server().Register("FT.CREATE", func(c *server.Peer, cmd string, args []string) {
+		search.mu.Lock()
+		defer search.mu.Unlock()
+		search.commands[cmd] = args
+		if search.created {
+			c.WriteError("Index already exists")
+			return
+		}
+		search.created = true
+		c.WriteOK()
+	})
+	for cmd, reply := range map[string]*[]interface{}{"FT.SEARCH": &search.search, "FT.AGGREGATE": &search.aggregate} {
+		reply := reply
+		m.Server().Register(cmd, func(c *server.Peer, cmd string, args []string) {
+			search.mu.Lock()
+			defer search.mu.Unlock()
+			search.commands[cmd] = args
+			writeReply(c, *reply)
+		})
+	}
+	return redis.NewClient(&redis.Options{Addr: m.Addr()}), search
+}
+
+//writeReply writes nested arrays of ints and strings
+func writeReply(c *server.Peer, reply []interface{}) {
+	c.WriteLen(len(reply))
+	for _, value := range reply {
+		switch value := value.(type) {
+		case int:
+			c.WriteInt(value)
+		case string:
+			c.WriteBulk(value)
+		case []interface{}:
+			writeReply(c, value)
+		}
+	}
+}
+
+func (f *fakeRediSearch) command(cmd string) []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.commands[cmd]
+}
+
+func TestRediSearchIndex(t *testing.T) {
+	c, fake := newRediSearchRedis()
+	index := v1.NewRediSearchIndex(c)
+
+	if created, err := index.CreateIndex(); err != nil || !created {
+		t.Fatalf("CreateIndex() = %v, %v, expect it created", created, err)
+	}
+	if schema := strings.Join(fake.command("FT.CREATE"), " "); !strings.Contains(schema, "ON HASH PREFIX 1 search:product: SCHEMA") ||
+		!strings.Contains(schema, "brand TEXT") || !strings.Contains(schema, "features TEXT") {
+		t.Errorf("FT.CREATE %s, expect an index of search:product: hashes with brand and features", schema)
+	}
+	if created, err := index.CreateIndex(); err != nil || created {
+		t.Errorf("CreateIndex() again = %v, %v, expect the existing index kept", created, err)
+	}
+
+	products := searchedProducts()
+	if err := index.Index(&products[0]); err != nil {
+		t.Fatalf("Index() error = %v", err)
+	}
+	fields, _ := c.HGetAll("search:product:B07FSH5L52").Result()
+	expect := map[string]string{
+		"name":       "Summer Floral Dress",
+		"brand":      "Acme",
+		"features":   "Linen|Summer cut",
+		"categories": "Clothing|Women|Dresses",
+		"category":   "clothing|women|dresses",
+	}
+	if !reflect.DeepEqual(fields, expect) {
+		t.Errorf("search hash = %v, expect %v", fields, expect)
+	}
+	if err := index.Remove("B07FSH5L52"); err != nil {
+		t.Errorf("Remove() error = %v", err)
+	}
+	if exists, _ := c.Exists("search:product:B07FSH5L52").Result(); exists != 0 {
+		t.Errorf("search hash after Remove() exists, expect it deleted")
+	}
+
+	memory := v1.NewMemoryStore(0)
+	for _, product := range products {
+		product.CreatedAt = "2019-04-22T01:04:16.292932Z"
+		memory.StoreProduct(&product)
+	}
+	if indexed, err := index.Rebuild(memory); err != nil || indexed != 4 {
+		t.Errorf("Rebuild() = %d, %v, expect 4 products indexed", indexed, err)
+	}
+	if keys, _ := c.Keys("search:product:*").Result(); len(keys) != 4 {
+		t.Errorf("search hashes after Rebuild() = %v, expect 4", keys)
+	}
+
+	fake.search = []interface{}{2,
+		"search:product:B07FSH5L52", "3.5", []interface{}{
+			"name", "<em>Summer</em> Floral Dress", "brand", "Acme",
+			"features", "Linen|<em>Summer</em> cut", "categories", "Clothing|Women|Dresses"},
+		"search:product:B01644OCVS", "1", []interface{}{
+			"name", "Linen Shirt", "categories", "Clothing|Men|<em>Summer</em> Shirts"},
+	}
+	fake.aggregate = []interface{}{2,
+		[]interface{}{"facet", "Clothing", "count", "2"},
+		[]interface{}{"facet", "Women", "count", "1"},
+	}
+	result, err := index.Search(v1.SearchQuery{Query: "Summer, (dresses)!", Category: "Clothing, Shoes & Jewelry", Limit: 5, Offset: 2})
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	//punctuation is dropped from keywords and escaped in the category
+	args := fake.command("FT.SEARCH")
+	if len(args) < 2 || args[1] != `summer dresses @category:{clothing\,\ shoes\ \&\ jewelry}` ||
+		strings.Join(args[len(args)-3:], " ") != "LIMIT 2 5" {
+		t.Errorf("FT.SEARCH %q, expect the keywords, the category filter and LIMIT 2 5", args)
+	}
+	if args = fake.command("FT.AGGREGATE"); len(args) < 2 || args[1] != "summer dresses" {
+		t.Errorf("FT.AGGREGATE %q, expect facets of every match", args)
+	}
+	if result.Total != 2 || len(result.Hits) != 2 || result.Hits[0].Asin != "B07FSH5L52" || result.Hits[0].Score != 3.5 {
+		t.Fatalf("Search() = %+v, expect B07FSH5L52 first of 2", result)
+	}
+	highlights := map[string][]string{
+		v1.FieldName:     {"<em>Summer</em> Floral Dress"},
+		v1.FieldFeatures: {"<em>Summer</em> cut"},
+	}
+	if !reflect.DeepEqual(result.Hits[0].Highlights, highlights) {
+		t.Errorf("Search() highlights = %v, expect %v", result.Hits[0].Highlights, highlights)
+	}
+	if got := result.Hits[1].Highlights[v1.FieldCategories]; !reflect.DeepEqual(got, []string{"<em>Summer</em> Shirts"}) {
+		t.Errorf("Search() category highlights = %v, expect the matching category", got)
+	}
+	facets := []v1.CategoryFacet{{Category: "Clothing", Count: 2}, {Category: "Women", Count: 1}}
+	if !reflect.DeepEqual(result.Facets, facets) {
+		t.Errorf("Search() facets = %v, expect %v", result.Facets, facets)
+	}
+
+	if _, err = index.Search(v1.SearchQuery{Query: " ,"}); err != v1.ErrMissingKeywords {
+		t.Errorf("Search() without keywords error = %v, expect %v", err, v1.ErrMissingKeywords)
+	}
+}
+
+func TestSearchStoredProducts(t *testing.T) {
+	c, fake := newRediSearchRedis()
+	//the second hit was deleted since it was indexed
+	product := searchedProducts()[0]
+	v1.StoreProduct(c, &product)
+	fake.search = []interface{}{2,
+		"search:product:B07FSH5L52", "2", []interface{}{"name", "Summer Floral <em>Dress</em>"},
+		"search:product:B00D89VK3Q", "1", []interface{}{"name", "Party <em>Dress</em>"},
+	}
+	fake.aggregate = []interface{}{1, []interface{}{"facet", "Dresses", "count", "2"}}
+	server := newTestServer(c, v1.ServerOptions{Search: v1.NewRediSearchIndex(c)})
+	ctx := context.Background()
+
+	res, err := server.SearchStoredProducts(ctx, &api.SearchStoredProductsRequest{Query: "Dress", Limit: 2})
+	if err != nil || res.Total != 2 || len(res.Hits) != 1 || res.Hits[0].Product.Brand != "Acme" ||
+		len(res.Hits[0].Product.Features) != 2 || len(res.Hits[0].Highlights) != 1 || len(res.Facets) != 1 {
+		t.Errorf("SearchStoredProducts() = %v, %v, expect the stored hit with highlights and facets", res, err)
+	}
+	if _, err := server.SearchStoredProducts(ctx, &api.SearchStoredProductsRequest{Query: " ,"}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("SearchStoredProducts() without keywords error = %v, expect InvalidArgument", err)
+	}
+	disabled := newTestServer(c, v1.ServerOptions{})
+	if _, err := disabled.SearchStoredProducts(ctx, &api.SearchStoredProductsRequest{Query: "dress"}); status.Code(err) != codes.Unimplemented {
+		t.Errorf("SearchStoredProducts() without an index error = %v, expect Unimplemented", err)
+	}
+}
@@ -0,0 +1,130 @@
+package v1
+
+import (
+	"database/sql"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+	v1 "github.com/rnidev/go-webscraper/pkg/service/v1"
+)
+
+func TestSQLStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "webscraper")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "webscraper.sqlite")
+
+	store, err := v1.NewSQLStore("sqlite3", path)
+	if err != nil {
+		t.Fatalf("v1.NewSQLStore() error = %v", err)
+	}
+	older := v1.AmazonProduct{
+		Asin:       "B07FSH5L52",
+		Name:       "Dress",
+		Categories: []string{"Clothing, Shoes & Jewelry", "Women"},
+		Ranks:      []string{"#2 in Dresses"},
+		CreatedAt:  time.Now().Add(-time.Hour).In(time.UTC).Format(time.RFC3339Nano),
+	}
+	latest := older
+	latest.Ranks = []string{"#1 in Dresses; Women"}
+	latest.Dimensions = []string{"10 x 8 x 1 inches"}
+	latest.Marketplace = "www.amazon.de"
+	latest.Brand = "Acme"
+	latest.Features = []string{"Leinen", "Maschinenwäsche"}
+	latest.Price = "24,99 €"
+	latest.Availability = "Auf Lager."
+	latest.CreatedAt = time.Now().In(time.UTC).Format(time.RFC3339Nano)
+	for _, product := range []*v1.AmazonProduct{&older, &latest} {
+		if err := store.StoreProduct(product); err != nil {
+			t.Fatalf("StoreProduct() error = %v", err)
+		}
+	}
+	store.Close()
+
+	//migrations run once, reopening keeps the data
+	store, err = v1.NewSQLStore("sqlite3", path)
+	if err != nil {
+		t.Fatalf("v1.NewSQLStore() reopen error = %v", err)
+	}
+	defer store.Close()
+
+	product, err := store.FetchProduct(latest.Asin)
+	if err != nil {
+		t.Fatalf("FetchProduct() error = %v", err)
+	}
+	if product.Name != latest.Name || product.Marketplace != latest.Marketplace ||
+		product.Price != latest.Price || product.Availability != latest.Availability ||
+		product.Brand != latest.Brand || len(product.Features) != 2 || product.Features[1] != latest.Features[1] ||
+		len(product.Categories) != 2 || product.Categories[1] != "Women" ||
+		len(product.Ranks) != 1 || product.Ranks[0] != latest.Ranks[0] ||
+		len(product.Dimensions) != 1 || product.CreatedAt != latest.CreatedAt {
+		t.Errorf("FetchProduct() = %v, expect latest scrape %v", product, latest)
+	}
+	//every stored scrape is a snapshot
+	snapshots, err := store.Snapshots(latest.Asin, time.Unix(0, 0), time.Now())
+	if err != nil || len(snapshots) != 2 || snapshots[0].Ranks[0] != "#2 in Dresses" {
+		t.Errorf("Snapshots() = %v, %v, expect both scrapes", snapshots, err)
+	}
+	if err := store.AddSnapshot(&latest, v1.HistoryRetention{MaxSnapshots: 1}); err != nil {
+		t.Errorf("AddSnapshot() error = %v", err)
+	}
+	if snapshots, _ = store.Snapshots(latest.Asin, time.Unix(0, 0), time.Now()); len(snapshots) != 1 {
+		t.Errorf("Snapshots() after retention = %v, expect the latest scrape", snapshots)
+	}
+	if _, err := store.FetchProduct("B004QWYCVG"); err == nil {
+		t.Errorf("FetchProduct() missing product error = nil, expect an error")
+	}
+
+	if err := store.StoreCodeMapping(v1.CodeTypeUPC, "042100005264", "B07FSH5L52"); err != nil {
+		t.Fatalf("StoreCodeMapping() error = %v", err)
+	}
+	if err := store.StoreCodeMapping(v1.CodeTypeUPC, "042100005264", "B004QWYCVG"); err != nil {
+		t.Fatalf("StoreCodeMapping() again error = %v", err)
+	}
+	if asin, err := store.FetchASINByCode(v1.CodeTypeUPC, "042100005264"); err != nil || asin != "B004QWYCVG" {
+		t.Errorf("FetchASINByCode() = %q, %v, expect B004QWYCVG", asin, err)
+	}
+	if asin, err := store.FetchASINByCode(v1.CodeTypeEAN, "4006381333931"); err != nil || asin != "" {
+		t.Errorf("FetchASINByCode() = %q, %v, expect no ASIN", asin, err)
+	}
+
+	other := v1.AmazonProduct{
+		Asin:       "B004QWYCVG",
+		Name:       "Shoes",
+		Categories: []string{"Shoes"},
+		Dimensions: []string{"12 x 6 x 4 inches"},
+	}
+	if err := store.StoreProduct(&other); err != nil {
+		t.Fatalf("StoreProduct() error = %v", err)
+	}
+	products, err := store.FetchProducts([]string{other.Asin, "B000000000", latest.Asin})
+	if err != nil || len(products) != 2 || products[0].Asin != other.Asin || products[0].Dimensions[0] != other.Dimensions[0] ||
+		products[1].Asin != latest.Asin || products[1].Ranks[0] != latest.Ranks[0] || len(products[1].Categories) != 2 {
+		t.Errorf("FetchProducts() = %v, %v, expect the latest scrapes in order", products, err)
+	}
+
+	//child rows cascade, whichever connection the pool runs the delete on
+	if deleted, err := store.DeleteProduct(other.Asin); err != nil || !deleted {
+		t.Fatalf("DeleteProduct() = %v, %v, expect it deleted", deleted, err)
+	}
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	var orphans int
+	err = db.QueryRow(`SELECT COUNT(*) FROM product_categories WHERE product_id NOT IN (SELECT id FROM products)`).Scan(&orphans)
+	if err != nil || orphans != 0 {
+		t.Errorf("%d orphan categories after DeleteProduct(), %v, expect none", orphans, err)
+	}
+
+	if _, err := v1.NewSQLStore("mysql", ""); err == nil {
+		t.Errorf("v1.NewSQLStore() unsupported driver error = nil, expect an error")
+	}
+}
@@ -0,0 +1,53 @@
+package v1
+
+import (
+	"testing"
+	"time"
+
+	v1 "github.com/rnidev/go-webscraper/pkg/service/v1"
+)
+
+func TestBaseTTL(t *testing.T) {
+	if got := (v1.TTLPolicy{Default: time.Hour}).BaseTTL(); got != time.Hour {
+		t.Errorf("BaseTTL() = %v, expect %v", got, time.Hour)
+	}
+	if got := (v1.TTLPolicy{}).BaseTTL(); got != v1.DefaultTTLPolicy.Default {
+		t.Errorf("BaseTTL() = %v, expect the default %v", got, v1.DefaultTTLPolicy.Default)
+	}
+}
+
+func TestAdaptTTL(t *testing.T) {
+	c := newTestRedis()
+	c.FlushDB()
+	store := v1.NewRedisStore(c)
+
+	policy := v1.TTLPolicy{Adaptive: true, AdaptAfter: 2, MinTTL: 5 * time.Minute, MaxTTL: time.Hour}
+	base := 20 * time.Minute
+	steps := []struct {
+		changed bool
+		want    time.Duration
+	}{
+		{false, 20 * time.Minute},
+		{false, 40 * time.Minute},
+		{false, 40 * time.Minute},
+		{false, time.Hour},
+		{true, 30 * time.Minute},
+		{true, 15 * time.Minute},
+		{true, 7*time.Minute + 30*time.Second},
+		{true, 5 * time.Minute},
+	}
+	for i, step := range steps {
+		got, err := policy.AdaptTTL(store, "B07FSH5L52", base, step.changed)
+		if err != nil {
+			t.Fatalf("AdaptTTL() error = %v", err)
+		}
+		if got != step.want {
+			t.Errorf("AdaptTTL() refresh %d = %v, expect %v", i+1, got, step.want)
+		}
+	}
+
+	policy.Adaptive = false
+	if got, _ := policy.AdaptTTL(store, "B07FSH5L52", base, true); got != base {
+		t.Errorf("AdaptTTL() without adaptive = %v, expect %v", got, base)
+	}
+}
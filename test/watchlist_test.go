@@ -0,0 +1,146 @@
+package v1
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	api "github.com/rnidev/go-webscraper/pkg/api/v1"
+	v1 "github.com/rnidev/go-webscraper/pkg/service/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestWatchlistClaimDue(t *testing.T) {
+	c := newTestRedis()
+	c.FlushDB()
+	stores := map[string]v1.WatchlistStore{
+		"redis":  v1.NewRedisStore(c),
+		"memory": v1.NewMemoryStore(0),
+	}
+	now := time.Now()
+	for name, store := range stores {
+		store.Watch(v1.WatchEntry{Asin: "B07FSH5L52", Interval: time.Hour, NextRefresh: now.Add(-time.Second)})
+		store.Watch(v1.WatchEntry{Asin: "B004QWYCVG", Interval: time.Hour, NextRefresh: now.Add(time.Hour)})
+
+		entries, err := store.ClaimDue(now, 10, time.Minute)
+		if err != nil || len(entries) != 1 || entries[0].Asin != "B07FSH5L52" || entries[0].Interval != time.Hour {
+			t.Errorf("%s ClaimDue() = %v, %v, expect B07FSH5L52 only", name, entries, err)
+		}
+		//a claimed product isn't claimed again within its lease
+		if entries, _ = store.ClaimDue(now, 10, time.Minute); len(entries) != 0 {
+			t.Errorf("%s ClaimDue() again = %v, expect none", name, entries)
+		}
+		if entries, _ = store.ClaimDue(now.Add(2*time.Minute), 10, time.Minute); len(entries) != 1 {
+			t.Errorf("%s ClaimDue() after the lease = %v, expect B07FSH5L52 again", name, entries)
+		}
+
+		if removed, err := store.Unwatch("B07FSH5L52"); err != nil || !removed {
+			t.Errorf("%s Unwatch() = %v, %v, expect removed", name, removed, err)
+		}
+		//a refresh finishing after its product was removed doesn't bring it back
+		store.Reschedule("B07FSH5L52", now)
+		if entries, _ = store.ClaimDue(now.Add(2*time.Hour), 10, time.Minute); len(entries) != 1 || entries[0].Asin != "B004QWYCVG" {
+			t.Errorf("%s ClaimDue() after Unwatch() = %v, expect B004QWYCVG only", name, entries)
+		}
+	}
+}
+
+func TestWatchlistScheduler(t *testing.T) {
+	c := newTestRedis()
+	c.FlushDB()
+	server := newTestServer(c, v1.ServerOptions{})
+	ctx := context.Background()
+
+	res, err := server.AddToWatchlist(ctx, &api.AddToWatchlistRequest{Asin: "B07FSH5L52", Interval: 3600})
+	if err != nil || res.Asin != "B07FSH5L52" || res.NextRefresh == nil {
+		t.Fatalf("AddToWatchlist() = %v, %v, expect it due now", res, err)
+	}
+	for _, req := range []api.AddToWatchlistRequest{{Asin: "B07FSH5L52", Interval: 59}, {Asin: "not an asin"}} {
+		if _, err := server.AddToWatchlist(ctx, &req); status.Code(err) != codes.InvalidArgument {
+			t.Errorf("AddToWatchlist(%v) error = %v, expect InvalidArgument", req, err)
+		}
+	}
+
+	//the refresh fails offline, and the product waits for its next interval
+	scheduler := v1.NewWatchlistScheduler(server, v1.NewRedisStore(c), 100)
+	runCtx, cancel := context.WithTimeout(ctx, 200*time.Millisecond)
+	scheduler.Run(runCtx)
+	cancel()
+	score, err := c.ZScore("watchlist:due", "B07FSH5L52").Result()
+	next := time.Unix(0, int64(score)*int64(time.Millisecond))
+	if err != nil || next.Before(time.Now().Add(59*time.Minute)) {
+		t.Errorf("next refresh = %v, %v, expect in an hour", next, err)
+	}
+
+	removed, err := server.RemoveFromWatchlist(ctx, &api.RemoveFromWatchlistRequest{Asin: "B07FSH5L52"})
+	if err != nil || !removed.Removed {
+		t.Errorf("RemoveFromWatchlist() = %v, %v, expect removed", removed, err)
+	}
+	if removed, _ = server.RemoveFromWatchlist(ctx, &api.RemoveFromWatchlistRequest{Asin: "B07FSH5L52"}); removed.Removed {
+		t.Errorf("RemoveFromWatchlist() again = %v, expect not removed", removed)
+	}
+}
+
+//slowRefresher is a server whose refreshes take a while, it tracks how many run at once
+type slowRefresher struct {
+	api.WebScraperServer
+	mu        sync.Mutex
+	running   int
+	most      int
+	refreshed int
+}
+
+func (r *slowRefresher) RefreshProduct(ctx context.Context, req *api.RefreshProductRequest) (*api.RefreshProductResponse, error) {
+	r.mu.Lock()
+	r.running++
+	if r.running > r.most {
+		r.most = r.running
+	}
+	r.mu.Unlock()
+	time.Sleep(50 * time.Millisecond)
+	r.mu.Lock()
+	r.running--
+	r.refreshed++
+	r.mu.Unlock()
+	return &api.RefreshProductResponse{}, nil
+}
+
+func TestWatchlistSchedulerConcurrency(t *testing.T) {
+	store := v1.NewMemoryStore(0)
+	for i := 0; i < 20; i++ {
+		store.Watch(v1.WatchEntry{Asin: "B0000000" + strconv.Itoa(10+i), Interval: time.Hour, NextRefresh: time.Now()})
+	}
+	server := &slowRefresher{}
+
+	//ticks are much faster than refreshes, only Concurrency of them run at once
+	scheduler := v1.NewWatchlistScheduler(server, store, 1000)
+	scheduler.Concurrency = 2
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	scheduler.Run(ctx)
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	if server.most != 2 || server.running != 0 {
+		t.Errorf("%d refreshes ran at once, %d after Run() returned, expect 2 and none", server.most, server.running)
+	}
+	//the skipped ticks claimed nothing, the rest of the watchlist is still due
+	if entries, _ := store.ClaimDue(time.Now(), 20, time.Minute); len(entries)+server.refreshed != 20 {
+		t.Errorf("%d due after %d refreshes, expect the other products still due", len(entries), server.refreshed)
+	}
+
+	for _, rate := range []float64{0, -1} {
+		done := make(chan struct{})
+		go func() {
+			v1.NewWatchlistScheduler(server, store, rate).Run(context.Background())
+			close(done)
+		}()
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Errorf("Run() with rate %v didn't return, expect a scheduler that refreshes nothing", rate)
+		}
+	}
+}
@@ -0,0 +1,238 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redis"
+	api "github.com/rnidev/go-webscraper/pkg/api/v1"
+	"github.com/rnidev/go-webscraper/pkg/logger"
+	v1 "github.com/rnidev/go-webscraper/pkg/service/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+//offlineLimiter fails every visit, so tests never reach Amazon
+type offlineLimiter struct{}
+
+func (offlineLimiter) Wait(ctx context.Context, domain string) error {
+	return errors.New("offline")
+}
+
+//newTestServer returns a scraper server whose scrapes fail right away
+func newTestServer(c *redis.Client, opts v1.ServerOptions) api.WebScraperServer {
+	logger.Init(0)
+	scraper := v1.NewScraper(v1.RetryPolicy{MaxAttempts: 1}, nil)
+	scraper.Limiter = offlineLimiter{}
+	return v1.NewScraperServer(c, scraper, opts)
+}
+
+func TestGetProduct(t *testing.T) {
+	c := newTestRedis()
+	server := newTestServer(c, v1.ServerOptions{MaxStaleness: 24 * time.Hour})
+
+	product := v1.AmazonProduct{
+		Asin:       "B07FSH5L52",
+		Name:       "Longwu Women's Loose Casual Front Tie Short Sleeve Bandage Party Dress",
+		Categories: []string{"Clothing, Shoes & Jewelry", "Novelty & More"},
+		CreatedAt:  time.Now().Add(-time.Hour).In(time.UTC).Format(time.RFC3339Nano),
+	}
+	oldProduct := v1.AmazonProduct{
+		Asin:       "B004QWYCVG",
+		Name:       "Old Product",
+		Categories: []string{"Toys & Games"},
+		CreatedAt:  time.Now().Add(-48 * time.Hour).In(time.UTC).Format(time.RFC3339Nano),
+	}
+	v1.StoreProduct(c, &product)
+	v1.StoreProduct(c, &oldProduct)
+	v1.AddProductToCache(c, &product, time.Minute)
+	ctx := context.Background()
+
+	res, err := server.GetProduct(ctx, &api.GetProductRequest{Asin: "b07fsh5l52"})
+	if err != nil || res.Product.Name != product.Name || res.Stale {
+		t.Errorf("GetProduct() cached = %v, %v, expect fresh %s", res, err, product.Name)
+	}
+
+	//the cache expired, the stored copy is served stale
+	c.Del("cacheProduct:" + product.Asin)
+	res, err = server.GetProduct(ctx, &api.GetProductRequest{Asin: product.Asin})
+	if err != nil || res.Product.Name != product.Name || !res.Stale {
+		t.Errorf("GetProduct() expired = %v, %v, expect stale %s", res, err, product.Name)
+	}
+
+	//too old to be served stale, the caller waits for a scrape
+	_, err = server.GetProduct(ctx, &api.GetProductRequest{Asin: oldProduct.Asin})
+	if err == nil {
+		t.Errorf("GetProduct() beyond max staleness error = nil, expect scrape error")
+	}
+
+	_, err = server.GetProduct(ctx, &api.GetProductRequest{Asin: "not an asin"})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("GetProduct() error = %v, expect code %v", err, codes.InvalidArgument)
+	}
+}
+
+func TestGetProductMarketplace(t *testing.T) {
+	c := newTestRedis()
+	c.FlushDB()
+	server := newTestServer(c, v1.ServerOptions{MaxStaleness: 24 * time.Hour})
+	ctx := context.Background()
+
+	product := v1.AmazonProduct{
+		Asin:        "B07FSH5L52",
+		Name:        "Dress",
+		Categories:  []string{"Clothing"},
+		Marketplace: "www.amazon.com",
+		CreatedAt:   time.Now().In(time.UTC).Format(time.RFC3339Nano),
+	}
+	v1.StoreProduct(c, &product)
+	v1.AddProductToCache(c, &product, time.Hour)
+
+	res, err := server.GetProduct(ctx, &api.GetProductRequest{Asin: "https://www.amazon.com/dp/B07FSH5L52"})
+	if err != nil || res.Product.Name != product.Name {
+		t.Errorf("GetProduct() same marketplace = %v, %v, expect the cached product", res, err)
+	}
+	//the cached and stored copies are from amazon.com, so amazon.de is scraped and fails offline
+	if _, err = server.GetProduct(ctx, &api.GetProductRequest{Asin: "https://www.amazon.de/dp/B07FSH5L52"}); status.Code(err) != codes.Unknown {
+		t.Errorf("GetProduct() other marketplace error = %v, expect the offline scrape error", err)
+	}
+}
+
+func TestGetProductCacheControl(t *testing.T) {
+	c := newTestRedis()
+	server := newTestServer(c, v1.ServerOptions{MaxStaleness: 24 * time.Hour})
+
+	product := v1.AmazonProduct{
+		Asin:       "B07FSH5L52",
+		Name:       "Longwu Women's Loose Casual Front Tie Short Sleeve Bandage Party Dress",
+		Categories: []string{"Clothing, Shoes & Jewelry", "Novelty & More"},
+		CreatedAt:  time.Now().Add(-10 * time.Minute).In(time.UTC).Format(time.RFC3339Nano),
+	}
+	v1.StoreProduct(c, &product)
+	v1.AddProductToCache(c, &product, time.Minute)
+	ctx := context.Background()
+	header := func(value string) context.Context {
+		return metadata.NewIncomingContext(ctx, metadata.Pairs("grpcgateway-cache-control", value))
+	}
+
+	//scrapes fail with offlineLimiter's plain error, so codes.Unknown means a scrape was tried
+	tests := []struct {
+		subject string
+		ctx     context.Context
+		req     api.GetProductRequest
+		code    codes.Code
+	}{
+		{
+			subject: "Test max_age accepts cached product",
+			ctx:     ctx,
+			req:     api.GetProductRequest{Asin: product.Asin, MaxAge: 3600},
+			code:    codes.OK,
+		},
+		{
+			subject: "Test max_age rejects cached product",
+			ctx:     ctx,
+			req:     api.GetProductRequest{Asin: product.Asin, MaxAge: 60},
+			code:    codes.Unknown,
+		},
+		{
+			subject: "Test force_refresh skips cache",
+			ctx:     ctx,
+			req:     api.GetProductRequest{Asin: product.Asin, ForceRefresh: true},
+			code:    codes.Unknown,
+		},
+		{
+			subject: "Test cache_only returns cached product",
+			ctx:     ctx,
+			req:     api.GetProductRequest{Asin: product.Asin, CacheOnly: true},
+			code:    codes.OK,
+		},
+		{
+			subject: "Test cache_only doesn't scrape",
+			ctx:     ctx,
+			req:     api.GetProductRequest{Asin: "B004QWYCVG", CacheOnly: true},
+			code:    codes.NotFound,
+		},
+		{
+			subject: "Test only-if-cached header",
+			ctx:     header("only-if-cached"),
+			req:     api.GetProductRequest{Asin: "B004QWYCVG"},
+			code:    codes.NotFound,
+		},
+		{
+			subject: "Test max-age header",
+			ctx:     header("max-age=60"),
+			req:     api.GetProductRequest{Asin: product.Asin},
+			code:    codes.Unknown,
+		},
+		{
+			subject: "Test no-cache header",
+			ctx:     header("no-cache"),
+			req:     api.GetProductRequest{Asin: product.Asin},
+			code:    codes.Unknown,
+		},
+		{
+			subject: "Test force_refresh with cache_only",
+			ctx:     ctx,
+			req:     api.GetProductRequest{Asin: product.Asin, ForceRefresh: true, CacheOnly: true},
+			code:    codes.InvalidArgument,
+		},
+		{
+			subject: "Test negative max_age",
+			ctx:     ctx,
+			req:     api.GetProductRequest{Asin: product.Asin, MaxAge: -1},
+			code:    codes.InvalidArgument,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.subject, func(t *testing.T) {
+			_, err := server.GetProduct(test.ctx, &test.req)
+			if status.Code(err) != test.code {
+				t.Errorf("GetProduct() error = %v, expect code %v", err, test.code)
+			}
+		})
+	}
+}
+
+func TestGetProductNegativeCache(t *testing.T) {
+	c := newTestRedis()
+	c.FlushDB()
+	server := newTestServer(c, v1.ServerOptions{
+		NotFoundTTL:      time.Minute,
+		MaxRobotChecks:   2,
+		RobotCheckWindow: time.Minute,
+	})
+	ctx := context.Background()
+
+	if err := v1.MarkProductNotFound(c, "B07FSH5L52", time.Minute); err != nil {
+		t.Fatalf("v1.MarkProductNotFound() error = %v", err)
+	}
+	_, err := server.GetProduct(ctx, &api.GetProductRequest{Asin: "B07FSH5L52"})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("GetProduct() not found = %v, expect code %v", err, codes.NotFound)
+	}
+	//forcing a refresh looks again, the offline scraper fails
+	_, err = server.GetProduct(ctx, &api.GetProductRequest{Asin: "B07FSH5L52", ForceRefresh: true})
+	if status.Code(err) != codes.Unknown {
+		t.Errorf("GetProduct() forced = %v, expect code %v", err, codes.Unknown)
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := v1.RecordRobotCheck(c, "B004QWYCVG", time.Minute); err != nil {
+			t.Fatalf("v1.RecordRobotCheck() error = %v", err)
+		}
+	}
+	_, err = server.GetProduct(ctx, &api.GetProductRequest{Asin: "B004QWYCVG", ForceRefresh: true})
+	if status.Code(err) != codes.Unavailable {
+		t.Errorf("GetProduct() blocked = %v, expect code %v", err, codes.Unavailable)
+	}
+
+	if err := v1.ClearNegativeCache(c, "B004QWYCVG"); err != nil {
+		t.Errorf("v1.ClearNegativeCache() error = %v", err)
+	}
+	if count, _ := v1.RobotCheckCount(c, "B004QWYCVG"); count != 0 {
+		t.Errorf("v1.RobotCheckCount() = %d after clear, expect 0", count)
+	}
+}